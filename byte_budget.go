@@ -0,0 +1,176 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 18:02:11
+
+package logit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// ByteBudgetExceededMsg is the msg of the note emitted after a window in which logs were dropped
+	// because the byte budget was exceeded. See Logger.SetByteBudget.
+	ByteBudgetExceededMsg = "byte budget exceeded"
+
+	// DroppedKey is the key of the field carrying the count of logs dropped.
+	DroppedKey = "dropped"
+)
+
+// byteBudget limits the bytes of logs in every window.
+type byteBudget struct {
+
+	// limit is the max bytes of logs in one window.
+	limit int64
+
+	// window is the duration of one window.
+	window time.Duration
+
+	// windowStart is the start time of current window.
+	windowStart time.Time
+
+	// used is the bytes of logs handled in current window.
+	used int64
+
+	// droppedInWindow is the count of logs dropped in current window.
+	droppedInWindow uint64
+
+	// dropped is the count of logs dropped in all windows.
+	dropped uint64
+
+	// mu is for safe concurrency.
+	mu sync.Mutex
+}
+
+// take takes size bytes from the budget of the window at now, and returns false if the budget isn't enough.
+// If now is in a new window, the count of logs dropped in the last window will be returned, too.
+func (bb *byteBudget) take(size int64, now time.Time) (ok bool, droppedInLastWindow uint64) {
+	bb.mu.Lock()
+	defer bb.mu.Unlock()
+
+	if bb.windowStart.IsZero() || now.Sub(bb.windowStart) >= bb.window {
+		droppedInLastWindow = bb.droppedInWindow
+		bb.windowStart = now
+		bb.used = 0
+		bb.droppedInWindow = 0
+	}
+
+	if bb.used+size > bb.limit {
+		bb.droppedInWindow++
+		bb.dropped++
+		return false, droppedInLastWindow
+	}
+
+	bb.used += size
+	return true, droppedInLastWindow
+}
+
+// estimatedSizeOf returns the estimated size of log, which is the length of msg plus the sizes
+// of all keys and values of fields. It's much cheaper than encoding log, and the size of a value
+// which isn't a string or bytes is estimated by its type without formatting it.
+func estimatedSizeOf(log *Log) int {
+	size := len(log.msg)
+	for _, field := range log.fields {
+		size += len(field.Key) + estimatedSizeOfValue(field.Value)
+	}
+	return size
+}
+
+// estimatedSizeOfValue returns the estimated size of value in a field.
+// It never calls methods of value, because a method may panic, such as Error of a nil pointer.
+func estimatedSizeOfValue(value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case error:
+		// 错误信息的长度需要调用方法才能知道，这里按照一个固定的值估算
+		return 64
+	case bool:
+		return 5
+	case int8, uint8, int16, uint16:
+		return 5
+	case int32, uint32, float32:
+		return 10
+	default:
+		// int、int64、float64、time.Time 等类型都按照 20 个字节估算
+		return 20
+	}
+}
+
+// droppedCount returns the count of logs dropped in all windows.
+func (bb *byteBudget) droppedCount() uint64 {
+	bb.mu.Lock()
+	defer bb.mu.Unlock()
+	return bb.dropped
+}
+
+// SetByteBudget limits l to at most bytes of logs in every window, which protects a shared log pipeline.
+// The size of a log is estimated by the length of msg plus the sizes of keys and values of fields
+// without encoding it, so it's smaller than the encoded size and logs exceeding the budget
+// will be dropped until the window resets. The dropped logs are counted by DroppedCounter of MetricsSink
+// and Logger.ByteBudgetDropped. After a window in which logs were dropped, a warn log whose msg is
+// ByteBudgetExceededMsg will be emitted before the first log of the next window if warn level is enabled,
+// and it carries the count of logs dropped in a field whose key is DroppedKey. Child loggers created after setting share the budget.
+// Set bytes or window to <= 0 to remove the budget, which is the default.
+func (l *Logger) SetByteBudget(bytes int64, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bytes <= 0 || window <= 0 {
+		l.byteBudget = nil
+		return
+	}
+
+	l.byteBudget = &byteBudget{
+		limit:  bytes,
+		window: window,
+	}
+}
+
+// ByteBudgetDropped returns the count of logs dropped because the byte budget was exceeded.
+// See Logger.SetByteBudget.
+func (l *Logger) ByteBudgetDropped() uint64 {
+	l.mu.RLock()
+	byteBudget := l.byteBudget
+	l.mu.RUnlock()
+
+	if byteBudget == nil {
+		return 0
+	}
+	return byteBudget.droppedCount()
+}
+
+// takeByteBudget takes the size of log from byteBudget, and returns false if log should be dropped.
+// The note of the last window will be handled before log if some logs were dropped in it and warn level is enabled.
+// The handlers and recoverHandlers are passed to handleLog when handling the note.
+func (l *Logger) takeByteBudget(byteBudget *byteBudget, handlers []Handler, log *Log, recoverHandlers bool) bool {
+	ok, dropped := byteBudget.take(int64(estimatedSizeOf(log)), log.now)
+	if dropped > 0 && l.IsLevelEnabled(WarnLevel) {
+		note := l.newLog(WarnLevel, ByteBudgetExceededMsg, log.now)
+		note.fields = []Field{{Key: DroppedKey, Value: dropped}}
+		l.handleLog(handlers, note, recoverHandlers)
+		l.releaseLog(note)
+	}
+
+	if !ok {
+		reportDropped(log, "byte_budget")
+	}
+	return ok
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 18:20:36
+
+package logit
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试超过字节预算的日志被丢弃，并且在下一个窗口输出丢弃的数量
+func TestLoggerSetByteBudget(t *testing.T) {
+	handler := &mapHandler{}
+	sink := &fakeMetricsSink{counts: map[string]int{}}
+	logger := NewLogger(DebugLevel, handler)
+	logger.SetMetricsSink(sink)
+
+	size := estimatedSizeOf(&Log{level: InfoLevel, now: time.Now(), msg: "budget"})
+	window := 100 * time.Millisecond
+	logger.SetByteBudget(int64(size*2), window)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("budget")
+	}
+
+	if len(handler.logs) != 2 || logger.ByteBudgetDropped() != 3 {
+		t.Fatalf("超过预算的日志没有被丢弃！%d %d", len(handler.logs), logger.ByteBudgetDropped())
+	}
+
+	if sink.counts[DroppedCounter+",handler=byte_budget"] != 3 {
+		t.Fatalf("丢弃的日志没有被统计！%v", sink.counts)
+	}
+
+	// 新的窗口开始之后，先输出上一个窗口的丢弃数量
+	time.Sleep(window)
+	logger.Info("budget")
+
+	if len(handler.logs) != 4 {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	note := handler.logs[2]
+	if note["level"] != WarnLevel || note["msg"] != ByteBudgetExceededMsg || note[DroppedKey] != uint64(3) {
+		t.Fatalf("丢弃数量的日志不正确！%v", note)
+	}
+
+	if handler.logs[3]["msg"] != "budget" {
+		t.Fatalf("新窗口的日志不正确！%v", handler.logs[3])
+	}
+
+	// 去掉预算之后不再限制
+	logger.SetByteBudget(0, window)
+	for i := 0; i < 5; i++ {
+		logger.Info("budget")
+	}
+
+	if len(handler.logs) != 9 || logger.ByteBudgetDropped() != 0 {
+		t.Fatalf("去掉预算之后日志还被限制！%d", len(handler.logs))
+	}
+}
+
+// 测试估算日志大小的时候包含了消息和字段
+func TestEstimatedSizeOf(t *testing.T) {
+	log := &Log{msg: "budget", fields: []Field{{Key: "user", Value: "fish"}, {Key: "id", Value: 123}, {Key: "raw", Value: []byte("abc")}}}
+	if size := estimatedSizeOf(log); size != len("budget")+len("user")+len("fish")+len("id")+20+len("raw")+len("abc") {
+		t.Fatalf("估算的日志大小不正确！%d", size)
+	}
+}
+
+// 测试日志记录器的级别高于 warn 的时候不输出丢弃数量的日志
+func TestLoggerSetByteBudgetNoteLevel(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(ErrorLevel, handler)
+
+	window := 100 * time.Millisecond
+	logger.SetByteBudget(int64(len("budget")), window)
+	logger.Error("budget")
+	logger.Error("budget")
+
+	time.Sleep(window)
+	logger.Error("budget")
+
+	if len(handler.logs) != 2 || logger.ByteBudgetDropped() != 1 {
+		t.Fatalf("日志条数不正确！%d %d", len(handler.logs), logger.ByteBudgetDropped())
+	}
+
+	for _, log := range handler.logs {
+		if log["msg"] != "budget" {
+			t.Fatalf("warn 级别没有开启的时候不应该输出丢弃数量的日志！%v", log)
+		}
+	}
+}
+
+// 指针类型的错误，nil 指针调用 Error 方法会 panic
+type pointerError struct {
+	msg string
+}
+
+func (pe *pointerError) Error() string {
+	return pe.msg
+}
+
+// 测试估算日志大小的时候不会调用字段值的方法
+func TestLoggerSetByteBudgetNilError(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	logger.SetByteBudget(1024, time.Minute)
+
+	var err *pointerError
+	logger.ErrorWith(Fields{{Key: "err", Value: err}}, "nil error")
+
+	if len(handler.logs) != 1 || handler.logs[0]["err"] != err {
+		t.Fatalf("nil 指针的错误没有被记录！%v", handler.logs)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 14:40:13
+
+package logit
+
+import "sync"
+
+// Capture holds the logs captured by Logger.StartCapture.
+type Capture struct {
+
+	// logger is the logger this capture is started on.
+	logger *Logger
+
+	// logs is the logs captured, which are copies and won't be reused by the pool of logger.
+	logs []*Log
+
+	// stopped is a flag to check if this capture has been stopped.
+	stopped bool
+
+	// mu is for safe concurrency.
+	mu *sync.Mutex
+}
+
+// add adds a copy of log to this capture if it hasn't been stopped.
+func (c *Capture) add(log *Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	// log 会被放回对象池复用，所以需要拷贝一份，字段也可能被使用者复用，所以也需要克隆
+	captured := *log
+	captured.fields = Fields(log.fields).Clone()
+	c.logs = append(c.logs, &captured)
+}
+
+// Stop stops capturing and returns the logs captured in order.
+// Calling it again returns the same logs, and logs after stopping won't be captured anymore.
+func (c *Capture) Stop() []*Log {
+	c.logger.removeCapture(c)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	return c.logs
+}
+
+// StartCapture starts capturing logs of l into memory, and call Capture.Stop to get them.
+// The logs are still handled by handlers, so it's like a tee, which is handy to assert on
+// logs produced during an operation in tests without reconfiguring handlers:
+//
+//     capture := logger.StartCapture()
+//     doSomething()
+//     logs := capture.Stop()
+//
+// Captures can be nested, and every capture started gets the logs until it's stopped.
+// Child loggers created during capturing are captured, too, but ones created before aren't.
+// Notice that only logs whose levels are enabled will be captured.
+func (l *Logger) StartCapture() *Capture {
+	capture := &Capture{
+		logger: l,
+		mu:     &sync.Mutex{},
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 写时复制，原来的切片可能正在被使用
+	captures := make([]*Capture, 0, len(l.captures)+1)
+	captures = append(captures, l.captures...)
+	l.captures = append(captures, capture)
+	return capture
+}
+
+// removeCapture removes capture from l.
+func (l *Logger) removeCapture(capture *Capture) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	captures := make([]*Capture, 0, len(l.captures))
+	for _, c := range l.captures {
+		if c != capture {
+			captures = append(captures, c)
+		}
+	}
+	l.captures = captures
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 14:52:40
+
+package logit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 测试捕获一个函数调用期间的日志
+func TestLoggerStartCapture(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(InfoLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger.Info("before")
+
+	createOrder := func(id int) {
+		logger.Debug("ignored")
+		logger.WithFields(map[string]interface{}{"id": id}).Info("creating order")
+		logger.Warn("stock is low")
+	}
+
+	outer := logger.StartCapture()
+	logger.Info("outer")
+
+	fields := Fields{{Key: "step", Value: 1}}
+	inner := logger.StartCapture()
+	createOrder(123)
+	logger.InfoWith(fields, "inner")
+	innerLogs := inner.Stop()
+
+	// 字段被复用也不能影响捕获到的日志
+	fields.Set("step", 2)
+	logger.Error("after inner")
+	outerLogs := outer.Stop()
+	logger.Info("after")
+
+	expects := []string{"creating order", "stock is low", "inner"}
+	if len(innerLogs) != len(expects) {
+		t.Fatalf("内层捕获的日志条数不正确！%d", len(innerLogs))
+	}
+
+	for i, log := range innerLogs {
+		if log.Msg() != expects[i] {
+			t.Fatalf("内层捕获的第 %d 条日志不正确！%s", i+1, log.Msg())
+		}
+	}
+
+	if innerLogs[0].Fields()[0].Value != 123 || innerLogs[1].Level() != WarnLevel || innerLogs[2].Fields()[0].Value != 1 {
+		t.Fatalf("内层捕获的日志内容不正确！%+v", innerLogs)
+	}
+
+	expects = []string{"outer", "creating order", "stock is low", "inner", "after inner"}
+	if len(outerLogs) != len(expects) {
+		t.Fatalf("外层捕获的日志条数不正确！%d", len(outerLogs))
+	}
+
+	for i, log := range outerLogs {
+		if log.Msg() != expects[i] {
+			t.Fatalf("外层捕获的第 %d 条日志不正确！%s", i+1, log.Msg())
+		}
+	}
+
+	// 捕获不影响日志处理器
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 7 {
+		t.Fatalf("日志处理器处理的日志条数不正确！%d", len(lines))
+	}
+
+	if logs := outer.Stop(); len(logs) != len(outerLogs) {
+		t.Fatalf("再次停止捕获返回的日志不正确！%d", len(logs))
+	}
+}
+
+// 测试并发地捕获日志
+func TestLoggerStartCaptureConcurrently(t *testing.T) {
+	logger := NewLogger(InfoLevel, NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat))
+	capture := logger.StartCapture()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("concurrent")
+			}
+			logger.StartCapture().Stop()
+		}()
+	}
+	wg.Wait()
+
+	if logs := capture.Stop(); len(logs) != 1000 {
+		t.Fatalf("并发捕获的日志条数不正确！%d", len(logs))
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/24 20:30:17
+
+package logit
+
+import (
+	"sync/atomic"
+)
+
+// ChannelHandler is a handler sending logs to a channel, so in-process consumers like a live
+// log viewer can receive them. Consumers must drain the channel in time, or logs will be dropped
+// when the buffer is full. See ChannelHandler.Dropped and ChannelHandler.SetDropOldest.
+type ChannelHandler struct {
+
+	// logs is the channel which logs will be sent to.
+	logs chan *Log
+
+	// dropped is the count of logs dropped because the buffer is full.
+	dropped uint64
+
+	// dropOldest is a flag to check if the oldest log should be dropped when the buffer is full.
+	// It's stored in uint32 for atomic operations.
+	dropOldest uint32
+}
+
+// NewChannelHandler returns a handler and a receive-only channel which the handler sends logs to.
+// The bufferSize is the buffer size of the channel, and the newest log will be dropped when the
+// buffer is full by default. Notice that logs are pooled by logger, so the handler sends copies
+// of them to the channel, which means you can retain the logs received as long as you want.
+func NewChannelHandler(bufferSize int) (*ChannelHandler, <-chan *Log) {
+	ch := &ChannelHandler{
+		logs: make(chan *Log, bufferSize),
+	}
+	return ch, ch.logs
+}
+
+// SetDropOldest sets if the oldest log in buffer should be dropped when the buffer is full.
+// If false, the newest log will be dropped, which is the default behavior.
+func (ch *ChannelHandler) SetDropOldest(dropOldest bool) {
+	var flag uint32
+	if dropOldest {
+		flag = 1
+	}
+	atomic.StoreUint32(&ch.dropOldest, flag)
+}
+
+// Dropped returns the count of logs dropped because the buffer is full.
+func (ch *ChannelHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&ch.dropped)
+}
+
+// Handle sends a copy of log to the channel without blocking.
+// If the buffer is full, the oldest or the newest log will be dropped and counted.
+// Return true so that handlers after it will be used.
+func (ch *ChannelHandler) Handle(log *Log) bool {
+
+	// log 会被放回对象池中复用，所以需要拷贝一份发送到 channel 中
+	// 字段可能是调用者传进来的 Fields，调用者会复用和修改它，所以也需要克隆
+	copied := *log
+	copied.fields = Fields(log.fields).Clone()
+	select {
+	case ch.logs <- &copied:
+		return true
+	default:
+	}
+
+	if atomic.LoadUint32(&ch.dropOldest) == 0 {
+		atomic.AddUint64(&ch.dropped, 1)
+		reportDropped(log, "channel")
+		return true
+	}
+
+	// 丢弃最旧的日志，腾出位置给当前的日志，并发情况下可能腾出的位置被别人抢走了，这时就丢弃当前的日志
+	select {
+	case <-ch.logs:
+		atomic.AddUint64(&ch.dropped, 1)
+		reportDropped(log, "channel")
+	default:
+	}
+
+	select {
+	case ch.logs <- &copied:
+	default:
+		atomic.AddUint64(&ch.dropped, 1)
+		reportDropped(log, "channel")
+	}
+	return true
+}
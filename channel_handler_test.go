@@ -0,0 +1,86 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/24 20:58:41
+
+package logit
+
+import (
+	"strconv"
+	"testing"
+)
+
+// 测试日志发送到 channel 中
+func TestChannelHandler(t *testing.T) {
+	handler, logs := NewChannelHandler(3)
+	logger := NewLogger(DebugLevel, handler)
+	for i := 0; i < 5; i++ {
+		logger.InfoWith(Fields{{Key: "i", Value: i}}, strconv.Itoa(i))
+	}
+
+	if handler.Dropped() != 2 {
+		t.Fatalf("丢弃的日志条数不正确！%d", handler.Dropped())
+	}
+
+	// 默认丢弃最新的日志，所以收到的是最早的三条日志
+	for i := 0; i < 3; i++ {
+		log := <-logs
+		if log.Msg() != strconv.Itoa(i) || log.Level() != InfoLevel || log.Fields()[0].Value != i {
+			t.Fatalf("收到的第 %d 条日志不正确！%v", i+1, log.Map())
+		}
+	}
+}
+
+// 测试缓冲区满了之后丢弃最旧的日志
+func TestChannelHandlerSetDropOldest(t *testing.T) {
+	handler, logs := NewChannelHandler(3)
+	handler.SetDropOldest(true)
+
+	logger := NewLogger(DebugLevel, handler)
+	for i := 0; i < 5; i++ {
+		logger.Info(strconv.Itoa(i))
+	}
+
+	if handler.Dropped() != 2 {
+		t.Fatalf("丢弃的日志条数不正确！%d", handler.Dropped())
+	}
+
+	// 丢弃最旧的日志，所以收到的是最新的三条日志
+	for i := 2; i < 5; i++ {
+		if log := <-logs; log.Msg() != strconv.Itoa(i) {
+			t.Fatalf("收到的日志不正确！%s", log.Msg())
+		}
+	}
+}
+
+// 测试调用者复用 Fields 不会影响已经发送到 channel 中的日志
+func TestChannelHandlerReusedFields(t *testing.T) {
+	handler, logs := NewChannelHandler(2)
+	logger := NewLogger(DebugLevel, handler)
+
+	fields := Fields{}
+	fields.Set("id", 1)
+	logger.InfoWith(fields, "first")
+	fields.Set("id", 2)
+	logger.InfoWith(fields, "second")
+
+	for i := 1; i <= 2; i++ {
+		log := <-logs
+		if id, _ := log.FieldInt("id"); id != int64(i) {
+			t.Fatalf("第 %d 条日志的字段被调用者修改了！%v", i, log.Fields())
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/27 20:44:09
+
+package logit
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CircuitBreakerHandler is a handler protecting your application from a dead sink.
+// After some consecutive failures of the inner handler, the circuit opens and logs will be
+// dropped fast for a cooldown. Then one log will be handled as a probe, and the circuit closes
+// if the probe succeeded, or opens again if failed. Dropped logs are counted, see Dropped.
+//
+// There is no network handler in logit, so if your logs are sent to a remote sink, create a
+// handler by NewStandardHandler with your network writer, then wrap it with this handler:
+//
+//     handler := logit.NewCircuitBreakerHandler(logit.NewStandardHandler(conn, logit.JsonEncoder(), ""), 5, 10*time.Second)
+//
+type CircuitBreakerHandler struct {
+
+	// inner is the handler protected by this circuit breaker.
+	// Notice that it must be an ErrorReportingHandler to report its failures.
+	inner Handler
+
+	// maxFailures is the count of consecutive failures which will open the circuit.
+	maxFailures int
+
+	// cooldown is the duration of the open state.
+	cooldown time.Duration
+
+	// failures is the count of consecutive failures now.
+	failures int
+
+	// openedAt is the time when the circuit opened, and zero means the circuit is closed.
+	openedAt time.Time
+
+	// probing is a flag to check if a probe is in progress in half-open state.
+	probing bool
+
+	// dropped is the count of logs dropped when the circuit is open.
+	dropped uint64
+
+	// mu is for safe concurrency.
+	mu *sync.Mutex
+}
+
+// NewCircuitBreakerHandler returns a handler wrapping inner with a circuit breaker.
+// The circuit opens after maxFailures consecutive failures, and stays open for cooldown.
+// Notice that inner must be an ErrorReportingHandler, or the circuit will never open.
+func NewCircuitBreakerHandler(inner Handler, maxFailures int, cooldown time.Duration) *CircuitBreakerHandler {
+	return &CircuitBreakerHandler{
+		inner:       inner,
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		mu:          &sync.Mutex{},
+	}
+}
+
+// SetCircuitBreaker resets the count of consecutive failures opening the circuit and the
+// duration of open state. The circuit will be closed after resetting.
+func (cbh *CircuitBreakerHandler) SetCircuitBreaker(maxFailures int, cooldown time.Duration) {
+	cbh.mu.Lock()
+	defer cbh.mu.Unlock()
+	cbh.maxFailures = maxFailures
+	cbh.cooldown = cooldown
+	cbh.failures = 0
+	cbh.openedAt = time.Time{}
+	cbh.probing = false
+}
+
+// allow returns true if a log can be handled now.
+func (cbh *CircuitBreakerHandler) allow() bool {
+	cbh.mu.Lock()
+	defer cbh.mu.Unlock()
+
+	if cbh.openedAt.IsZero() {
+		return true
+	}
+
+	// 冷却时间还没到，或者已经有一个探测正在进行，直接丢弃
+	if time.Since(cbh.openedAt) < cbh.cooldown || cbh.probing {
+		return false
+	}
+
+	cbh.probing = true
+	return true
+}
+
+// record records the result of handling a log and updates the state of circuit.
+func (cbh *CircuitBreakerHandler) record(err error) {
+	cbh.mu.Lock()
+	defer cbh.mu.Unlock()
+
+	cbh.probing = false
+	if err == nil {
+		cbh.failures = 0
+		cbh.openedAt = time.Time{}
+		return
+	}
+
+	cbh.failures++
+	if cbh.failures >= cbh.maxFailures {
+		cbh.openedAt = time.Now()
+	}
+}
+
+// Handle handles log with inner handler if the circuit isn't open, or drops it fast.
+// The error of inner handler will be reported to the error callback of logger.
+// Return true so that handlers after it will be used.
+func (cbh *CircuitBreakerHandler) Handle(log *Log) bool {
+	err := cbh.HandleWithError(log)
+	if err != nil && err != CircuitOpenError && log.logger != nil {
+		log.logger.reportError(err)
+	}
+	return true
+}
+
+// HandleWithError handles log with inner handler if the circuit isn't open.
+// Return CircuitOpenError if log is dropped, or the error of inner handler.
+// A panic of inner handler is recorded as a failure wrapping HandlerPanicError, then re-panicked.
+func (cbh *CircuitBreakerHandler) HandleWithError(log *Log) error {
+	if !cbh.allow() {
+		atomic.AddUint64(&cbh.dropped, 1)
+		reportDropped(log, "circuit_breaker")
+		return CircuitOpenError
+	}
+
+	// 内层的日志处理器 panic 的话也要记录结果，否则探测的标记不会被重置，之后的日志会一直被丢弃
+	defer func() {
+		if r := recover(); r != nil {
+			cbh.record(fmt.Errorf("%w: %T: %v", HandlerPanicError, cbh.inner, r))
+			panic(r)
+		}
+	}()
+
+	inner, ok := cbh.inner.(ErrorReportingHandler)
+	if !ok {
+		cbh.inner.Handle(log)
+		cbh.record(nil)
+		return nil
+	}
+
+	err := inner.HandleWithError(log)
+	cbh.record(err)
+	return err
+}
+
+// Dropped returns the count of logs dropped when the circuit is open.
+func (cbh *CircuitBreakerHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&cbh.dropped)
+}
+
+// Flush flushes inner handler if it is a Flusher.
+func (cbh *CircuitBreakerHandler) Flush() error {
+	_, err := cbh.FlushWithResult()
+	return err
+}
+
+// FlushWithResult flushes inner handler if it is a Flusher, and returns the result.
+func (cbh *CircuitBreakerHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers([]Handler{cbh.inner})
+}
+
+// Close closes inner handler if it is an io.Closer.
+func (cbh *CircuitBreakerHandler) Close() error {
+	return closeHandlers([]Handler{cbh.inner})
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/27 21:26:53
+
+package logit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试连续失败之后熔断，冷却之后探测恢复
+func TestCircuitBreakerHandler(t *testing.T) {
+	writer := &flakyWriter{fail: true}
+	handler := NewCircuitBreakerHandler(NewStandardHandler(writer, TextEncoder(), DefaultTimeFormat), 3, 50*time.Millisecond)
+	logger := NewLogger(DebugLevel, handler)
+
+	var errs []error
+	logger.SetErrorCallback(func(err error) {
+		errs = append(errs, err)
+	})
+
+	// 连续失败 3 次之后熔断，后面的日志直接丢弃
+	for i := 0; i < 5; i++ {
+		logger.Info("failed")
+	}
+
+	if len(errs) != 3 || handler.Dropped() != 2 {
+		t.Fatalf("熔断的结果不正确！%d %d", len(errs), handler.Dropped())
+	}
+
+	// 冷却时间到了之后，探测失败会重新熔断
+	time.Sleep(60 * time.Millisecond)
+	logger.Info("probe failed")
+	logger.Info("dropped")
+	if len(errs) != 4 || handler.Dropped() != 3 {
+		t.Fatalf("探测失败之后没有重新熔断！%d %d", len(errs), handler.Dropped())
+	}
+
+	// 恢复之后，探测成功会关闭熔断
+	writer.fail = false
+	time.Sleep(60 * time.Millisecond)
+	logger.Info("probe ok")
+	logger.Info("closed")
+	if handler.Dropped() != 3 || !strings.Contains(writer.buffer.String(), "probe ok") || !strings.Contains(writer.buffer.String(), "closed") {
+		t.Fatalf("探测成功之后没有关闭熔断！%d %s", handler.Dropped(), writer.buffer.String())
+	}
+}
+
+// 可以切换是否 panic 的日志处理器
+type switchablePanicHandler struct {
+	panicking bool
+	handled   int
+}
+
+func (sph *switchablePanicHandler) Handle(log *Log) bool {
+	if sph.panicking {
+		panic("handler is broken")
+	}
+	sph.handled++
+	return true
+}
+
+// 测试探测的时候内层的日志处理器 panic 不会导致熔断一直打开
+func TestCircuitBreakerHandlerProbePanicked(t *testing.T) {
+	inner := &switchablePanicHandler{panicking: true}
+	handler := NewCircuitBreakerHandler(inner, 1, 10*time.Millisecond)
+
+	handleWithPanic := func() (err error, panicked bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+			}
+		}()
+		return handler.HandleWithError(&Log{level: InfoLevel, now: time.Now(), msg: "probe"}), false
+	}
+
+	// 第一次 panic 之后熔断，冷却之后探测的时候依然 panic
+	if _, panicked := handleWithPanic(); !panicked {
+		t.Fatal("内层的日志处理器 panic 之后没有继续 panic！")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, panicked := handleWithPanic(); !panicked {
+		t.Fatal("探测的时候内层的日志处理器没有 panic！")
+	}
+
+	// 恢复之后，下一次探测需要能正常处理日志
+	inner.panicking = false
+	time.Sleep(20 * time.Millisecond)
+	if err, panicked := handleWithPanic(); err != nil || panicked || inner.handled != 1 {
+		t.Fatalf("探测的时候 panic 之后熔断一直打开！%v %v %d", err, panicked, inner.handled)
+	}
+
+	// 重新设置熔断参数也需要重置探测的标记
+	handler.probing = true
+	handler.SetCircuitBreaker(1, 10*time.Millisecond)
+	if handler.probing {
+		t.Fatal("重新设置熔断参数之后没有重置探测的标记！")
+	}
+}
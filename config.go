@@ -21,10 +21,18 @@ package logit
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 )
 
+var (
+	// InvalidConfigError is an error happening on validating an invalid config.
+	// The error returned by ValidateConfig wraps it, so use errors.Is to check it.
+	InvalidConfigError = errors.New("invalid config")
+)
+
 // config is the config mapping the config file.
 type config struct {
 
@@ -90,11 +98,129 @@ func parseConfigFrom(reader io.Reader) (config, error) {
 }
 
 // parseHandlersFrom parses all handlers in conf.
-// Return a slice of all parsed handlers.
-func parseHandlersFrom(conf config) []Handler {
+// Return a slice of all parsed handlers. If one of them failed, the handlers created
+// will be closed and the error will be returned.
+func parseHandlersFrom(conf config) ([]Handler, error) {
 	handlers := make([]Handler, 0, len(conf.Handlers)+2)
 	for name, params := range conf.Handlers {
-		handlers = append(handlers, handlerOf(name, params))
+		handler, err := handlerOf(name, params)
+		if err != nil {
+			closeHandlers(handlers)
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// ValidateConfig validates the config in data without any side effect, which means no handler
+// will be created, so no file will be created either. It checks the level, the handler names,
+// and the common params of handlers like "level", "fields", "encoder", "timeFormat", "path", "directory", "label" and "limit".
+// Return an error wrapping InvalidConfigError if data is not a valid config.
+// Notice that the params of your own handlers won't be checked except the common params above.
+func ValidateConfig(data []byte) error {
+	conf, err := parseConfigFrom(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s", InvalidConfigError, err.Error())
+	}
+	return validateConfig(conf)
+}
+
+// validateConfig validates conf without any side effect. See ValidateConfig.
+func validateConfig(conf config) error {
+	if !isLevelName(conf.Level) {
+		return fmt.Errorf("%w: level \"%s\" doesn't exist", InvalidConfigError, conf.Level)
+	}
+
+	for name, params := range conf.Handlers {
+		if err := validateHandler(name, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLevelName returns true if name is the name of a level.
+func isLevelName(name string) bool {
+	for _, levelName := range levels {
+		if levelName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isWrapperHandler returns true if the handler of name wraps other handlers in its params,
+// such as level based handlers and level shielded handlers.
+func isWrapperHandler(name string) bool {
+	if len(name) > 0 && name[0] == '!' {
+		name = name[1:]
+	}
+	return name != levels[OffLevel] && isLevelName(name)
+}
+
+// validateHandler validates the handler of name with params.
+// The handlers inside a wrapper handler will be validated, too.
+func validateHandler(name string, params map[string]interface{}) error {
+	mutexOfHandlers.RLock()
+	_, ok := handlers[name]
+	mutexOfHandlers.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: handler \"%s\" doesn't exist", InvalidConfigError, name)
+	}
+
+	if param, ok := params[levelParam]; ok {
+		if level, ok := param.(string); !ok || !isLevelName(level) {
+			return fmt.Errorf("%w: level \"%v\" of handler \"%s\" doesn't exist", InvalidConfigError, param, name)
+		}
+	}
+
+	if param, ok := params[fieldsParam]; ok {
+		if _, ok := param.(map[string]interface{}); !ok {
+			return fmt.Errorf("%w: param \"%s\" of handler \"%s\" should be an object", InvalidConfigError, fieldsParam, name)
+		}
+	}
+
+	if isWrapperHandler(name) {
+		for innerName, innerParams := range params {
+			if innerName == levelParam || innerName == fieldsParam {
+				continue
+			}
+
+			p, ok := innerParams.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: params of handler \"%s\" in \"%s\" should be an object", InvalidConfigError, innerName, name)
+			}
+
+			if err := validateHandler(innerName, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return validateHandlerParams(name, params)
+}
+
+// validateHandlerParams validates the common params of handler.
+func validateHandlerParams(name string, params map[string]interface{}) error {
+	for _, key := range []string{"encoder", "timeFormat", "path", "directory", "label"} {
+		if param, ok := params[key]; ok {
+			if _, ok := param.(string); !ok {
+				return fmt.Errorf("%w: param \"%s\" of handler \"%s\" should be a string", InvalidConfigError, key, name)
+			}
+		}
+	}
+
+	if encoderName, ok := params["encoder"].(string); ok && encoderName != "" {
+		if !isEncoderRegistered(encoderName) {
+			return fmt.Errorf("%w: encoder \"%s\" of handler \"%s\" doesn't exist", InvalidConfigError, encoderName, name)
+		}
+	}
+
+	if param, ok := params["limit"]; ok {
+		if limit, ok := param.(float64); !ok || limit <= 0 {
+			return fmt.Errorf("%w: param \"limit\" of handler \"%s\" should be a positive number", InvalidConfigError, name)
+		}
 	}
-	return handlers
+	return nil
 }
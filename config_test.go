@@ -19,9 +19,11 @@
 package logit
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -79,7 +81,7 @@ func TestParseConfigFile(t *testing.T) {
 // 测试从 config 中解析日志处理器的方法
 func TestParseHandlersFromConfig(t *testing.T) {
 
-	handlers := parseHandlersFrom(config{
+	handlers, err := parseHandlersFrom(config{
 		Handlers: map[string]map[string]interface{}{
 			"console": {
 				"k1": "v1",
@@ -90,7 +92,175 @@ func TestParseHandlersFromConfig(t *testing.T) {
 			},
 		},
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	for i, handler := range handlers {
 		t.Logf("No.%d ==> %T\n", i+1, handler)
 	}
 }
+
+// 测试校验配置文件
+func TestValidateConfig(t *testing.T) {
+	valid := `
+		# 合法的配置
+		"level": "info",
+		"handlers": {
+			"console": {
+				"level": "warn",
+				"encoder": "json"
+			},
+			"!debug": {
+				"level": "info",
+				"size": {
+					"directory": "./",
+					"limit": 64
+				}
+			}
+		}
+	`
+	if err := ValidateConfig([]byte(valid)); err != nil {
+		t.Fatalf("合法的配置校验失败！%v", err)
+	}
+
+	invalids := map[string]string{
+		"语法错误":        `"level": "info",,`,
+		"日志级别不存在":     `"level": "trace"`,
+		"日志处理器不存在":    `"handlers": {"unknown": {}}`,
+		"内层日志处理器不存在":  `"handlers": {"error": {"unknown": {}}}`,
+		"内层日志处理器参数错误": `"handlers": {"error": {"console": 1}}`,
+		"编码器不存在":      `"handlers": {"console": {"encoder": "xml"}}`,
+		"参数类型错误":      `"handlers": {"file": {"path": 1}}`,
+		"滚动限制错误":      `"handlers": {"size": {"limit": -1}}`,
+		"日志处理器级别不存在":  `"handlers": {"console": {"level": "trace"}}`,
+		"日志处理器级别类型错误": `"handlers": {"console": {"level": 1}}`,
+	}
+
+	for name, invalid := range invalids {
+		err := ValidateConfig([]byte(invalid))
+		if !errors.Is(err, InvalidConfigError) {
+			t.Fatalf("%s的配置校验没有返回错误！%v", name, err)
+		}
+	}
+}
+
+// 测试配置文件中每个日志处理器的级别
+func TestNewLoggerFromConfigWithHandlerLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewLoggerFromConfigWithHandlerLevel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "error.log")
+	config := `
+		"level": "debug",
+		"handlers": {
+			"console": {
+				"level": "debug"
+			},
+			"file": {
+				"level": "error",
+				"encoder": "json",
+				"path": "` + escapeString(path) + `"
+			}
+		}
+	`
+
+	output := captureStdout(t, func() {
+		logger, err := NewLoggerFromE(strings.NewReader(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Error("error")
+		logger.Close()
+	})
+
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); len(lines) != 3 {
+		t.Fatalf("控制台日志处理器输出的日志条数不正确！%s", output)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"level":"error"`) {
+		t.Fatalf("文件日志处理器应该只输出 error 级别的日志！%s", content)
+	}
+}
+
+// 测试配置文件中日志处理器的静态字段
+func TestNewLoggerFromConfigWithHandlerFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewLoggerFromConfigWithHandlerFields")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "logit.log")
+	config := `
+		"level": "debug",
+		"handlers": {
+			"console": {},
+			"file": {
+				"encoder": "json",
+				"path": "` + escapeString(path) + `",
+				"fields": {
+					"service": "order",
+					"env": "prod"
+				}
+			}
+		}
+	`
+
+	if err := ValidateConfig([]byte(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		logger, err := NewLoggerFromE(strings.NewReader(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logger.Info("static")
+		logger.WithFields(map[string]interface{}{"env": "test"}).Info("override")
+		logger.Close()
+	})
+
+	// 静态字段只加在配置了的日志处理器上
+	if strings.Contains(output, "service") {
+		t.Fatalf("没有配置静态字段的日志处理器输出了静态字段！%s", output)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("文件日志处理器输出的日志条数不正确！%s", content)
+	}
+
+	if !strings.Contains(lines[0], `"env":"prod","service":"order"`) {
+		t.Fatalf("日志中没有静态字段！%s", lines[0])
+	}
+
+	// 日志自己的字段会覆盖静态字段
+	if !strings.Contains(lines[1], `"env":"test","service":"order"`) {
+		t.Fatalf("日志的字段没有覆盖静态字段！%s", lines[1])
+	}
+
+	// 静态字段必须是一个对象
+	invalid := `"handlers": {"console": {"fields": "service=order"}}`
+	if err := ValidateConfig([]byte(invalid)); !errors.Is(err, InvalidConfigError) {
+		t.Fatalf("静态字段不是对象的时候应该返回错误！%v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/22 19:40:26
+
+package logit
+
+import "context"
+
+// contextKey is the type of keys of values stored in context by logit.
+// It's unexported so that no one else can override our values in context.
+type contextKey struct{}
+
+// loggerKey is the key of logger stored in context.
+var loggerKey = contextKey{}
+
+// NewContext returns a copy of ctx carrying logger.
+// Use FromContext to get the logger back in the functions receiving this context.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by ctx.
+// If ctx doesn't carry any logger, the global logger will be returned. See Me.
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerKey).(*Logger); ok && logger != nil {
+		return logger
+	}
+	return globalLogger
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/22 20:46:31
+
+package logit
+
+import (
+	"context"
+	"testing"
+)
+
+// 测试在 context 中存取 logger
+func TestNewContextAndFromContext(t *testing.T) {
+	if FromContext(context.Background()) != Me() {
+		t.Fatal("context 中没有 logger 时应该返回全局的 logger！")
+	}
+
+	logger := NewLogger(DebugLevel, &myHandler{})
+	ctx := NewContext(context.Background(), logger)
+	if FromContext(ctx) != logger {
+		t.Fatal("从 context 中取出的 logger 不正确！")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 23:02:19
+
+package logit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// InvalidJsonLogError is an error happening on decoding a malformed Json log.
+	// See DecodeJsonLog.
+	InvalidJsonLogError = errors.New("invalid json log")
+
+	// decodingTimeFormats are the time formats tried in order when decoding a time in string form.
+	decodingTimeFormats = []string{DefaultTimeFormat, time.RFC3339Nano, time.RFC3339}
+)
+
+// DecodeJsonLog decodes line encoded by JsonEncoder to a log, which is symmetric to JsonEncoder.
+// It's useful for tooling which reprocesses logs, such as replaying or filtering them.
+// The level, time, msg, file, line, func and mono will be restored, and others will be the fields in order.
+// The time can be in unix form of seconds or milliseconds, or formatted in DefaultTimeFormat,
+// time.RFC3339Nano or time.RFC3339. Notice that numbers of fields will be float64 like encoding/json,
+// and the log decoded doesn't have a logger.
+// Return an error wrapping InvalidJsonLogError if line is malformed.
+func DecodeJsonLog(line []byte) (*Log, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("%w: line should be a json object", InvalidJsonLogError)
+	}
+
+	// 逐个读取属性，保留字段的顺序
+	log := &Log{}
+	hasLevel := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", InvalidJsonLogError, err.Error())
+		}
+
+		key := token.(string)
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%w: value of \"%s\" is malformed", InvalidJsonLogError, key)
+		}
+
+		switch key {
+		case "level":
+			log.level, err = decodeLevel(value)
+			hasLevel = true
+		case "time":
+			log.now, err = decodeTime(value)
+		case "msg":
+			log.msg, err = decodeString(key, value)
+		case "file":
+			log.file, err = decodeString(key, value)
+		case "line":
+			var line float64
+			line, err = decodeNumber(key, value)
+			log.line = int(line)
+		case "func":
+			log.callerFunc, err = decodeString(key, value)
+		case MonoKey:
+			var mono float64
+			mono, err = decodeNumber(key, value)
+			log.mono = time.Duration(mono)
+		default:
+			log.fields = append(log.fields, Field{Key: key, Value: value})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidJsonLogError, err.Error())
+	}
+
+	if !hasLevel {
+		return nil, fmt.Errorf("%w: level is missing", InvalidJsonLogError)
+	}
+	return log, nil
+}
+
+// decodeLevel decodes value to a level, and return an error if it isn't a level name.
+func decodeLevel(value interface{}) (Level, error) {
+	if name, ok := value.(string); ok {
+		for level, levelName := range levels {
+			if levelName == name {
+				return level, nil
+			}
+		}
+	}
+	return OffLevel, fmt.Errorf("%w: level \"%v\" doesn't exist", InvalidJsonLogError, value)
+}
+
+// decodeTime decodes value to a time, which is a unix number or a string in one of decodingTimeFormats.
+func decodeTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		// 毫秒的时间戳远大于秒的时间戳，以此区分两者
+		if v >= 1e12 {
+			return time.Unix(0, int64(v)*int64(time.Millisecond)), nil
+		}
+		return time.Unix(int64(v), 0), nil
+	case string:
+		for _, timeFormat := range decodingTimeFormats {
+			if t, err := time.ParseInLocation(timeFormat, v, time.Local); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: time \"%v\" can't be parsed", InvalidJsonLogError, value)
+}
+
+// decodeString returns value as a string, and return an error if it isn't a string.
+func decodeString(key string, value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: %s should be a string", InvalidJsonLogError, key)
+}
+
+// decodeNumber returns value as a number, and return an error if it isn't a number.
+func decodeNumber(key string, value interface{}) (float64, error) {
+	if n, ok := value.(float64); ok {
+		return n, nil
+	}
+	return 0, fmt.Errorf("%w: %s should be a number", InvalidJsonLogError, key)
+}
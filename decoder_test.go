@@ -0,0 +1,89 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 23:28:40
+
+package logit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 测试编码之后再解码 Json 日志
+func TestDecodeJsonLog(t *testing.T) {
+	now := time.Date(2020, 8, 29, 23, 30, 15, 0, time.Local)
+	log := &Log{
+		level: WarnLevel,
+		now:   now,
+		file:  "decoder_test.go",
+		line:  30,
+		msg:   `say "hello"`,
+		fields: []Field{
+			{Key: "id", Value: 1},
+			{Key: "name", Value: "logit"},
+			{Key: "tags", Value: []string{"a", "b"}},
+		},
+	}
+
+	for _, timeFormat := range []string{DefaultTimeFormat, time.RFC3339, UnixTimeFormat, UnixMilliTimeFormat} {
+		decoded, err := DecodeJsonLog(JsonEncoder().Encode(log, timeFormat))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Level() != WarnLevel || !decoded.Now().Equal(now) || decoded.Msg() != log.msg {
+			t.Fatalf("使用 %s 格式的时间解码出来的日志不正确！%v", timeFormat, decoded.Map())
+		}
+
+		if decoded.File() != log.file || decoded.Line() != log.line {
+			t.Fatalf("解码出来的文件信息不正确！%s:%d", decoded.File(), decoded.Line())
+		}
+
+		// 数字会被解码成 float64，并且字段的顺序需要保持一致
+		fields := decoded.Fields()
+		if len(fields) != 3 || fields[0].Key != "id" || fields[0].Value != float64(1) || fields[1].Value != "logit" {
+			t.Fatalf("解码出来的字段不正确！%v", fields)
+		}
+
+		if tags, ok := fields[2].Value.([]interface{}); !ok || len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+			t.Fatalf("解码出来的切片字段不正确！%v", fields[2].Value)
+		}
+	}
+}
+
+// 测试解码格式错误的 Json 日志
+func TestDecodeJsonLogMalformed(t *testing.T) {
+	lines := []string{
+		``,
+		`not json`,
+		`[1, 2]`,
+		`{"level":"info","msg":"missing brace"`,
+		`{"msg":"missing level"}`,
+		`{"level":"fatal","msg":"unknown level"}`,
+		`{"level":"info","time":"yesterday"}`,
+		`{"level":"info","msg":1}`,
+		`{"level":"info","line":"1"}`,
+	}
+
+	for _, line := range lines {
+		log, err := DecodeJsonLog([]byte(line))
+		if log != nil || !errors.Is(err, InvalidJsonLogError) {
+			t.Fatalf("格式错误的日志 %s 没有返回错误！%v", line, err)
+		}
+	}
+}
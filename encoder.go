@@ -20,22 +20,37 @@ package logit
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"math"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
-	// encoders store all encoders provided.
-	// Call encoderOf method to use one of encoders below.
-	// Actually, this field is for me, not for you, ha:)
-	encoders = map[string]Encoder{
-		"text": TextEncoder(),
-		"json": JsonEncoder(),
-	}
+	// encoders store all encoders registered.
+	// Call encoderOf method to use one of encoders.
+	// mutexOfEncoders is for concurrency.
+	encoders        = builtinEncoders()
+	mutexOfEncoders = &sync.RWMutex{}
+
+	// EncoderIsExistedError is an error happening on repeating encoder name.
+	EncoderIsExistedError = errors.New("the name of encoder you want to register already exists! May be you should give it an another name")
 )
 
+// builtinEncoders returns a new map of the built-in encoders, which are "text" and "json".
+func builtinEncoders() map[string]func(params map[string]interface{}) Encoder {
+	return map[string]func(params map[string]interface{}) Encoder{
+		"text": func(params map[string]interface{}) Encoder { return TextEncoder() },
+		"json": func(params map[string]interface{}) Encoder { return JsonEncoder() },
+	}
+}
+
 // Encoder is for encoding a log to bytes with timeFormat.
 // No matter what you do, remember, return it in bytes form.
 type Encoder func(log *Log, timeFormat string) []byte
@@ -46,58 +61,296 @@ func (e Encoder) Encode(log *Log, timeFormat string) []byte {
 	return e(log, timeFormat)
 }
 
-// encoderOf returns the encoder called name.
-// If the encoder doesn't exist, a tip will be printed and
-// the program will exit with status code 2.
-func encoderOf(name string) Encoder {
-	encoder, ok := encoders[name]
+// RegisterEncoder registers your encoder to logit so that you can use it by name in config file.
+// Return EncoderIsExistedError if the name is already registered, and the built-in "text" and "json"
+// are registered, too. The params of the handler using this encoder will be injected into newEncoder,
+// so your encoder can be configured in config file like this:
+//
+//     "handlers": {
+//         "console": {
+//             "encoder": "myEncoder",
+//             "prefix": "[my-app]"
+//         }
+//     }
+//
+// Then a map[string]interface{} {
+//            "encoder": "myEncoder",
+//            "prefix": "[my-app]"
+//        } will be injected to params.
+func RegisterEncoder(name string, newEncoder func(params map[string]interface{}) Encoder) error {
+	mutexOfEncoders.Lock()
+	defer mutexOfEncoders.Unlock()
+	if _, ok := encoders[name]; ok {
+		return EncoderIsExistedError
+	}
+	encoders[name] = newEncoder
+	return nil
+}
+
+// DeregisterEncoder removes the encoder registered with name.
+// Nothing will happen if the name doesn't exist.
+// It's useful in testing, so registrations won't leak across cases.
+func DeregisterEncoder(name string) {
+	mutexOfEncoders.Lock()
+	defer mutexOfEncoders.Unlock()
+	delete(encoders, name)
+}
+
+// isEncoderRegistered returns true if an encoder called name is registered.
+func isEncoderRegistered(name string) bool {
+	mutexOfEncoders.RLock()
+	defer mutexOfEncoders.RUnlock()
+	_, ok := encoders[name]
+	return ok
+}
+
+// encoderOf returns the encoder called name, which is created with params.
+// Return an error wrapping InvalidConfigError if the encoder doesn't exist.
+func encoderOf(name string, params map[string]interface{}) (Encoder, error) {
+	mutexOfEncoders.RLock()
+	newEncoder, ok := encoders[name]
+	mutexOfEncoders.RUnlock()
 	if !ok {
-		fmt.Fprintf(os.Stderr, "Error: The encoder \"%s\" you pointed doesn't exist! Try \"text\" or \"json\".\n", name)
-		os.Exit(2)
+		return nil, fmt.Errorf("%w: encoder \"%s\" doesn't exist, try \"text\" or \"json\"", InvalidConfigError, name)
 	}
-	return encoder
+	return newEncoder(params), nil
 }
 
 // =================================== text encoder ===================================
 
 // TextEncoder encodes a log to a plain string like "[Info] [2020-03-06 16:10:44] msg" in bytes.
-// If timeFormat == "", then it will not format time and keep time in unix form.
+// If timeFormat == "", then it will not format time and keep time in unix form. See TimeFormat.
+// Fields are appended like key=value, and a string value containing spaces, "=" or double quotes
+// will be quoted like key="a b" with backslashes and double quotes escaped, so the pairs can be split
+// unambiguously. Other characters like line breaks in a stack are kept as they are.
 func TextEncoder() Encoder {
+	return textEncoder(false, true)
+}
+
+// TextEncoderPaddedLevel is the same as TextEncoder except the level will be padded to a fixed width
+// like "[info ]" and "[error]", so the output in terminal will be aligned in columns.
+func TextEncoderPaddedLevel() Encoder {
+	return textEncoder(true, true)
+}
+
+// paddedLevelWidth is the width of padded level, which is the length of the longest level name.
+const paddedLevelWidth = 5
+
+// textEncoder returns an encoder encoding logs to plain strings.
+// The padLevel decides if the level should be padded to a fixed width.
+// The withTime decides if the time should be written, and timeFormat will be ignored if it's false.
+func textEncoder(padLevel bool, withTime bool) Encoder {
 	return func(log *Log, timeFormat string) []byte {
 
 		// 组装 log
 		buffer := bytes.NewBuffer(make([]byte, 0, 64))
 		buffer.WriteString("[")
-		buffer.WriteString(log.Level().String())
-		buffer.WriteString("] [")
+		level := log.Level().String()
+		buffer.WriteString(level)
 
-		// 判断是否需要格式化时间
-		if timeFormat != "" {
-			buffer.WriteString(log.Now().Format(timeFormat))
-		} else {
-			buffer.WriteString(strconv.FormatInt(log.Now().Unix(), 10))
+		// 补齐日志级别的宽度，让终端中的输出对齐
+		if padLevel && len(level) < paddedLevelWidth {
+			buffer.WriteString(strings.Repeat(" ", paddedLevelWidth-len(level)))
 		}
 
 		buffer.WriteString("] ")
 
+		// 判断是否需要格式化时间
+		if withTime {
+			buffer.WriteString("[")
+			writeTime(buffer, log, timeFormat, false)
+			buffer.WriteString("] ")
+		}
+
 		// 如果有文件信息，就把文件信息也加进去
 		if log.file != "" && log.Line() != 0 {
 			buffer.WriteString("[")
 			buffer.WriteString(log.File() + ":" + strconv.Itoa(log.Line()))
+			if log.callerFunc != "" {
+				buffer.WriteString(" " + log.callerFunc)
+			}
 			buffer.WriteString("] ")
 		}
 
 		buffer.WriteString(log.Msg())
+
+		// 单调时钟的读数以纳秒为单位，和字段的形式一样
+		if log.mono > 0 {
+			buffer.WriteString(" " + MonoKey + "=" + strconv.FormatInt(int64(log.mono), 10))
+		}
+
+		// 结构化的字段以 key=value 的形式追加在 msg 后面
+		for _, field := range log.fields {
+			buffer.WriteString(" ")
+			buffer.WriteString(field.Key)
+			buffer.WriteString("=")
+			writeTextFieldValue(buffer, renderFieldValue(field.Value))
+		}
+
 		buffer.WriteString("\n")
 		return buffer.Bytes()
 	}
 }
 
+// writeTextFieldValue writes value of a field to buffer in text form.
+// A string value will be quoted if it contains spaces, "=" or double quotes.
+func writeTextFieldValue(buffer *bytes.Buffer, value interface{}) {
+	if s, ok := value.(string); ok && strings.ContainsAny(s, " =\"") {
+		buffer.WriteString(`"`)
+		buffer.WriteString(textQuoteReplacer.Replace(s))
+		buffer.WriteString(`"`)
+		return
+	}
+	writeTextValue(buffer, value)
+}
+
+// textQuoteReplacer escapes backslashes and double quotes in a quoted string value of text encoder.
+var textQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// writeTextValue writes value to buffer in text form.
+func writeTextValue(buffer *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("<nil>")
+	case string:
+		buffer.WriteString(v)
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case int:
+		buffer.WriteString(strconv.Itoa(v))
+	case int64:
+		buffer.WriteString(strconv.FormatInt(v, 10))
+	case time.Duration:
+		// 时间间隔使用人类友好的形式，比如 350ms 和 1.2s
+		buffer.WriteString(v.String())
+	case error:
+		writeTextError(buffer, v)
+	case fmt.Stringer:
+		// nil 指针调用 String 方法可能会 panic，和 fmt 一样输出 <nil>
+		if isNilPointer(v) {
+			buffer.WriteString("<nil>")
+			return
+		}
+		buffer.WriteString(v.String())
+	case []byte:
+		buffer.Write(v)
+	default:
+		writeTextCollection(buffer, v)
+	}
+}
+
+// isNilPointer returns true if value is a nil pointer, whose methods may panic.
+func isNilPointer(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// writeTextCollection writes value to buffer in a compact form if it's a slice or a map.
+// A slice will be like [a b c] and a map will be like {k1:v1 k2:v2} with sorted keys.
+// Other values will be written like fmt.Sprintf("%v").
+func writeTextCollection(buffer *bytes.Buffer, value interface{}) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		buffer.WriteString("[")
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				buffer.WriteString(" ")
+			}
+			writeTextValue(buffer, rv.Index(i).Interface())
+		}
+		buffer.WriteString("]")
+	case reflect.Map:
+		// map 的遍历顺序是随机的，为了输出稳定，需要按照 key 进行排序
+		keys := rv.MapKeys()
+		keyStrings := make([]string, len(keys))
+		for i, key := range keys {
+			keyStrings[i] = fmt.Sprintf("%v", key.Interface())
+		}
+
+		indexes := make([]int, len(keys))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		sort.Slice(indexes, func(i, j int) bool {
+			return keyStrings[indexes[i]] < keyStrings[indexes[j]]
+		})
+
+		buffer.WriteString("{")
+		for i, index := range indexes {
+			if i > 0 {
+				buffer.WriteString(" ")
+			}
+			buffer.WriteString(keyStrings[index])
+			buffer.WriteString(":")
+			writeTextValue(buffer, rv.MapIndex(keys[index]).Interface())
+		}
+		buffer.WriteString("}")
+	default:
+		buffer.WriteString(fmt.Sprintf("%v", value))
+	}
+}
+
 // =================================== json encoder ===================================
 
 // JsonEncoder encodes a log to a Json string like `{"level":"debug", "time":"2020-03-22 22:35:00", "msg":"log content..."}` in bytes.
-// If timeFormat == "", then it will not format time and keep time in unix form.
+// If timeFormat == "", then it will not format time and keep time in unix form. See TimeFormat.
 func JsonEncoder() Encoder {
+	return jsonEncoder(false, StackKey, StackAsString)
+}
+
+// JsonEncoderOmitEmpty is the same as JsonEncoder except fields with empty values will be omitted.
+// An empty value is nil, an empty string or a zero-length slice/map. The standard keys like
+// level, time and msg will always be encoded. It keeps lines compact for downstream storage.
+func JsonEncoderOmitEmpty() Encoder {
+	return jsonEncoder(true, StackKey, StackAsString)
+}
+
+// StackFormat decides how a stack is rendered by JsonEncoderWithStack.
+type StackFormat uint8
+
+const (
+	// StackAsString renders a stack as a string whose frames are joined with "\n".
+	StackAsString StackFormat = iota
+
+	// StackAsFrames renders a stack as an array of frames like ["main.main()  /app/main.go:12 +0x25"].
+	// Each frame is a function and its location, and the goroutine header is the first element.
+	StackAsFrames
+)
+
+// JsonEncoderWithStack is the same as JsonEncoder except the field of stack will be written with key
+// and rendered in format, because backends differ on that. For example, ELK wants it in a string called
+// "error.stack_trace". The field of stack is the one whose key is StackKey, and its value can be a string
+// like debug.Stack() returns or a []string of frames.
+func JsonEncoderWithStack(key string, format StackFormat) Encoder {
+	return jsonEncoder(false, key, format)
+}
+
+// JsonEncoderWrapped is the same as JsonEncoder except the Json object is nested under rootKey like
+// `{"log":{"level":"debug","time":"2020-03-22 22:35:00","msg":"log content..."}}`, because some backends
+// require it. An empty rootKey produces the flat object like JsonEncoder.
+func JsonEncoderWrapped(rootKey string) Encoder {
+	encoder := JsonEncoder()
+	if rootKey == "" {
+		return encoder
+	}
+
+	prefix := `{"` + escapeString(rootKey) + `":`
+	return func(log *Log, timeFormat string) []byte {
+		encoded := encoder(log, timeFormat)
+
+		// 去掉原来的换行符，包裹之后再加上
+		wrapped := make([]byte, 0, len(prefix)+len(encoded)+2)
+		wrapped = append(wrapped, prefix...)
+		wrapped = append(wrapped, bytes.TrimSuffix(encoded, []byte("\n"))...)
+		return append(wrapped, "}\n"...)
+	}
+}
+
+// jsonEncoder returns an encoder encoding logs to Json strings.
+// The omitEmpty decides if fields with empty values should be omitted.
+// The field of stack will be written with stackKey and rendered in stackFormat.
+func jsonEncoder(omitEmpty bool, stackKey string, stackFormat StackFormat) Encoder {
 	return func(log *Log, timeFormat string) []byte {
 
 		// 组装 log
@@ -107,25 +360,143 @@ func JsonEncoder() Encoder {
 		buffer.WriteString(`","time":`)
 
 		// 判断是否需要格式化时间
-		if timeFormat != "" {
-			buffer.WriteString(strconv.Quote(log.Now().Format(timeFormat)))
-		} else {
-			buffer.WriteString(strconv.FormatInt(log.Now().Unix(), 10))
+		writeTime(buffer, log, timeFormat, true)
+
+		// 单调时钟的读数以纳秒为单位
+		if log.mono > 0 {
+			buffer.WriteString(`,"` + MonoKey + `":` + strconv.FormatInt(int64(log.mono), 10))
 		}
 
 		// 如果有文件信息，就把文件信息也加进去
 		if log.file != "" && log.Line() != 0 {
 			buffer.WriteString(`,"file":"` + log.File())
 			buffer.WriteString(`","line":` + strconv.Itoa(log.Line()))
+			if log.callerFunc != "" {
+				buffer.WriteString(`,"func":"` + escapeString(log.callerFunc) + `"`)
+			}
 		}
 
 		buffer.WriteString(`,"msg":"`)
 		buffer.WriteString(escapeString(log.Msg()))
-		buffer.WriteString("\"}\n")
+		buffer.WriteString(`"`)
+
+		// 结构化的字段直接作为 Json 对象的属性
+		for _, field := range log.fields {
+			key := field.Key
+			value := renderFieldValue(field.Value)
+			if key == StackKey {
+				key = stackKey
+				value = renderStack(value, stackFormat)
+			}
+
+			if omitEmpty && isEmptyValue(value) {
+				continue
+			}
+
+			buffer.WriteString(`,"`)
+			buffer.WriteString(escapeString(key))
+			buffer.WriteString(`":`)
+			writeJsonValue(buffer, value)
+		}
+
+		buffer.WriteString("}\n")
 		return buffer.Bytes()
 	}
 }
 
+// renderStack renders stack in format if it's a string or a []string, and other values are returned directly.
+func renderStack(stack interface{}, format StackFormat) interface{} {
+	switch v := stack.(type) {
+	case string:
+		if format == StackAsFrames {
+			return stackFrames(v)
+		}
+		return v
+	case []string:
+		if format == StackAsString {
+			return strings.Join(v, "\n")
+		}
+		return v
+	default:
+		return stack
+	}
+}
+
+// stackFrames splits stack into frames. In a stack like debug.Stack() returns, a function is followed
+// by its location in a line starting with "\t", so they are joined to one frame.
+func stackFrames(stack string) []string {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	frames := make([]string, 0, len(lines)/2+1)
+	for i := 0; i < len(lines); i++ {
+		frame := strings.TrimSpace(lines[i])
+		if frame == "" {
+			continue
+		}
+
+		// 函数的下一行是以 \t 开头的文件位置，合并成一帧
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			frame += "  " + strings.TrimSpace(lines[i+1])
+			i++
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// isEmptyValue returns true if value is nil, an empty string or a zero-length slice/map.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// writeJsonValue writes value to buffer in Json form.
+// Common types are written directly, and others will be marshaled by encoding/json.
+// If marshaling failed, the value will be written as a string like fmt.Sprintf("%v").
+// Notice that the keys of maps are sorted recursively by both of them, so the output is deterministic.
+func writeJsonValue(buffer *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case string:
+		buffer.WriteString(`"` + escapeString(v) + `"`)
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case int:
+		buffer.WriteString(strconv.Itoa(v))
+	case int64:
+		buffer.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		// Json 中不支持 NaN 和 Inf，只能转成字符串
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			buffer.WriteString(`"` + strconv.FormatFloat(v, 'f', -1, 64) + `"`)
+		} else {
+			buffer.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	case error:
+		writeJsonError(buffer, v)
+	default:
+		marshaled, err := json.Marshal(v)
+		if err != nil {
+			buffer.WriteString(`"` + escapeString(fmt.Sprintf("%v", v)) + `"`)
+			return
+		}
+		buffer.Write(marshaled)
+	}
+}
+
 // escapeString is for escaping string from special characters, such as double quotes.
 // See issue: https://github.com/FishGoddess/logit/issues/1
 func escapeString(s string) string {
@@ -153,3 +524,23 @@ func escapeString(s string) string {
 
 	return builder.String()
 }
+
+// ================================= pipeline encoder =================================
+
+// NewPipelineEncoder returns an encoder which encodes a log with base, then passes the output
+// through transforms in order. It's useful for post-processing without writing a whole new encoder:
+//
+//     encoder := logit.NewPipelineEncoder(logit.JsonEncoder(), func(encoded []byte) []byte {
+//         return append([]byte("[tenant-a] "), encoded...)
+//     })
+//
+// Notice that the bytes passed to a transform may be reused, so never retain them.
+func NewPipelineEncoder(base Encoder, transforms ...func(encoded []byte) []byte) Encoder {
+	return func(log *Log, timeFormat string) []byte {
+		encoded := base.Encode(log, timeFormat)
+		for _, transform := range transforms {
+			encoded = transform(encoded)
+		}
+		return encoded
+	}
+}
@@ -19,6 +19,11 @@
 package logit
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,11 +31,10 @@ import (
 // 测试获取编码器
 func TestEncoderOf(t *testing.T) {
 
-	// 这个不存在的编码器退出程序
-	//encoder := encoderOf("fake-encoder")
-	//if encoder != nil {
-	//	t.Fatal("encoderOf 中 encoder 应该为 nil")
-	//}
+	// 这个不存在的编码器返回错误
+	if _, err := encoderOf("fake-encoder", nil); !errors.Is(err, InvalidConfigError) {
+		t.Fatalf("不存在的编码器应该返回错误！%v", err)
+	}
 
 	log := &Log{
 		level: DebugLevel,
@@ -39,10 +43,321 @@ func TestEncoderOf(t *testing.T) {
 	}
 
 	// 判断获取的编码器是否正确
-	if string(encoderOf("text").Encode(log, DefaultTimeFormat)) != string(TextEncoder().Encode(log, DefaultTimeFormat)) {
+	encoder, err := encoderOf("text", nil)
+	if err != nil || string(encoder.Encode(log, DefaultTimeFormat)) != string(TextEncoder().Encode(log, DefaultTimeFormat)) {
 		t.Fatal("encoderOf(\"text\") 出现问题！")
 	}
-	if string(encoderOf("json").Encode(log, "")) != string(JsonEncoder().Encode(log, "")) {
+
+	encoder, err = encoderOf("json", nil)
+	if err != nil || string(encoder.Encode(log, "")) != string(JsonEncoder().Encode(log, "")) {
 		t.Fatal("encoderOf(\"json\") 出现问题！")
 	}
 }
+
+// 测试注册自定义的编码器，并在配置文件中使用
+func TestRegisterEncoder(t *testing.T) {
+	err := RegisterEncoder("prefixed", func(params map[string]interface{}) Encoder {
+		prefix, _ := params["prefix"].(string)
+		return NewPipelineEncoder(TextEncoder(), func(encoded []byte) []byte {
+			return append([]byte(prefix), encoded...)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterEncoder("prefixed")
+
+	if err := RegisterEncoder("json", nil); err != EncoderIsExistedError {
+		t.Fatalf("重复注册编码器应该返回 EncoderIsExistedError！%v", err)
+	}
+
+	config := `
+		"handlers": {
+			"console": {
+				"encoder": "prefixed",
+				"prefix": "[my-app] "
+			}
+		}
+	`
+	if err := ValidateConfig([]byte(config)); err != nil {
+		t.Fatalf("使用自定义编码器的配置校验失败！%v", err)
+	}
+
+	output := captureStdout(t, func() {
+		NewLoggerFrom(strings.NewReader(config)).Info("custom encoder")
+	})
+
+	if !strings.HasPrefix(output, "[my-app] [info] [") || !strings.HasSuffix(output, "custom encoder\n") {
+		t.Fatalf("自定义编码器的输出不正确！%s", output)
+	}
+
+	DeregisterEncoder("prefixed")
+	if err := ValidateConfig([]byte(config)); !errors.Is(err, InvalidConfigError) {
+		t.Fatalf("注销之后的编码器不应该通过校验！%v", err)
+	}
+}
+
+// 测试 Json 编码器按照字符串和数组两种形式输出堆栈
+func TestJsonEncoderWithStack(t *testing.T) {
+	stack := "goroutine 1 [running]:\nmain.f(...)\n\t/app/main.go:12 +0x25\nmain.main()\n\t/app/main.go:5 +0x1d\n"
+	frames := []string{"goroutine 1 [running]:", "main.f(...)  /app/main.go:12 +0x25", "main.main()  /app/main.go:5 +0x1d"}
+
+	cases := []struct {
+		encoder Encoder
+		key     string
+		value   interface{}
+		expect  interface{}
+	}{
+		{JsonEncoder(), "stack", stack, stack},
+		{JsonEncoderWithStack("stack_trace", StackAsString), "stack_trace", stack, stack},
+		{JsonEncoderWithStack("stack_trace", StackAsString), "stack_trace", frames, strings.Join(frames, "\n")},
+		{JsonEncoderWithStack("error.stack_trace", StackAsFrames), "error.stack_trace", stack, frames},
+		{JsonEncoderWithStack("stack", StackAsFrames), "stack", frames, frames},
+	}
+
+	for i, c := range cases {
+		log := &Log{level: ErrorLevel, now: time.Now(), msg: "stack", fields: Fields{{Key: StackKey, Value: c.value}}}
+
+		m := map[string]interface{}{}
+		if err := json.Unmarshal(c.encoder.Encode(log, ""), &m); err != nil {
+			t.Fatal(err)
+		}
+
+		if c.key != StackKey && m[StackKey] != nil {
+			t.Fatalf("第 %d 个案例的堆栈字段没有改名！%v", i+1, m)
+		}
+
+		value := m[c.key]
+		if array, ok := value.([]interface{}); ok {
+			strs := make([]string, 0, len(array))
+			for _, v := range array {
+				strs = append(strs, v.(string))
+			}
+			value = strs
+		}
+
+		if !reflect.DeepEqual(value, c.expect) {
+			t.Fatalf("第 %d 个案例的堆栈不正确！%#v", i+1, value)
+		}
+	}
+}
+
+// 测试包裹在根 key 下面的 Json 编码器
+func TestJsonEncoderWrapped(t *testing.T) {
+	log := &Log{level: InfoLevel, now: time.Unix(0, 0), msg: "wrapped", fields: Fields{{Key: "id", Value: 123}}}
+
+	encoded := JsonEncoderWrapped("log").Encode(log, "")
+	if !bytes.HasSuffix(encoded, []byte("}}\n")) || bytes.Count(encoded, []byte("\n")) != 1 {
+		t.Fatalf("包裹之后的 Json 格式不正确！%q", encoded)
+	}
+
+	m := map[string]map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	root, ok := m["log"]
+	if len(m) != 1 || !ok {
+		t.Fatalf("根 key 不正确！%s", encoded)
+	}
+
+	if root["level"] != "info" || root["msg"] != "wrapped" || root["id"] != float64(123) {
+		t.Fatalf("包裹的日志不正确！%s", encoded)
+	}
+
+	// 根 key 为空的时候和 JsonEncoder 一样
+	if flat := JsonEncoderWrapped("").Encode(log, ""); !bytes.Equal(flat, JsonEncoder().Encode(log, "")) {
+		t.Fatalf("根 key 为空的时候应该不包裹！%s", flat)
+	}
+}
+
+// 测试忽略空字段的 Json 编码器
+func TestJsonEncoderOmitEmpty(t *testing.T) {
+	var nilPointer *int
+	log := &Log{
+		level: InfoLevel,
+		now:   time.Unix(0, 0),
+		msg:   "",
+		fields: []Field{
+			{Key: "nil", Value: nil},
+			{Key: "empty", Value: ""},
+			{Key: "emptySlice", Value: []string{}},
+			{Key: "emptyMap", Value: map[string]int{}},
+			{Key: "nilPointer", Value: nilPointer},
+			{Key: "name", Value: "fish"},
+			{Key: "zero", Value: 0},
+			{Key: "tags", Value: []string{"a"}},
+		},
+	}
+
+	encoded := string(JsonEncoderOmitEmpty().Encode(log, ""))
+	want := `{"level":"info","time":0,"msg":"","name":"fish","zero":0,"tags":["a"]}` + "\n"
+	if encoded != want {
+		t.Fatalf("忽略空字段的结果不正确！\n%s%s", encoded, want)
+	}
+
+	// 默认的 Json 编码器不忽略空字段
+	encoded = string(JsonEncoder().Encode(log, ""))
+	if !strings.Contains(encoded, `"nil":null,"empty":"","emptySlice":[],"emptyMap":{}`) {
+		t.Fatalf("默认的 Json 编码器不应该忽略空字段！%s", encoded)
+	}
+}
+
+// 测试文本编码器输出切片和 map 类型的字段
+func TestTextEncoderCollections(t *testing.T) {
+	log := &Log{
+		level: InfoLevel,
+		now:   time.Unix(0, 0),
+		msg:   "collections",
+		fields: []Field{
+			{Key: "tags", Value: []string{"a", "b", "c"}},
+			{Key: "meta", Value: map[string]int{"k2": 2, "k1": 1}},
+			{Key: "nested", Value: map[string]interface{}{"ids": []int{1, 2}, "empty": []string{}}},
+			{Key: "array", Value: [2]bool{true, false}},
+			{Key: "bytes", Value: []byte("raw")},
+		},
+	}
+
+	encoded := string(TextEncoder().Encode(log, ""))
+	want := "[info] [0] collections tags=[a b c] meta={k1:1 k2:2} nested={empty:[] ids:[1 2]} array=[true false] bytes=raw\n"
+	if encoded != want {
+		t.Fatalf("切片和 map 的输出不正确！\n%s%s", encoded, want)
+	}
+}
+
+// 测试日志级别补齐宽度的文本编码器
+func TestTextEncoderPaddedLevel(t *testing.T) {
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		log := &Log{level: level, now: time.Unix(0, 0), msg: "padded"}
+		encoded := string(TextEncoderPaddedLevel().Encode(log, ""))
+
+		token := encoded[:strings.Index(encoded, "]")+1]
+		if len(token) != paddedLevelWidth+2 || !strings.HasPrefix(token, "["+level.String()) {
+			t.Fatalf("日志级别 %s 补齐之后的宽度不正确！%q", level, token)
+		}
+
+		if !strings.HasSuffix(encoded, "] [0] padded\n") {
+			t.Fatalf("补齐日志级别影响了其他内容！%q", encoded)
+		}
+	}
+}
+
+// 测试对编码器的输出进行后置处理
+func TestNewPipelineEncoder(t *testing.T) {
+	encoder := NewPipelineEncoder(JsonEncoder(), func(encoded []byte) []byte {
+		return append([]byte("[tenant-a] "), encoded...)
+	}, func(encoded []byte) []byte {
+		return bytes.Replace(encoded, []byte("\n"), []byte(" #end\n"), -1)
+	})
+
+	log := &Log{level: InfoLevel, now: time.Unix(1598198712, 0), msg: "pipeline"}
+	expect := `[tenant-a] {"level":"info","time":1598198712,"msg":"pipeline"} #end` + "\n"
+	if encoded := string(encoder.Encode(log, UnixTimeFormat)); encoded != expect {
+		t.Fatalf("管道编码器的输出不正确！%s", encoded)
+	}
+}
+
+// 测试文本编码器以人类友好的形式输出时间间隔
+func TestTextEncoderDuration(t *testing.T) {
+	log := &Log{
+		level: InfoLevel,
+		now:   time.Unix(1598198712, 0),
+		msg:   "duration",
+		fields: []Field{
+			{Key: "latency", Value: 350 * time.Millisecond},
+			{Key: "timeout", Value: 1200 * time.Millisecond},
+		},
+	}
+
+	expect := "[info] [1598198712] duration latency=350ms timeout=1.2s\n"
+	if encoded := string(TextEncoder().Encode(log, UnixTimeFormat)); encoded != expect {
+		t.Fatalf("文本编码器输出的时间间隔不正确！%s", encoded)
+	}
+
+	expect = `{"level":"info","time":1598198712,"msg":"duration","latency":350000000,"timeout":1200000000}` + "\n"
+	if encoded := string(JsonEncoder().Encode(log, UnixTimeFormat)); encoded != expect {
+		t.Fatalf("Json 编码器输出的时间间隔不正确！%s", encoded)
+	}
+}
+
+// 测试嵌套的 map 字段在 Json 中按照 key 排序输出
+func TestJsonEncoderSortedMapKeys(t *testing.T) {
+	log := &Log{
+		level: InfoLevel,
+		msg:   "nested",
+		fields: []Field{
+			{Key: "user", Value: map[string]interface{}{
+				"zone": "cn",
+				"name": "fish",
+				"meta": map[string]interface{}{"z": 1, "a": 2, "m": []interface{}{map[string]int{"y": 1, "b": 2}}},
+			}},
+		},
+	}
+
+	// 多次编码的结果必须一致，并且 key 是有序的
+	expect := `"user":{"meta":{"a":2,"m":[{"b":2,"y":1}],"z":1},"name":"fish","zone":"cn"}}`
+	for i := 0; i < 100; i++ {
+		encoded := string(JsonEncoder().Encode(log, ""))
+		if !strings.HasSuffix(encoded, expect+"\n") {
+			t.Fatalf("第 %d 次编码嵌套的 map 结果不正确！%s", i+1, encoded)
+		}
+	}
+}
+
+// 测试文本编码器给包含空格和等号的字符串加上引号
+func TestTextEncoderQuotedString(t *testing.T) {
+	log := &Log{
+		level: InfoLevel,
+		now:   time.Unix(0, 0),
+		msg:   "quoted",
+		fields: []Field{
+			{Key: "plain", Value: "fish"},
+			{Key: "space", Value: "fish goddess"},
+			{Key: "equal", Value: "a=b"},
+			{Key: "quote", Value: `say "hi"`},
+			{Key: "path", Value: `C:\a b`},
+			{Key: "empty", Value: ""},
+		},
+	}
+
+	encoded := string(TextEncoder().Encode(log, ""))
+	want := `[info] [0] quoted plain=fish space="fish goddess" equal="a=b" quote="say \"hi\"" path="C:\\a b" empty=` + "\n"
+	if encoded != want {
+		t.Fatalf("字符串的引号不正确！\n%s%s", encoded, want)
+	}
+}
+
+// 指针类型的 Stringer，nil 指针调用 String 方法会 panic
+type pointerStringer struct {
+	name string
+}
+
+func (ps *pointerStringer) String() string {
+	return ps.name
+}
+
+// 测试文本编码器输出 nil 指针的 Stringer 和错误
+func TestTextEncoderNilPointer(t *testing.T) {
+	var stringer *pointerStringer
+	var err *pointerError
+	log := &Log{
+		level:  InfoLevel,
+		now:    time.Unix(0, 0),
+		msg:    "nil",
+		fields: []Field{{Key: "stringer", Value: stringer}, {Key: "err", Value: err}},
+	}
+
+	encoded := string(TextEncoder().Encode(log, ""))
+	want := "[info] [0] nil stringer=<nil> err=*logit.pointerError:<nil>\n"
+	if encoded != want {
+		t.Fatalf("nil 指针的输出不正确！\n%s%s", encoded, want)
+	}
+}
+
+// 测试不输出时间的文本编码器
+func TestTextEncoderWithoutTime(t *testing.T) {
+	log := &Log{level: InfoLevel, now: time.Now(), msg: "event", fields: []Field{{Key: "id", Value: 1}}}
+	if encoded := string(textEncoder(false, false).Encode(log, DefaultTimeFormat)); encoded != "[info] event id=1\n" {
+		t.Fatalf("不输出时间的文本编码器结果不正确！%q", encoded)
+	}
+}
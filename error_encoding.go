@@ -0,0 +1,128 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/25 21:10:34
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// chainedError is an error whose wrapped errors will be encoded, too. See ErrorChain.
+type chainedError struct {
+	err error
+}
+
+// ErrorChain returns an error which is the same as err, but encoders will encode all errors
+// wrapped by err, too. Use it as the value of a field if you want the whole chain in your log:
+//
+//     logger.ErrorWith(logit.Fields{{Key: "err", Value: logit.ErrorChain(err)}}, "request failed")
+//
+func ErrorChain(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &chainedError{err: err}
+}
+
+// Error returns the message of the error inside, and "<nil>" if it's a nil pointer.
+func (ce *chainedError) Error() string {
+	if isNilPointer(ce.err) {
+		return "<nil>"
+	}
+	return ce.err.Error()
+}
+
+// Unwrap returns the error inside, so errors.Is and errors.As still work.
+func (ce *chainedError) Unwrap() error {
+	return ce.err
+}
+
+// errorChainOf returns err and the errors wrapped by it if err is a chainedError.
+// Otherwise, only err will be returned.
+func errorChainOf(err error) []error {
+	ce, ok := err.(*chainedError)
+	if !ok {
+		return []error{err}
+	}
+
+	var chain []error
+	for e := ce.err; e != nil; e = errors.Unwrap(e) {
+		chain = append(chain, e)
+
+		// nil 指针调用 Unwrap 方法可能会 panic，所以到这里就结束
+		if isNilPointer(e) {
+			break
+		}
+	}
+	return chain
+}
+
+// writeTextError writes err to buffer in text like "*net.OpError:dial tcp: i/o timeout".
+// The errors in chain will be joined with " <- ", and a nil pointer will be written like "*T:<nil>".
+func writeTextError(buffer *bytes.Buffer, err error) {
+	for i, e := range errorChainOf(err) {
+		if i > 0 {
+			buffer.WriteString(" <- ")
+		}
+		buffer.WriteString(fmt.Sprintf("%T", e))
+		buffer.WriteString(":")
+		if isNilPointer(e) {
+			buffer.WriteString("<nil>")
+			continue
+		}
+		buffer.WriteString(e.Error())
+	}
+}
+
+// writeJsonError writes err to buffer in Json like {"message":"i/o timeout","type":"*net.OpError"}.
+// The errors wrapped by err will be written to "chain" if err is a chainedError.
+func writeJsonError(buffer *bytes.Buffer, err error) {
+	chain := errorChainOf(err)
+	writeJsonErrorObject(buffer, chain[0])
+	if _, ok := err.(*chainedError); !ok {
+		buffer.WriteString("}")
+		return
+	}
+
+	buffer.WriteString(`,"chain":[`)
+	for i, e := range chain[1:] {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		writeJsonErrorObject(buffer, e)
+		buffer.WriteString("}")
+	}
+	buffer.WriteString("]}")
+}
+
+// writeJsonErrorObject writes the message and the type of err to buffer without the closing brace.
+// The message of a nil pointer will be "<nil>".
+func writeJsonErrorObject(buffer *bytes.Buffer, err error) {
+	message := "<nil>"
+	if !isNilPointer(err) {
+		message = err.Error()
+	}
+
+	buffer.WriteString(`{"message":"`)
+	buffer.WriteString(escapeString(message))
+	buffer.WriteString(`","type":"`)
+	buffer.WriteString(escapeString(fmt.Sprintf("%T", err)))
+	buffer.WriteString(`"`)
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/25 21:47:02
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 自定义的错误类型，包装了另一个错误
+type openError struct {
+	path string
+	err  error
+}
+
+func (oe *openError) Error() string {
+	return "open " + oe.path + ": " + oe.err.Error()
+}
+
+func (oe *openError) Unwrap() error {
+	return oe.err
+}
+
+// 测试编码 error 类型的字段时带上类型
+func TestEncodeError(t *testing.T) {
+	pathErr := &openError{path: "/logit.log", err: errors.New("permission denied")}
+	wrapped := fmt.Errorf("open log file: %w", pathErr)
+	fields := Fields{{Key: "err", Value: pathErr}, {Key: "chain", Value: ErrorChain(wrapped)}}
+
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger.ErrorWith(fields, "text")
+
+	expect := "text err=*logit.openError:open /logit.log: permission denied " +
+		"chain=*fmt.wrapError:open log file: open /logit.log: permission denied <- " +
+		"*logit.openError:open /logit.log: permission denied <- *errors.errorString:permission denied\n"
+	if !strings.HasSuffix(buffer.String(), expect) {
+		t.Fatalf("文本编码器输出的 error 不正确！%s", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.SetHandlers(NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat))
+	logger.ErrorWith(fields, "json")
+
+	expect = `"err":{"message":"open /logit.log: permission denied","type":"*logit.openError"},` +
+		`"chain":{"message":"open log file: open /logit.log: permission denied","type":"*fmt.wrapError","chain":[` +
+		`{"message":"open /logit.log: permission denied","type":"*logit.openError"},` +
+		`{"message":"permission denied","type":"*errors.errorString"}]}}` + "\n"
+	if !strings.HasSuffix(buffer.String(), expect) {
+		t.Fatalf("Json 编码器输出的 error 不正确！%s", buffer.String())
+	}
+}
+
+// 测试编码 nil 指针的错误
+func TestEncodeNilPointerError(t *testing.T) {
+	var err *pointerError
+	log := &Log{
+		level:  ErrorLevel,
+		now:    time.Unix(0, 0),
+		msg:    "nil",
+		fields: []Field{{Key: "err", Value: err}, {Key: "chain", Value: ErrorChain(err)}},
+	}
+
+	text := string(TextEncoder().Encode(log, ""))
+	if want := "[error] [0] nil err=*logit.pointerError:<nil> chain=*logit.pointerError:<nil>\n"; text != want {
+		t.Fatalf("文本编码的 nil 指针错误不正确！\n%s%s", text, want)
+	}
+
+	json := string(JsonEncoder().Encode(log, ""))
+	if !strings.Contains(json, `"err":{"message":"<nil>","type":"*logit.pointerError"}`) ||
+		!strings.Contains(json, `"chain":{"message":"<nil>","type":"*logit.pointerError","chain":[]}`) {
+		t.Fatalf("Json 编码的 nil 指针错误不正确！%s", json)
+	}
+
+	if ErrorChain(err).Error() != "<nil>" {
+		t.Fatalf("nil 指针的错误链信息不正确！%s", ErrorChain(err).Error())
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/20 21:48:09
+
+// +build !windows
+
+package logit
+
+import "errors"
+
+var (
+	// EventLogUnsupportedError is an error happening on creating an event log handler on non-Windows os.
+	EventLogUnsupportedError = errors.New("event log is only supported on Windows")
+)
+
+// NewEventLogHandler returns EventLogUnsupportedError because event log is only supported on Windows.
+// See the Windows version of NewEventLogHandler.
+func NewEventLogHandler(source string) (Handler, error) {
+	return nil, EventLogUnsupportedError
+}
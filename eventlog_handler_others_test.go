@@ -0,0 +1,31 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/20 22:03:37
+
+// +build !windows
+
+package logit
+
+import "testing"
+
+// 测试非 Windows 系统上创建事件日志处理器会返回错误
+func TestNewEventLogHandler(t *testing.T) {
+	handler, err := NewEventLogHandler("logit")
+	if handler != nil || err != EventLogUnsupportedError {
+		t.Fatalf("非 Windows 系统上创建事件日志处理器应该返回错误！%v %v", handler, err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/20 21:05:44
+
+package logit
+
+import (
+	"bytes"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// These are the event types of Windows event log.
+	eventLogErrorType       = 0x0001
+	eventLogWarningType     = 0x0002
+	eventLogInformationType = 0x0004
+
+	// eventLogEventID is the event id of all logs reported by eventLogHandler.
+	eventLogEventID = 1
+)
+
+var (
+	// These are the procedures of Windows event log in advapi32.dll.
+	// We use syscall directly so that logit still has no dependency.
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSource   = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEvent           = advapi32.NewProc("ReportEventW")
+)
+
+// eventLogHandler is a handler reporting logs to Windows event log.
+type eventLogHandler struct {
+
+	// handle is the handle of registered event source.
+	handle uintptr
+
+	// mu is for closing safely.
+	mu *sync.RWMutex
+}
+
+// NewEventLogHandler returns a handler reporting logs to Windows event log with source.
+// Debug and info logs are reported as information events, and warn logs are reported as
+// warning events, and error logs are reported as error events.
+//
+// Notice that the source should be registered in registry first, or the event viewer will show
+// a "description cannot be found" tip before your message. You can register it in PowerShell:
+//
+//     New-EventLog -LogName Application -Source "your source"
+//
+// Call Logger.Close to deregister the event source when your service stops.
+func NewEventLogHandler(source string) (Handler, error) {
+	sourcePtr, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, _, err := procRegisterEventSource.Call(0, uintptr(unsafe.Pointer(sourcePtr)))
+	if handle == 0 {
+		return nil, err
+	}
+
+	return &eventLogHandler{
+		handle: handle,
+		mu:     &sync.RWMutex{},
+	}, nil
+}
+
+// eventTypeOf returns the event type of level.
+func eventTypeOf(level Level) uint16 {
+	switch {
+	case level >= ErrorLevel:
+		return eventLogErrorType
+	case level == WarnLevel:
+		return eventLogWarningType
+	default:
+		return eventLogInformationType
+	}
+}
+
+// Handle reports log to Windows event log.
+// If reporting failed, the error will be reported to the error callback of logger.
+// Return true so that handlers after it will be used.
+func (elh *eventLogHandler) Handle(log *Log) bool {
+
+	// 事件日志自带时间，所以这里不需要再输出时间
+	msg := bytes.TrimSuffix(textEncoder(false, false).Encode(log, ""), []byte("\n"))
+	msgPtr, err := syscall.UTF16PtrFromString(string(msg))
+	if err != nil {
+		if log.logger != nil {
+			log.logger.reportError(err)
+		}
+		return true
+	}
+
+	elh.mu.RLock()
+	defer elh.mu.RUnlock()
+	if elh.handle == 0 {
+		return true
+	}
+
+	ok, _, err := procReportEvent.Call(
+		elh.handle,
+		uintptr(eventTypeOf(log.Level())),
+		0,
+		eventLogEventID,
+		0,
+		1,
+		0,
+		uintptr(unsafe.Pointer(&msgPtr)),
+		0,
+	)
+
+	if ok == 0 && log.logger != nil {
+		log.logger.reportError(err)
+	}
+	return true
+}
+
+// Close deregisters the event source.
+// It's safe to call it more than once.
+func (elh *eventLogHandler) Close() error {
+	elh.mu.Lock()
+	defer elh.mu.Unlock()
+	if elh.handle == 0 {
+		return nil
+	}
+
+	ok, _, err := procDeregisterEventSource.Call(elh.handle)
+	elh.handle = 0
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/20 22:10:25
+
+package logit
+
+import "testing"
+
+// 测试日志级别和事件类型的映射
+func TestEventTypeOf(t *testing.T) {
+	cases := map[Level]uint16{
+		DebugLevel: eventLogInformationType,
+		InfoLevel:  eventLogInformationType,
+		WarnLevel:  eventLogWarningType,
+		ErrorLevel: eventLogErrorType,
+	}
+
+	for level, eventType := range cases {
+		if eventTypeOf(level) != eventType {
+			t.Fatalf("日志级别 %s 映射的事件类型不正确！%d", level, eventTypeOf(level))
+		}
+	}
+}
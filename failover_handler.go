@@ -0,0 +1,96 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/26 21:18:55
+
+package logit
+
+// failoverHandler is a handler using fallback only when primary failed.
+type failoverHandler struct {
+
+	// primary is the handler handling all logs first.
+	primary Handler
+
+	// fallback is the handler handling logs which primary failed to handle.
+	fallback Handler
+}
+
+// NewFailoverHandler returns a handler which handles logs with primary, and uses fallback only
+// when primary failed. For example, the primary writes logs to network and the fallback writes
+// logs to a local file, so no log will be lost during a transient network outage.
+// The error of primary will be reported to the error callback of logger, too.
+//
+// Notice that primary must be an ErrorReportingHandler to report its failure, such as handlers
+// returned by NewStandardHandler. Otherwise, fallback will never be used.
+func NewFailoverHandler(primary Handler, fallback Handler) Handler {
+	return &failoverHandler{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Handle handles log with primary, and handles log with fallback if primary failed.
+// If fallback failed, too, the error will be reported to the error callback of logger.
+// Return true so that handlers after it will be used.
+func (fh *failoverHandler) Handle(log *Log) bool {
+	err := fh.HandleWithError(log)
+	if err != nil && log.logger != nil {
+		log.logger.reportError(err)
+	}
+	return true
+}
+
+// HandleWithError handles log with primary, and handles log with fallback if primary failed.
+// Return the error of fallback, so failover handlers can be nested.
+func (fh *failoverHandler) HandleWithError(log *Log) error {
+	primary, ok := fh.primary.(ErrorReportingHandler)
+	if !ok {
+		fh.primary.Handle(log)
+		return nil
+	}
+
+	err := primary.HandleWithError(log)
+	if err == nil {
+		return nil
+	}
+
+	// 主日志处理器失败了，上报错误之后使用备用的日志处理器
+	if log.logger != nil {
+		log.logger.reportError(err)
+	}
+
+	if fallback, ok := fh.fallback.(ErrorReportingHandler); ok {
+		return fallback.HandleWithError(log)
+	}
+	fh.fallback.Handle(log)
+	return nil
+}
+
+// Flush flushes primary and fallback if they are Flushers.
+func (fh *failoverHandler) Flush() error {
+	_, err := fh.FlushWithResult()
+	return err
+}
+
+// FlushWithResult flushes primary and fallback if they are Flushers, and returns the sum of results.
+func (fh *failoverHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers([]Handler{fh.primary, fh.fallback})
+}
+
+// Close closes primary and fallback if they are io.Closers.
+func (fh *failoverHandler) Close() error {
+	return closeHandlers([]Handler{fh.primary, fh.fallback})
+}
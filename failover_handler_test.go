@@ -0,0 +1,72 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/26 21:52:30
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 可以切换成功或失败的 writer，模拟网络故障
+type flakyWriter struct {
+	buffer bytes.Buffer
+	fail   bool
+}
+
+func (fw *flakyWriter) Write(p []byte) (n int, err error) {
+	if fw.fail {
+		return 0, errors.New("network is unreachable")
+	}
+	return fw.buffer.Write(p)
+}
+
+// 测试主日志处理器失败时使用备用的日志处理器
+func TestFailoverHandler(t *testing.T) {
+	primary := &flakyWriter{}
+	fallback := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewFailoverHandler(
+		NewStandardHandler(primary, TextEncoder(), DefaultTimeFormat),
+		NewStandardHandler(fallback, TextEncoder(), DefaultTimeFormat),
+	))
+
+	var errs []error
+	logger.SetErrorCallback(func(err error) {
+		errs = append(errs, err)
+	})
+
+	logger.Info("primary ok")
+	primary.fail = true
+	logger.Info("primary failed")
+	primary.fail = false
+	logger.Info("primary recovered")
+
+	if !strings.Contains(primary.buffer.String(), "primary ok") || !strings.Contains(primary.buffer.String(), "primary recovered") {
+		t.Fatalf("主日志处理器的输出不正确！%s", primary.buffer.String())
+	}
+
+	if strings.Count(fallback.String(), "\n") != 1 || !strings.Contains(fallback.String(), "primary failed") {
+		t.Fatalf("备用日志处理器的输出不正确！%s", fallback.String())
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("主日志处理器的错误没有上报！%v", errs)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 22:14:37
+
+package logit
+
+import "os"
+
+const (
+	// fatalExitCode is the status code of exiting on a log reaching the fatal threshold.
+	fatalExitCode = 1
+)
+
+var (
+	// exit exits the process with code, and it's replaceable in testing.
+	exit = os.Exit
+)
+
+// SetFatalThreshold sets the level from which logs are fatal. After handling a log not lower than
+// level, the logger will be closed to flush buffered logs, and the process will exit with status code 1.
+// Closing flushes all Flushers like FlushOnSignals does, including console handlers writing to a buffered
+// writer like bufio.Writer, so the fatal log is fully written before exiting.
+// It's useful for strict mode, such as failing fast on warnings in CI:
+//
+//     logger.SetFatalThreshold(logit.WarnLevel)
+//
+// Default is OffLevel, which means never, because no log is in OffLevel. Closing will wait at most 5 seconds.
+// Notice that deferred functions won't run when exiting, so don't use it if your application needs them.
+func (l *Logger) SetFatalThreshold(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fatalThreshold = level
+}
+
+// exitOnFatal closes l and exits the process. See Logger.SetFatalThreshold.
+func (l *Logger) exitOnFatal() {
+	if err := closeWithTimeout(l, closeTimeoutOnSignals); err != nil {
+		l.reportError(err)
+	}
+	exit(fatalExitCode)
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 22:36:48
+
+package logit
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 测试退出程序之前，带缓冲的控制台日志处理器中的日志已经全部写出
+func TestLoggerSetFatalThresholdFlushesPipe(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	// 退出的时候关闭管道的写入端，读取到的就是退出之前写出的全部日志
+	var output []byte
+	exited := 0
+	defer func(original func(code int)) {
+		exit = original
+	}(exit)
+	exit = func(code int) {
+		exited++
+		writer.Close()
+		output, _ = ioutil.ReadAll(reader)
+	}
+
+	logger := NewLogger(DebugLevel, NewStandardHandler(bufio.NewWriter(writer), TextEncoder(), DefaultTimeFormat))
+	logger.SetFatalThreshold(ErrorLevel)
+	for i := 0; i < 10; i++ {
+		logger.Info("buffered " + strconv.Itoa(i))
+	}
+	logger.Error("fatal message")
+
+	if exited != 1 {
+		t.Fatalf("退出的次数不正确！%d", exited)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(lines) != 11 || !strings.HasSuffix(string(output), "fatal message\n") {
+		t.Fatalf("退出之前日志没有全部写出！%q", output)
+	}
+
+	for i, line := range lines[:10] {
+		if !strings.HasSuffix(line, "buffered "+strconv.Itoa(i)) {
+			t.Fatalf("第 %d 条日志不完整！%q", i+1, line)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/02 14:21:36
+
+package logit
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	// RedactedValue is the value replacing the value of a redacted field.
+	// See Logger.RedactFields.
+	RedactedValue = "***"
+
+	// These are the keys of fields carrying the version and the commit of your application.
+	// See Logger.SetVersion.
+	VersionKey = "version"
+	CommitKey  = "commit"
+
+	// FieldsTruncatedKey is the key of field marking the fields of a log have been truncated.
+	// See Logger.SetMaxFields.
+	FieldsTruncatedKey = "fields_truncated"
+
+	// TruncatedValueSuffix is the suffix appended to a truncated field value.
+	// See Logger.SetMaxFieldValueLength.
+	TruncatedValueSuffix = "..."
+)
+
+// Field is a key-value pair attached to a log.
+// Fields make a log structured, so you can search or analyze logs by them.
+type Field struct {
+
+	// Key is the name of this field.
+	Key string
+
+	// Value is the value of this field.
+	Value interface{}
+}
+
+// Fields is an ordered collection of fields, which can be reused and cloned cheaply.
+// It's better than a map[string]interface{} when logging in a tight loop, because you can
+// set the changing values on the same Fields and no more map will be allocated.
+//
+//     fields := logit.Fields{{Key: "service", Value: "order"}}
+//     for _, id := range ids {
+//         fields.Set("id", id)
+//         logger.InfoWith(fields, "handling")
+//     }
+//
+type Fields []Field
+
+// Set sets value to the field whose key is key.
+// If the key doesn't exist, a new field will be appended.
+func (fs *Fields) Set(key string, value interface{}) {
+	for i := range *fs {
+		if (*fs)[i].Key == key {
+			(*fs)[i].Value = value
+			return
+		}
+	}
+	*fs = append(*fs, Field{Key: key, Value: value})
+}
+
+// Clone returns a copy of fs, so setting one of them won't affect another.
+func (fs Fields) Clone() Fields {
+	if fs == nil {
+		return nil
+	}
+	cloned := make(Fields, len(fs), cap(fs))
+	copy(cloned, fs)
+	return cloned
+}
+
+// lazyValue is the value of a lazy field, which will be generated only if the log is really handled.
+// See Logger.LazyField.
+type lazyValue func() interface{}
+
+// resolveLazyFields returns fields whose lazy values are replaced with the values generated by them.
+// If no field is lazy, fields will be returned directly without any allocation.
+func resolveLazyFields(fields []Field) []Field {
+	var resolved []Field
+	for i, field := range fields {
+		gen, ok := field.Value.(lazyValue)
+		if !ok {
+			continue
+		}
+
+		// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+		if resolved == nil {
+			resolved = make([]Field, len(fields))
+			copy(resolved, fields)
+		}
+		resolved[i].Value = gen()
+	}
+
+	if resolved == nil {
+		return fields
+	}
+	return resolved
+}
+
+// fieldsOf returns a slice of fields converted from m.
+// The fields are sorted by key, so the output of encoders is stable.
+func fieldsOf(m map[string]interface{}) []Field {
+	fields := make([]Field, 0, len(m))
+	for key, value := range m {
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+
+	// map 的遍历顺序是随机的，为了输出稳定，需要按照 key 进行排序
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+	return fields
+}
+
+// FieldMergeMode decides what to do when merging a field whose key already exists.
+// See Logger.SetFieldMergeMode.
+type FieldMergeMode uint8
+
+const (
+	// OverrideMode overrides the value of existing field with the new one. It's the default mode.
+	OverrideMode FieldMergeMode = iota
+
+	// AppendMode collects the value of existing field and the new one into a slice,
+	// so both of them will be recorded, like [parent child].
+	AppendMode
+)
+
+// mergedValues is the values of a field collected in AppendMode.
+type mergedValues []interface{}
+
+// mergeFields returns a new slice of fields which merges newFields into fields.
+// If a key of newFields already exists in fields, the value of it will be overridden.
+// Notice that fields will never be modified, because it may be shared by other loggers.
+func mergeFields(fields []Field, newFields []Field) []Field {
+	return mergeFieldsWithMode(fields, newFields, OverrideMode)
+}
+
+// mergeFieldsWithMode is the same as mergeFields, but the existing field will be merged in mode.
+func mergeFieldsWithMode(fields []Field, newFields []Field, mode FieldMergeMode) []Field {
+	merged := make([]Field, 0, len(fields)+len(newFields))
+	merged = append(merged, fields...)
+	for _, field := range newFields {
+		overridden := false
+		for i := range merged {
+			if merged[i].Key == field.Key {
+				merged[i].Value = mergeValue(merged[i].Value, field.Value, mode)
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// mergeValue returns the value merged from oldValue and newValue in mode.
+func mergeValue(oldValue interface{}, newValue interface{}, mode FieldMergeMode) interface{} {
+	if mode != AppendMode {
+		return newValue
+	}
+
+	// 已经合并过的值需要创建新的切片，因为旧的切片可能被其他 logger 共享
+	if values, ok := oldValue.(mergedValues); ok {
+		appended := make(mergedValues, 0, len(values)+1)
+		appended = append(appended, values...)
+		return append(appended, newValue)
+	}
+	return mergedValues{oldValue, newValue}
+}
+
+// withStaticFields returns fields of a log which carries staticFields and fields.
+// The same key in fields will be merged with the one in staticFields in mode. If one of them
+// is empty, the other one will be returned directly without any allocation.
+func withStaticFields(staticFields []Field, fields []Field, mode FieldMergeMode) []Field {
+	if len(fields) < 1 {
+		return staticFields
+	}
+
+	if len(staticFields) < 1 {
+		return fields
+	}
+	return mergeFieldsWithMode(staticFields, fields, mode)
+}
+
+// truncateFields returns the first maxFields fields with a marker whose key is FieldsTruncatedKey
+// if the count of fields is larger than maxFields. Otherwise, fields will be returned directly.
+// A maxFields <= 0 means unlimited.
+func truncateFields(fields []Field, maxFields int) []Field {
+	if maxFields <= 0 || len(fields) <= maxFields {
+		return fields
+	}
+
+	// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+	truncated := make([]Field, maxFields, maxFields+1)
+	copy(truncated, fields)
+	return append(truncated, Field{Key: FieldsTruncatedKey, Value: true})
+}
+
+// truncateFieldValues returns fields whose string values longer than maxLength bytes are truncated to
+// maxLength bytes with TruncatedValueSuffix. A value is never cut in the middle of a UTF-8 character,
+// so it may be a little shorter. If no field is changed, fields will be returned directly without any allocation.
+// A maxLength <= 0 means unlimited.
+func truncateFieldValues(fields []Field, maxLength int) []Field {
+	if maxLength <= 0 {
+		return fields
+	}
+
+	var truncated []Field
+	for i, field := range fields {
+		value, ok := field.Value.(string)
+		if !ok || len(value) <= maxLength {
+			continue
+		}
+
+		// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+		if truncated == nil {
+			truncated = make([]Field, len(fields))
+			copy(truncated, fields)
+		}
+
+		// 往前找到一个字符的开头，防止把一个 UTF-8 字符截断
+		end := maxLength
+		for end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+		truncated[i].Value = value[:end] + TruncatedValueSuffix
+	}
+
+	if truncated == nil {
+		return fields
+	}
+	return truncated
+}
+
+// redactFields returns fields whose keys are in redactedKeys replaced with RedactedValue.
+// If no field needs to be redacted, fields will be returned directly without any allocation.
+func redactFields(fields []Field, redactedKeys map[string]struct{}) []Field {
+	if len(redactedKeys) < 1 {
+		return fields
+	}
+
+	var redacted []Field
+	for i, field := range fields {
+		if _, ok := redactedKeys[field.Key]; !ok {
+			continue
+		}
+
+		// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+		if redacted == nil {
+			redacted = make([]Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i].Value = RedactedValue
+	}
+
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/19 20:08:31
+
+package logit
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	// fieldRenderers stores all field renderers registered.
+	// mutexOfFieldRenderers is for concurrency.
+	fieldRenderers        = map[reflect.Type]func(value interface{}) interface{}{}
+	mutexOfFieldRenderers = &sync.RWMutex{}
+)
+
+// RegisterFieldRenderer registers render for values of type typ.
+// Encoders will call render to transform a field value of typ before encoding it,
+// so your domain types can be rendered in a specific way without pre-stringifying:
+//
+//     logit.RegisterFieldRenderer(reflect.TypeOf(uuid.UUID{}), func(value interface{}) interface{} {
+//         return value.(uuid.UUID).String()
+//     })
+//
+// The renderer registered before with the same typ will be replaced.
+// Notice that only the values of fields will be rendered, not the elements inside them.
+func RegisterFieldRenderer(typ reflect.Type, render func(value interface{}) interface{}) {
+	mutexOfFieldRenderers.Lock()
+	defer mutexOfFieldRenderers.Unlock()
+	fieldRenderers[typ] = render
+}
+
+// renderFieldValue returns value transformed by the renderer registered with its type.
+// If no renderer is registered for this type, value will be returned directly.
+func renderFieldValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	mutexOfFieldRenderers.RLock()
+	render, ok := fieldRenderers[reflect.TypeOf(value)]
+	mutexOfFieldRenderers.RUnlock()
+
+	if !ok {
+		return value
+	}
+	return render(value)
+}
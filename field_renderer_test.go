@@ -0,0 +1,60 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/19 20:31:47
+
+package logit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 自定义的领域类型，单位是分
+type money int64
+
+// 测试注册字段渲染器之后，编码器使用渲染后的值
+func TestRegisterFieldRenderer(t *testing.T) {
+	typ := reflect.TypeOf(money(0))
+	RegisterFieldRenderer(typ, func(value interface{}) interface{} {
+		m := value.(money)
+		return fmt.Sprintf("¥%d.%02d", m/100, m%100)
+	})
+	defer func() {
+		mutexOfFieldRenderers.Lock()
+		delete(fieldRenderers, typ)
+		mutexOfFieldRenderers.Unlock()
+	}()
+
+	fields := Fields{{Key: "price", Value: money(1999)}, {Key: "count", Value: 2}}
+
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger.InfoWith(fields, "text")
+	if !strings.HasSuffix(buffer.String(), "text price=¥19.99 count=2\n") {
+		t.Fatalf("文本编码器没有使用渲染后的值！%s", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.SetHandlers(NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat))
+	logger.InfoWith(fields, "json")
+	if !strings.HasSuffix(buffer.String(), `"msg":"json","price":"¥19.99","count":2}`+"\n") {
+		t.Fatalf("Json 编码器没有使用渲染后的值！%s", buffer.String())
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/04 22:16:45
+
+package logit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 测试 Fields 的设置和克隆
+func TestFieldsSetAndClone(t *testing.T) {
+	fields := Fields{{Key: "service", Value: "order"}}
+	fields.Set("id", 1)
+	fields.Set("service", "pay")
+
+	if len(fields) != 2 || fields[0].Value != "pay" || fields[1].Key != "id" {
+		t.Fatalf("Fields.Set 结果不正确！%v", fields)
+	}
+
+	cloned := fields.Clone()
+	cloned.Set("id", 2)
+	cloned.Set("extra", true)
+	if fields[1].Value != 1 || len(fields) != 2 {
+		t.Fatalf("修改克隆的 Fields 影响了原来的 Fields！%v", fields)
+	}
+
+	if cloned[1].Value != 2 || len(cloned) != 3 {
+		t.Fatalf("克隆的 Fields 结果不正确！%v", cloned)
+	}
+}
+
+// 测试携带 Fields 输出日志
+func TestLoggerInfoWith(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger = logger.WithFields(map[string]interface{}{"service": "order", "env": "test"})
+
+	fields := Fields{{Key: "service", Value: "pay"}}
+	for i := 0; i < 3; i++ {
+		fields.Set("i", i)
+		logger.InfoWith(fields, "with")
+	}
+	logger.Info("without")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("日志条数不正确！%d", len(lines))
+	}
+
+	for i, line := range lines[:3] {
+		if !strings.HasSuffix(line, "with env=test service=pay i="+strconv.Itoa(i)) {
+			t.Fatalf("第 %d 条日志的字段不正确！%s", i+1, line)
+		}
+	}
+
+	if !strings.HasSuffix(lines[3], "without env=test service=order") {
+		t.Fatalf("Fields 不应该影响后续的日志！%s", lines[3])
+	}
+}
+
+// 测试延迟生成的字段只在日志真正输出时才生成
+func TestLoggerLazyField(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(InfoLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+
+	called := 0
+	lazyLogger := logger.LazyField("dump", func() interface{} {
+		called++
+		return "expensive"
+	})
+
+	lazyLogger.Debug("debug")
+	if called != 0 || buffer.Len() != 0 {
+		t.Fatalf("日志没有输出，但是字段被生成了！%d", called)
+	}
+
+	lazyLogger.Info("info")
+	if called != 1 || !strings.HasSuffix(strings.TrimSpace(buffer.String()), "info dump=expensive") {
+		t.Fatalf("日志输出了，但是字段不正确！%d %s", called, buffer.String())
+	}
+
+	buffer.Reset()
+	logger.Info("parent")
+	if called != 1 || strings.Contains(buffer.String(), "dump") {
+		t.Fatalf("延迟字段不应该影响父日志记录器！%s", buffer.String())
+	}
+}
+
+// 测试使用 Fields 输出日志的性能
+func BenchmarkLoggerInfoWith(b *testing.B) {
+	logger := NewLogger(DebugLevel, NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat))
+	fields := Fields{{Key: "service", Value: "order"}, {Key: "env", Value: "test"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fields.Set("i", i)
+		logger.InfoWith(fields, "benchmark")
+	}
+}
+
+// 测试使用 map 输出带字段的日志的性能，用于和 Fields 对比
+func BenchmarkLoggerWithFieldsMap(b *testing.B) {
+	logger := NewLogger(DebugLevel, NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(map[string]interface{}{"service": "order", "env": "test", "i": i}).Info("benchmark")
+	}
+}
+
+// 测试使用 map 携带只属于一条日志的字段
+func TestLoggerInfoWithFields(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger = logger.WithFields(map[string]interface{}{"service": "order"})
+
+	logger.DebugWithFields(map[string]interface{}{"id": 1, "env": "test"}, "debug")
+	logger.InfoWithFields(map[string]interface{}{"service": "pay"}, "info")
+	logger.WarnWithFields(nil, "warn")
+	logger.ErrorWithFields(map[string]interface{}{"id": 2}, "error")
+	logger.Info("plain")
+
+	expects := []string{
+		"debug service=order env=test id=1",
+		"info service=pay",
+		"warn service=order",
+		"error service=order id=2",
+		"plain service=order",
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != len(expects) {
+		t.Fatalf("日志条数不正确！%d", len(lines))
+	}
+
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expects[i]) {
+			t.Fatalf("第 %d 条日志的字段不正确！%s", i+1, line)
+		}
+	}
+}
+
+// 测试字段合并的覆盖模式和追加模式
+func TestLoggerSetFieldMergeMode(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	parent := logger.WithFields(map[string]interface{}{"tag": "parent"})
+
+	// 默认是覆盖模式
+	parent.WithFields(map[string]interface{}{"tag": "child"}).Info("override")
+
+	parent.SetFieldMergeMode(AppendMode)
+	child := parent.WithFields(map[string]interface{}{"tag": "child"})
+	child.Info("append")
+	child.InfoWith(Fields{{Key: "tag", Value: "log"}}, "append")
+
+	// 在追加模式下，合并的值也需要被脱敏
+	child.AddScrubber(regexp.MustCompile("chi.d"), "***")
+	child.Info("scrub")
+
+	expects := []string{
+		"override tag=child",
+		"append tag=[parent child]",
+		"append tag=[parent child log]",
+		"scrub tag=[parent ***]",
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != len(expects) {
+		t.Fatalf("日志条数不正确！%d", len(lines))
+	}
+
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expects[i]) {
+			t.Fatalf("第 %d 条日志的字段不正确！%s", i+1, line)
+		}
+	}
+
+	// 追加模式下的多次合并不能影响共享的字段
+	child.InfoWith(Fields{{Key: "tag", Value: "again"}}, "again")
+	if !strings.HasSuffix(strings.TrimSpace(buffer.String()), "again tag=[parent *** again]") {
+		t.Fatalf("追加模式影响了共享的字段！%s", buffer.String())
+	}
+}
+
+// 测试每条日志都携带版本号和提交号
+func TestLoggerSetVersion(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	logger.SetVersion("v0.2.9", "d19fa3f")
+	logger.Info("version")
+
+	child := logger.WithFields(map[string]interface{}{"service": "order"})
+	logger.SetVersion("v0.3.0", "5a5c0b7")
+	logger.Info("new version")
+	child.Info("child")
+
+	expects := [][2]string{{"v0.2.9", "d19fa3f"}, {"v0.3.0", "5a5c0b7"}, {"v0.2.9", "d19fa3f"}}
+	if len(handler.logs) != len(expects) {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	for i, m := range handler.logs {
+		if m[VersionKey] != expects[i][0] || m[CommitKey] != expects[i][1] {
+			t.Fatalf("第 %d 条日志的版本号不正确！%v", i+1, m)
+		}
+	}
+}
+
+// 测试字段数量超过限制之后被截断
+func TestLoggerSetMaxFields(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler).WithFields(map[string]interface{}{"service": "order"})
+	logger.SetMaxFields(3)
+
+	fields := Fields{}
+	for i := 0; i < 10; i++ {
+		fields = append(fields, Field{Key: "key" + strconv.Itoa(i), Value: i})
+	}
+
+	logger.InfoWith(fields, "too many fields")
+	logger.InfoWith(fields[:2], "just enough")
+
+	// 截断之后保留前面的字段，并且加上截断的标记
+	m := handler.logs[0]
+	if len(m) != 3+3+1 || m["service"] != "order" || m["key0"] != 0 || m["key1"] != 1 || m[FieldsTruncatedKey] != true {
+		t.Fatalf("截断之后的字段不正确！%v", m)
+	}
+
+	m = handler.logs[1]
+	if len(m) != 3+3 || m[FieldsTruncatedKey] != nil {
+		t.Fatalf("没有超过限制的字段被截断了！%v", m)
+	}
+
+	// 0 表示不限制
+	logger.SetMaxFields(0)
+	logger.InfoWith(fields, "unlimited")
+	if m = handler.logs[2]; len(m) != 3+11 {
+		t.Fatalf("不限制的字段数量不正确！%v", m)
+	}
+}
+
+// 测试截断过长的字段值
+func TestLoggerSetMaxFieldValueLength(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler).WithFields(map[string]interface{}{"stack": strings.Repeat("frame\n", 10)})
+	logger.SetMaxFieldValueLength(8)
+
+	logger.InfoWith(Fields{{Key: "short", Value: "12345678"}, {Key: "name", Value: "日志记录器"}, {Key: "count", Value: 123456789}}, "truncate")
+
+	m := handler.logs[0]
+	expects := map[string]interface{}{
+		"stack": "frame\nfr" + TruncatedValueSuffix,
+		"short": "12345678",
+		"name":  "日志" + TruncatedValueSuffix, // 不会截断半个中文字符
+		"count": 123456789,
+	}
+
+	for key, value := range expects {
+		if m[key] != value {
+			t.Fatalf("字段 %s 的值不正确！%q", key, m[key])
+		}
+	}
+
+	// 0 表示不限制
+	logger.SetMaxFieldValueLength(0)
+	logger.Info("unlimited")
+	if m = handler.logs[1]; m["stack"] != strings.Repeat("frame\n", 10) {
+		t.Fatalf("不限制的字段值被截断了！%q", m["stack"])
+	}
+}
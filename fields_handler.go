@@ -0,0 +1,108 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:26:40
+
+package logit
+
+// fieldsHandler is a handler adding static fields to logs before handling them.
+// It's useful for tagging logs of some handlers only, such as the environment of logs sent to
+// a shared collector, while other handlers write logs without them.
+type fieldsHandler struct {
+
+	// fields is the static fields added to logs, which are sorted by key.
+	fields []Field
+
+	// handlers is all handlers used to handle logs with fields.
+	// See logit.Handler.
+	handlers []Handler
+}
+
+// NewFieldsHandler returns a handler adding fields to logs before handling them with handlers.
+// The fields are static fields like Logger.WithFields, so the fields of a log with the same key
+// will override them. The logs handled by other handlers in logger won't be changed.
+// The fields are processed by the settings of the logger of log like other fields, which are
+// Logger.RedactFields, Logger.AddScrubber, Logger.SetMaxFields and Logger.SetMaxFieldValueLength.
+//
+// For config:
+//     You don't need to register it, and just add a "fields" param to any handler in config:
+//
+//         "handlers": {
+//             "console": {
+//                 "fields": {
+//                     "service": "order",
+//                     "env": "prod"
+//                 }
+//             }
+//         }
+//
+func NewFieldsHandler(fields map[string]interface{}, handlers ...Handler) Handler {
+	return &fieldsHandler{
+		fields:   fieldsOf(fields),
+		handlers: handlers,
+	}
+}
+
+// Handle handles a log with fields added by handlers in fh.
+// Notice that the handling process will be interrupted if one of them
+// returned false. However, this method will always return true, so the handlers
+// after it will always be used.
+func (fh *fieldsHandler) Handle(log *Log) bool {
+
+	// log 会被后面的日志处理器继续使用，所以处理完之后要恢复原来的字段
+	fields := log.fields
+	staticFields, maxFields := fh.processedFields(log)
+	log.fields = truncateFields(withStaticFields(staticFields, fields, OverrideMode), maxFields)
+	defer func() {
+		log.fields = fields
+	}()
+
+	for _, handler := range fh.handlers {
+		if !handler.Handle(log) {
+			break
+		}
+	}
+	return true
+}
+
+// processedFields returns the fields of fh processed by the settings of the logger of log,
+// and the max count of fields of log. The fields of log have been processed by logger, so
+// only the fields of fh will be redacted, scrubbed and truncated here.
+func (fh *fieldsHandler) processedFields(log *Log) ([]Field, int) {
+	if log.logger == nil {
+		return fh.fields, 0
+	}
+
+	redactedKeys, scrubbers, maxFields, maxFieldValueLength := log.logger.fieldPolicies()
+	fields := scrubFields(redactFields(fh.fields, redactedKeys), scrubbers)
+	return truncateFieldValues(fields, maxFieldValueLength), maxFields
+}
+
+// Flush flushes all handlers inside which are Flushers.
+func (fh *fieldsHandler) Flush() error {
+	_, err := flushHandlers(fh.handlers)
+	return err
+}
+
+// FlushWithResult flushes all handlers inside which are Flushers, and returns the sum of results.
+func (fh *fieldsHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers(fh.handlers)
+}
+
+// Close closes all handlers inside which are io.Closers.
+func (fh *fieldsHandler) Close() error {
+	return closeHandlers(fh.handlers)
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 22:31:05
+
+package logit
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试日志处理器添加的静态字段也会被脱敏和截断
+func TestFieldsHandlerProcessesFields(t *testing.T) {
+	handler := &mapHandler{}
+	fields := map[string]interface{}{
+		"token":   "secret",
+		"comment": strings.Repeat("a", 16),
+		"env":     "prod",
+	}
+
+	logger := NewLogger(DebugLevel, NewFieldsHandler(fields, handler))
+	logger.RedactFields("token")
+	logger.SetMaxFieldValueLength(8)
+	logger.Info("processed")
+
+	log := handler.logs[0]
+	if log["token"] != RedactedValue || log["comment"] != strings.Repeat("a", 8)+TruncatedValueSuffix || log["env"] != "prod" {
+		t.Fatalf("静态字段没有被脱敏或者截断！%v", log)
+	}
+
+	// 静态字段也计算在字段的数量里
+	logger.SetMaxFields(2)
+	logger.WithFields(map[string]interface{}{"id": 1}).Info("truncated")
+
+	log = handler.logs[1]
+	if len(log) != 3+2+1 || log[FieldsTruncatedKey] != true {
+		t.Fatalf("静态字段没有被截断！%v", log)
+	}
+
+	// 没有记录器的日志不做处理
+	NewFieldsHandler(fields, handler).Handle(&Log{msg: "raw"})
+	if log = handler.logs[2]; log["token"] != "secret" {
+		t.Fatalf("没有记录器的日志不应该被处理！%v", log)
+	}
+}
@@ -35,6 +35,13 @@ Package files provides some writers to extend your logger.
 		return filepath.Join(directory, now.Format("2006-01-02-15-04-05.log"))
 	})
 
+	// Only the time layout and the extension need to change? Try this:
+	durationRollingFile.SetNameGenerator(files.TimeFormatNameGenerator("20060102.150405", ".log"))
+
+	// If the file system may hang, such as a network file system, try this:
+	// A WriteTimeoutError will be returned if one writing doesn't finish in one second.
+	durationRollingFile.SetWriteTimeout(time.Second)
+
 2. SizeRollingFile:
 
 	// SizeRollingFile is a file size sensitive file.
@@ -51,5 +58,45 @@ Package files provides some writers to extend your logger.
 		return filepath.Join(directory, now.Format("2006-01-02-15-04-05.log"))
 	})
 
+3. PlainFile:
+
+	// PlainFile is a file which never rolls.
+	plainFile, err := files.NewPlainFile("D:/logit.log")
+	if err != nil {
+		panic(err)
+	}
+	defer plainFile.Close()
+
+	// If the file may be removed or rotated by others, try this:
+	plainFile.SetReopenOnInodeChange(true)
+	plainFile.Write([]byte("plainFile!"))
+
+4. GzipWriter:
+
+	// GzipWriter compresses data to a gzip stream continuously.
+	gzipWriter := files.NewGzipWriter(plainFile)
+	defer gzipWriter.Close()
+	gzipWriter.Write([]byte("gzipWriter!"))
+
+	// If you want rolling files to be compressed, try this:
+	// The gzip stream will be closed properly when rolling, so every file is a valid archive.
+	durationRollingFile.SetGzip(true)
+
+5. ScheduledRollingFile:
+
+	// ScheduledRollingFile rolls at the instants of a schedule, such as every day at 00:00 and 12:00.
+	// Try files.Hourly() if you want to roll at the start of every hour.
+	schedule, err := files.DailyAt("00:00", "12:00")
+	if err != nil {
+		panic(err)
+	}
+
+	scheduledRollingFile, err := files.NewScheduledRollingFile("D:/", schedule)
+	if err != nil {
+		panic(err)
+	}
+	defer scheduledRollingFile.Close()
+	scheduledRollingFile.Write([]byte("scheduledRollingFile!"))
+
 */
 package files // import "github.com/FishGoddess/logit/files"
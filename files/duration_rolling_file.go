@@ -20,6 +20,7 @@ package files
 
 import (
 	"errors"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -56,6 +57,30 @@ type DurationRollingFile struct {
 	// Default is DefaultNameGenerator().
 	nameGenerator NameGenerator
 
+	// writeTimeout is the max duration of one writing, and <= 0 means no timeout.
+	// See SetWriteTimeout.
+	writeTimeout time.Duration
+
+	// timeoutWriting writes with writeTimeout and allows at most one writing in flight.
+	timeoutWriting timeoutWriting
+
+	// writeBOM is a flag to check if a UTF-8 BOM should be written at the start of new file.
+	// See SetWriteBOM.
+	writeBOM bool
+
+	// gzip is a flag to check if new files should be compressed in gzip. See SetGzip.
+	gzip bool
+
+	// gzipWriter compresses data to file if the file is compressed, or it's nil.
+	gzipWriter *GzipWriter
+
+	// preallocator creates the next file in advance if it's enabled. See SetPreallocateNext.
+	preallocator preallocator
+
+	// closed is a flag to check if this file has been closed.
+	// Close is idempotent, and writing to a closed file returns os.ErrClosed.
+	closed bool
+
 	// mu is a lock for safe concurrency.
 	mu *sync.Mutex
 }
@@ -72,11 +97,26 @@ const (
 // Every times rolling to next file will call nextFilename first.
 // now is the created time of next file. Notice that duration's min value
 // is one second. See minDuration.
+// It panics if failed, and see NewDurationRollingFileE if you want an error instead.
 func NewDurationRollingFile(directory string, duration time.Duration) *DurationRollingFile {
+	file, err := NewDurationRollingFileE(directory, duration)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// NewDurationRollingFileE is the same as NewDurationRollingFile except it returns an error instead of
+// panicking if duration is too small or directory isn't an existing directory.
+func NewDurationRollingFileE(directory string, duration time.Duration) (*DurationRollingFile, error) {
 
 	// 防止时间间隔太小导致滚动文件时 IO 的疯狂蠕动
 	if duration < minDuration {
-		panic(errors.New("Duration is smaller than " + minDuration.String() + "\n"))
+		return nil, errors.New("Duration is smaller than " + minDuration.String() + "\n")
+	}
+
+	if err := checkDirectory(directory); err != nil {
+		return nil, err
 	}
 
 	return &DurationRollingFile{
@@ -84,22 +124,51 @@ func NewDurationRollingFile(directory string, duration time.Duration) *DurationR
 		duration:      duration,
 		nameGenerator: DefaultNameGenerator(),
 		mu:            &sync.Mutex{},
-	}
+	}, nil
 }
 
 // rollingToNextFile will roll to next file for drf.
 func (drf *DurationRollingFile) rollingToNextFile(now time.Time) {
 
+	// 优先使用提前创建好的文件，没有的话再同步创建
 	// 如果创建新文件发生错误，就继续使用当前的文件，等到下一次时间间隔再重试
-	newFile, err := CreateFileOf(drf.nameGenerator.NextName(drf.directory, now))
-	if err != nil {
-		return
+	newFile := drf.preallocator.take()
+	if newFile == nil {
+		var err error
+		path := drf.nameGenerator.NextName(drf.directory, now)
+		newFile, err = CreateFileOf(path)
+		if err != nil {
+			return
+		}
+		drf.preallocator.use(path)
 	}
 
 	// 关闭当前使用的文件，初始化新文件
-	drf.file.Close()
-	drf.file = newFile
+	drf.closeFile()
+	drf.file, drf.gzipWriter = newFile, nil
+	if drf.gzip {
+		drf.gzipWriter = NewGzipWriter(newFile)
+	}
+
+	if drf.writeBOM {
+		writeBOMIfEmpty(newFile, drf.writer())
+	}
 	drf.lastTime = now
+	drf.preallocateNextFile()
+}
+
+// preallocateNextFile creates the next file in background if it's enabled.
+// The name is generated in the time of next rolling, which is the same as rolling without it.
+func (drf *DurationRollingFile) preallocateNextFile() {
+	next := time.Now()
+	if drf.file != nil {
+		next = drf.lastTime.Add(drf.duration)
+	}
+
+	directory, nameGenerator := drf.directory, drf.nameGenerator
+	drf.preallocator.start(drf.mu, func() string {
+		return nameGenerator.NextName(directory, next)
+	})
 }
 
 // ensureFileIsCorrect ensures drf is writing to a correct file this moment.
@@ -110,29 +179,134 @@ func (drf *DurationRollingFile) ensureFileIsCorrect() {
 	}
 }
 
+// writer returns the writer which data should be written to.
+// It's the gzip writer if current file is compressed, or the file itself.
+func (drf *DurationRollingFile) writer() io.Writer {
+	if drf.gzipWriter != nil {
+		return drf.gzipWriter
+	}
+	return drf.file
+}
+
+// closeFile closes current file, and the gzip stream will be closed first if it's compressed.
+func (drf *DurationRollingFile) closeFile() error {
+
+	// 文件可能还没有创建过，比如还没有写入过数据的滚动文件
+	if drf.file == nil {
+		return nil
+	}
+
+	// 压缩流关闭时会写入尾部信息，并且会关闭文件，这样滚动出来的每个文件都是完整的压缩文件
+	if drf.gzipWriter != nil {
+		return drf.gzipWriter.Close()
+	}
+	return drf.file.Close()
+}
+
 // Write writes len(p) bytes from p to the underlying data stream.
 // It returns the number of bytes written from p (0 <= n <= len(p))
 // and any error encountered that caused the write to stop early.
+// Return os.ErrClosed if the file has been closed.
 func (drf *DurationRollingFile) Write(p []byte) (n int, err error) {
 	drf.mu.Lock()
 	defer drf.mu.Unlock()
 
+	if drf.closed {
+		return 0, os.ErrClosed
+	}
+
 	// 确保当前文件对于当前时间点来说是正确的
 	drf.ensureFileIsCorrect()
-	return drf.file.Write(p)
+	return drf.timeoutWriting.write(drf.writer(), p, drf.writeTimeout, nil)
 }
 
 // Close releases any resources using just moment.
-// It returns error when closing.
+// It returns error when closing. It's safe to call it more than once, and the
+// calls after the first one will do nothing and return nil.
 func (drf *DurationRollingFile) Close() error {
 	drf.mu.Lock()
 	defer drf.mu.Unlock()
-	return drf.file.Close()
+
+	if drf.closed {
+		return nil
+	}
+	drf.closed = true
+
+	// 提前创建的文件还没有被使用过，直接删除，正在创建的文件会在创建完之后删除
+	drf.preallocator.enabled = false
+	drf.preallocator.discard()
+	return drf.closeFile()
 }
 
 // SetNameGenerator replaces drf.nameGenerator to newNameGenerator.
+// The next file created in advance will be discarded, so the next name is from newNameGenerator.
 func (drf *DurationRollingFile) SetNameGenerator(newNameGenerator NameGenerator) {
 	drf.mu.Lock()
 	defer drf.mu.Unlock()
 	drf.nameGenerator = newNameGenerator
+	drf.preallocator.discard()
+	drf.preallocateNextFile()
+}
+
+// SetWriteTimeout sets the max duration of one writing to timeout, and <= 0 means no timeout.
+// If a writing doesn't finish in timeout, WriteTimeoutError will be returned instead of blocking
+// forever, which is useful on network file systems. Notice that it adds a goroutine per writing
+// when enabled, and the timeout writing will keep going in background.
+// Only one writing is allowed in flight, so writings before the timeout one finishes will fail
+// with WritePendingError immediately, and no goroutine piles up on a hung file system.
+func (drf *DurationRollingFile) SetWriteTimeout(timeout time.Duration) {
+	drf.mu.Lock()
+	defer drf.mu.Unlock()
+	drf.writeTimeout = timeout
+}
+
+// SetWriteBOM sets if a UTF-8 BOM should be written at the start of every new file when rolling,
+// which helps some Windows tools recognize the encoding. The BOM won't be written to a file which
+// isn't empty, such as an existing file with the same name.
+func (drf *DurationRollingFile) SetWriteBOM(writeBOM bool) {
+	drf.mu.Lock()
+	defer drf.mu.Unlock()
+	drf.writeBOM = writeBOM
+}
+
+// SetGzip sets if new files should be compressed in gzip continuously, so the files on disk are
+// always compressed. The gzip stream will be closed properly when rolling, so every file rolled is
+// a valid archive. It takes effect from the next file, and you may want to change the name generator
+// to use a ".gz" suffix.
+func (drf *DurationRollingFile) SetGzip(gzip bool) {
+	drf.mu.Lock()
+	defer drf.mu.Unlock()
+	drf.gzip = gzip
+}
+
+// Flush flushes the gzip stream of current file if it's compressed.
+func (drf *DurationRollingFile) Flush() error {
+	drf.mu.Lock()
+	defer drf.mu.Unlock()
+
+	if drf.closed || drf.gzipWriter == nil {
+		return nil
+	}
+	return drf.gzipWriter.Flush()
+}
+
+// SetPreallocateNext sets if the next file should be created in advance in background, so rolling
+// to next file is instantaneous without creating a file on the write path. The time in name is the
+// time of next rolling, so the name is the same as the one created when rolling. The next file is
+// created with a temporary name and renamed when rolling, so an existing file with the same name won't
+// be touched. The next file not used will be removed when closing.
+func (drf *DurationRollingFile) SetPreallocateNext(preallocate bool) {
+	drf.mu.Lock()
+	defer drf.mu.Unlock()
+
+	if drf.closed {
+		return
+	}
+
+	drf.preallocator.enabled = preallocate
+	if !preallocate {
+		drf.preallocator.discard()
+		return
+	}
+	drf.preallocateNextFile()
 }
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -91,3 +92,137 @@ func TestDurationRollingFileSetNameGenerator(t *testing.T) {
 	time.Sleep(2 * time.Second)
 	file.Write([]byte("hi!"))
 }
+
+// 测试多次关闭时间间隔滚动文件
+func TestDurationRollingFileClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDurationRollingFileClose_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 还没有写入过数据，文件还没有创建
+	file := NewDurationRollingFile(dir, time.Second)
+	if err := file.Close(); err != nil {
+		t.Fatalf("关闭没有创建过的文件出现错误！%v", err)
+	}
+
+	file = NewDurationRollingFile(dir, time.Second)
+	file.Write([]byte("hello!"))
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatalf("第二次关闭文件出现错误！%v", err)
+	}
+
+	if _, err := file.Write([]byte("closed!")); err != os.ErrClosed {
+		t.Fatalf("写入已经关闭的文件没有返回 os.ErrClosed！%v", err)
+	}
+}
+
+// 测试时间间隔滚动文件以 BOM 开头
+func TestDurationRollingFileSetWriteBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDurationRollingFileSetWriteBOM_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := NewDurationRollingFile(dir, time.Second)
+	file.SetWriteBOM(true)
+	file.Write([]byte("hello!"))
+	file.Close()
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil || len(fileInfos) != 1 {
+		t.Fatalf("文件创建出现问题！%v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, fileInfos[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != string(utf8BOM)+"hello!" {
+		t.Fatalf("文件没有以 BOM 开头！%q", content)
+	}
+}
+
+// 测试创建文件失败时返回错误而不是 panic
+func TestNewDurationRollingFileE(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewDurationRollingFileE_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewDurationRollingFileE(dir, time.Millisecond); err == nil {
+		t.Fatal("限制值太小没有返回错误！")
+	}
+
+	// 不存在的目录和普通文件都不能作为目录
+	if _, err := NewDurationRollingFileE(filepath.Join(dir, "not_existed"), time.Second); err == nil {
+		t.Fatal("不存在的目录没有返回错误！")
+	}
+
+	path := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(path, nil, 0664); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDurationRollingFileE(path, time.Second); err == nil {
+		t.Fatal("普通文件作为目录没有返回错误！")
+	}
+
+	file, err := NewDurationRollingFileE(dir, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+}
+
+// 测试提前创建下一个文件，文件名使用下一次滚动的时间
+func TestDurationRollingFileSetPreallocateNext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDurationRollingFileSetPreallocateNext_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	times := make(chan time.Time, 16)
+	count := 0
+	file := NewDurationRollingFile(dir, time.Hour)
+	file.SetNameGenerator(func(directory string, now time.Time) string {
+		times <- now
+		count++
+		return filepath.Join(directory, strconv.Itoa(count)+SuffixOfLogFile)
+	})
+
+	file.SetPreallocateNext(true)
+	waitForPreallocated(t, file.mu, &file.preallocator)
+	<-times
+
+	file.Write([]byte("hello"))
+	waitForPreallocated(t, file.mu, &file.preallocator)
+
+	file.mu.Lock()
+	lastTime := file.lastTime
+	file.mu.Unlock()
+
+	if next := <-times; !next.Equal(lastTime.Add(time.Hour)) {
+		t.Fatalf("提前创建的文件名使用的时间不正确！%v %v", next, lastTime)
+	}
+
+	// 关闭提前创建的特性之后文件需要被删除
+	file.SetPreallocateNext(false)
+	if _, err := os.Stat(filepath.Join(dir, "2"+SuffixOfLogFile)); !os.IsNotExist(err) {
+		t.Fatalf("关闭提前创建之后没有删除文件！%v", err)
+	}
+
+	if len(times) != 0 {
+		t.Fatal("写入的时候创建了文件！")
+	}
+	file.Close()
+}
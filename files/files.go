@@ -19,6 +19,8 @@
 package files
 
 import (
+	"errors"
+	"io"
 	"os"
 )
 
@@ -36,6 +38,40 @@ const (
 	SuffixOfLogFile = ".log"
 )
 
+var (
+	// utf8BOM is the byte order mark of UTF-8, which helps some Windows tools recognize the encoding.
+	utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// writeBOMIfEmpty writes a UTF-8 BOM to writer if file is empty.
+// The writer is file itself or a writer wrapping file, such as GzipWriter.
+// Return the count of bytes written, which is 0 if file isn't empty.
+func writeBOMIfEmpty(file *os.File, writer io.Writer) (int, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() > 0 {
+		return 0, err
+	}
+	return writer.Write(utf8BOM)
+}
+
+// checkDirectory returns an error if directory isn't an existing directory.
+// An empty directory means the current directory, so it's always fine.
+func checkDirectory(directory string) error {
+	if directory == "" {
+		return nil
+	}
+
+	info, err := os.Stat(directory)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.New(directory + " is not a directory")
+	}
+	return nil
+}
+
 // CreateFileOf creates a new file with given filePath.
 // Return a new File or an error if failed.
 // Notice that the permission of new file is 0644, which means rw-rw-r-- in unix-like os.
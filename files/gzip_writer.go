@@ -0,0 +1,163 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 19:35:21
+
+package files
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultGzipFlushInterval is the default interval of flushing the gzip stream.
+	defaultGzipFlushInterval = time.Second
+)
+
+// GzipWriter is a writer compressing data to a gzip stream continuously, so the file on disk
+// is always compressed. The gzip stream will be flushed periodically when writing, so the recent
+// data is recoverable even if the stream isn't closed properly.
+//
+//  file, err := NewPlainFile("D:/logit.log.gz")
+//  if err != nil {
+//      panic(err)
+//  }
+//  writer := NewGzipWriter(file)
+//  defer writer.Close()
+//  writer.Write([]byte("Hello!"))
+//
+// Remember to close it, or the gzip stream won't be a valid archive.
+type GzipWriter struct {
+
+	// writer is the writer which compressed data will be written to.
+	writer io.WriteCloser
+
+	// gzipWriter compresses data to writer.
+	gzipWriter *gzip.Writer
+
+	// flushInterval is the interval of flushing the gzip stream.
+	flushInterval time.Duration
+
+	// lastFlushTime is the time of last flushing.
+	lastFlushTime time.Time
+
+	// flushWatermark is the count of unflushed bytes triggering a flush, and 0 means disabled.
+	flushWatermark int
+
+	// unflushed is the count of uncompressed bytes written since last flushing.
+	unflushed int
+
+	// closed is a flag to check if this writer has been closed.
+	closed bool
+
+	// mu is a lock for safe concurrency.
+	mu *sync.Mutex
+}
+
+// NewGzipWriter returns a writer compressing data to writer in gzip.
+// The gzip stream will be flushed every second by default. See SetFlushInterval.
+func NewGzipWriter(writer io.WriteCloser) *GzipWriter {
+	return &GzipWriter{
+		writer:        writer,
+		gzipWriter:    gzip.NewWriter(writer),
+		flushInterval: defaultGzipFlushInterval,
+		lastFlushTime: time.Now(),
+		mu:            &sync.Mutex{},
+	}
+}
+
+// Write compresses p to the gzip stream, and flushes the stream if flush interval elapsed
+// or the unflushed bytes reach the flush watermark.
+// Notice that the count of bytes returned is the count of uncompressed bytes from p.
+// Return os.ErrClosed if the writer has been closed.
+func (gw *GzipWriter) Write(p []byte) (n int, err error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed {
+		return 0, os.ErrClosed
+	}
+
+	n, err = gw.gzipWriter.Write(p)
+	gw.unflushed += n
+	if err != nil {
+		return n, err
+	}
+
+	// 定期刷新压缩流，这样即使程序崩溃，最近的数据也是可以恢复的
+	// 缓冲的数据超过水位线的时候也立即刷新，不用等到下一次定时刷新
+	now := time.Now()
+	if now.Sub(gw.lastFlushTime) >= gw.flushInterval || (gw.flushWatermark > 0 && gw.unflushed >= gw.flushWatermark) {
+		gw.lastFlushTime = now
+		gw.unflushed = 0
+		err = gw.gzipWriter.Flush()
+	}
+	return n, err
+}
+
+// Flush flushes the compressed data to the underlying writer.
+func (gw *GzipWriter) Flush() error {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed {
+		return nil
+	}
+
+	gw.lastFlushTime = time.Now()
+	gw.unflushed = 0
+	return gw.gzipWriter.Flush()
+}
+
+// Close closes the gzip stream and the underlying writer.
+// It's safe to call it more than once, and the calls after the first one will do nothing.
+func (gw *GzipWriter) Close() error {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed {
+		return nil
+	}
+	gw.closed = true
+
+	// 先关闭压缩流写入尾部信息，再关闭底层的 writer，否则压缩文件是不完整的
+	err := gw.gzipWriter.Close()
+	if closeErr := gw.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// SetFlushInterval sets the interval of flushing the gzip stream when writing.
+// If interval <= 0, the stream will be flushed in every writing, which reduces the compression ratio.
+func (gw *GzipWriter) SetFlushInterval(interval time.Duration) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	gw.flushInterval = interval
+}
+
+// SetFlushWatermark sets the count of unflushed bytes triggering a flush when writing, so the data only
+// in memory is bounded even if the flush interval is long. The bytes are counted before compression.
+// If bytes <= 0, the watermark is disabled and only the flush interval works, which is the default.
+func (gw *GzipWriter) SetFlushWatermark(bytes int) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	gw.flushWatermark = bytes
+}
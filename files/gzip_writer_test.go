@@ -0,0 +1,244 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 20:26:44
+
+package files
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 读取并解压缩文件内容
+func readGzipFile(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("压缩文件不完整！%v", err)
+	}
+	return string(content)
+}
+
+// 测试压缩写入之后解压缩的内容完整
+func TestGzipWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestGzipWriter_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log.gz")
+	file, err := NewPlainFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := NewGzipWriter(file)
+	writer.SetFlushInterval(0)
+
+	expect := bytes.NewBuffer(nil)
+	for i := 0; i < 100; i++ {
+		line := []byte("[info] [2020-08-29 20:26:44] gzip log\n")
+		expect.Write(line)
+		if n, err := writer.Write(line); err != nil || n != len(line) {
+			t.Fatalf("写入压缩流出现错误！%d %v", n, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("第二次关闭出现错误！%v", err)
+	}
+
+	if content := readGzipFile(t, path); content != expect.String() {
+		t.Fatalf("解压缩的内容不正确！%s", content)
+	}
+}
+
+// nopCloseBuffer is a buffer implementing io.WriteCloser.
+type nopCloseBuffer struct {
+	bytes.Buffer
+}
+
+func (ncb *nopCloseBuffer) Close() error {
+	return nil
+}
+
+// 读取没有关闭的压缩流中已经刷新的内容
+func readFlushedGzip(t *testing.T, data []byte) string {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+
+	// 压缩流还没有结束，读到结尾的时候会返回 io.ErrUnexpectedEOF，忽略它
+	content, _ := ioutil.ReadAll(reader)
+	return string(content)
+}
+
+// 测试缓冲的数据超过水位线的时候立即刷新
+func TestGzipWriterSetFlushWatermark(t *testing.T) {
+	buffer := &nopCloseBuffer{}
+	writer := NewGzipWriter(buffer)
+	writer.SetFlushInterval(time.Hour)
+	writer.SetFlushWatermark(100)
+
+	line := bytes.Repeat([]byte("a"), 60)
+	writer.Write(line)
+	if content := readFlushedGzip(t, buffer.Bytes()); content != "" {
+		t.Fatalf("没有超过水位线就刷新了！%d", len(content))
+	}
+
+	writer.Write(line)
+	if content := readFlushedGzip(t, buffer.Bytes()); len(content) != 120 {
+		t.Fatalf("超过水位线之后没有立即刷新！%d", len(content))
+	}
+
+	// 刷新之后重新计算缓冲的数据
+	writer.Write(line)
+	if content := readFlushedGzip(t, buffer.Bytes()); len(content) != 120 {
+		t.Fatalf("刷新之后没有重新计算缓冲的数据！%d", len(content))
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if content := readFlushedGzip(t, buffer.Bytes()); len(content) != 180 {
+		t.Fatalf("关闭之后的内容不正确！%d", len(content))
+	}
+}
+
+// 测试时间间隔滚动文件在滚动时关闭压缩流
+func TestDurationRollingFileSetGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDurationRollingFileSetGzip_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := NewDurationRollingFile(dir, time.Second)
+	file.SetGzip(true)
+	file.SetWriteBOM(true)
+	file.Write([]byte("before rolling\n"))
+
+	time.Sleep(time.Second)
+	file.Write([]byte("after rolling\n"))
+	file.Close()
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fileInfos) != 2 {
+		t.Fatalf("文件滚动出现问题！%d", len(fileInfos))
+	}
+
+	contents := map[string]bool{}
+	for _, fileInfo := range fileInfos {
+		contents[readGzipFile(t, filepath.Join(dir, fileInfo.Name()))] = true
+	}
+
+	if !contents[string(utf8BOM)+"before rolling\n"] || !contents[string(utf8BOM)+"after rolling\n"] {
+		t.Fatalf("滚动出来的压缩文件内容不正确！%v", contents)
+	}
+}
+
+// 测试大小滚动文件按照压缩之后写入文件的字节数计算大小
+func TestSizeRollingFileSetGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileSetGzip_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 全是 0 的数据压缩之后非常小，1 MB 的数据也不会超过 64 KB
+	file := NewSizeRollingFile(dir, 64*KB)
+	file.SetGzip(true)
+	for i := 0; i < 1024; i++ {
+		file.Write(make([]byte, KB))
+	}
+	file.Close()
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fileInfos) != 1 {
+		t.Fatalf("文件大小应该按照压缩之后的字节数计算！%d", len(fileInfos))
+	}
+
+	if content := readGzipFile(t, filepath.Join(dir, fileInfos[0].Name())); len(content) != 1024*int(KB) {
+		t.Fatalf("压缩文件的内容不正确！%d", len(content))
+	}
+
+	// 随机的数据几乎不能压缩，需要按照限制的大小滚动
+	randomDir, err := ioutil.TempDir("", "TestSizeRollingFileSetGzip_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(randomDir)
+
+	data := make([]byte, 512*KB)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	file = NewSizeRollingFile(randomDir, 64*KB)
+	file.SetGzip(true)
+	for i := 0; i < len(data); i += int(KB) {
+		file.Write(data[i : i+int(KB)])
+	}
+	file.Close()
+
+	fileInfos, err = ioutil.ReadDir(randomDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fileInfos) < 4 {
+		t.Fatalf("压缩之后超过限制的文件没有滚动！%d", len(fileInfos))
+	}
+
+	total := 0
+	for _, fileInfo := range fileInfos {
+		total += len(readGzipFile(t, filepath.Join(randomDir, fileInfo.Name())))
+	}
+
+	if total != len(data) {
+		t.Fatalf("滚动出来的压缩文件内容不完整！%d", total)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/rand"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -43,10 +44,28 @@ func (ng NameGenerator) NextName(directory string, now time.Time) string {
 
 var (
 	// For DefaultNameGenerator.
+	// Notice that rand.Rand isn't safe for concurrency, so lock defaultNameGeneratorMutex before using it.
 	defaultNameGeneratorRandom  = rand.New(rand.NewSource(time.Now().Unix()))
+	defaultNameGeneratorMutex   = &sync.Mutex{}
 	defaultNameGeneratorCounter = int64(0)
 )
 
+// SetRandSource replaces the source of random numbers used by DefaultNameGenerator with source.
+// Default is a source seeded by the time of starting, so pass a source with a fixed seed if you
+// want the names to be reproducible in tests.
+func SetRandSource(source rand.Source) {
+	defaultNameGeneratorMutex.Lock()
+	defer defaultNameGeneratorMutex.Unlock()
+	defaultNameGeneratorRandom = rand.New(source)
+}
+
+// nextRandomInt returns a random int from defaultNameGeneratorRandom safely.
+func nextRandomInt() int {
+	defaultNameGeneratorMutex.Lock()
+	defer defaultNameGeneratorMutex.Unlock()
+	return defaultNameGeneratorRandom.Int()
+}
+
 // DefaultNameGenerator returns a name generator that creates a time-relative filename
 // with given now time. Also, it uses random number to ensure this filename is available.
 // The filename will be like "20200304-145246-45.log".
@@ -59,7 +78,21 @@ func DefaultNameGenerator() NameGenerator {
 	return func(directory string, now time.Time) string {
 		atomic.CompareAndSwapInt64(&defaultNameGeneratorCounter, math.MaxInt64-128, 0)
 		seq := strconv.FormatInt(atomic.AddInt64(&defaultNameGeneratorCounter, int64(1)), 10)
-		name := now.Format("20060102-150405") + "-" + seq + strconv.Itoa(defaultNameGeneratorRandom.Int()) + SuffixOfLogFile
+		name := now.Format("20060102-150405") + "-" + seq + strconv.Itoa(nextRandomInt()) + SuffixOfLogFile
 		return filepath.Join(directory, name)
 	}
 }
+
+// ================================= time format name generator =================================
+
+// TimeFormatNameGenerator returns a name generator that creates a filename formatted from now
+// in layout with ext, such as "20200830.103015.123.log" from layout "20060102.150405.000" and
+// ext ".log". It's useful for matching your archival naming conventions.
+// Notice that the same name will be generated in the same layout unit, and the file will be appended
+// instead of created, so use a fine layout with size rolling files. The directory is the one passed
+// by rolling files, so it isn't a parameter here.
+func TimeFormatNameGenerator(layout string, ext string) NameGenerator {
+	return func(directory string, now time.Time) string {
+		return filepath.Join(directory, now.Format(layout)+ext)
+	}
+}
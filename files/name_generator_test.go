@@ -6,7 +6,11 @@ package files
 
 import (
 	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -65,3 +69,46 @@ func TestDefaultNameGenerator(t *testing.T) {
 
 	group.Wait()
 }
+
+// 测试使用固定种子的随机数源生成可以复现的名字
+func TestSetRandSource(t *testing.T) {
+	defer SetRandSource(rand.NewSource(time.Now().Unix()))
+
+	now := time.Now()
+	nameGenerator := DefaultNameGenerator()
+	nextName := func() string {
+		SetRandSource(rand.NewSource(1))
+		atomic.StoreInt64(&defaultNameGeneratorCounter, 0)
+		return nameGenerator.NextName("", now)
+	}
+
+	name := nextName()
+	for i := 0; i < 10; i++ {
+		if another := nextName(); another != name {
+			t.Fatalf("使用相同的种子生成的名字不一样！%s %s", name, another)
+		}
+	}
+
+	expect := now.Format("20060102-150405") + "-1" + strconv.Itoa(rand.New(rand.NewSource(1)).Int()) + SuffixOfLogFile
+	if name != expect {
+		t.Fatalf("生成的名字不正确！%s %s", name, expect)
+	}
+}
+
+// 测试使用自定义时间格式生成名字
+func TestTimeFormatNameGenerator(t *testing.T) {
+	now := time.Date(2020, 8, 30, 10, 30, 15, 123456789, time.Local)
+	cases := map[[2]string]string{
+		{"20060102.150405.000", ".log"}:  "20200830.103015.123.log",
+		{"2006-01-02_15h04m", ".txt"}:    "2020-08-30_10h30m.txt",
+		{"20060102", ""}:                 "20200830",
+		{"2006/01/02/150405", ".log.gz"}: filepath.Join("2020", "08", "30", "103015.log.gz"),
+	}
+
+	for params, expect := range cases {
+		name := TimeFormatNameGenerator(params[0], params[1]).NextName("logs", now)
+		if name != filepath.Join("logs", expect) {
+			t.Fatalf("使用格式 %s 生成的名字不正确！%s", params[0], name)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/06 20:33:18
+
+package files
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// PlainFile is a file which never rolls.
+//
+//  file, err := NewPlainFile("D:/logit.log")
+//  if err != nil {
+//      panic(err)
+//  }
+//  defer file.Close()
+//  file.Write([]byte("Hello!"))
+//
+// You can use it like using os.File!
+type PlainFile struct {
+
+	// file points the writer which will be used this moment.
+	file *os.File
+
+	// path is the path of this file.
+	path string
+
+	// reopenOnInodeChange is a flag to check if file should be reopened when
+	// path doesn't refer to file anymore. See SetReopenOnInodeChange.
+	reopenOnInodeChange bool
+
+	// checkInterval is the interval of checking if path still refers to file.
+	// Checking needs a system call, so we don't check it in every writing.
+	checkInterval time.Duration
+
+	// lastCheckTime is the time of last checking.
+	lastCheckTime time.Time
+
+	// writeBOM is a flag to check if a UTF-8 BOM should be written at the start of new file.
+	// See SetWriteBOM.
+	writeBOM bool
+
+	// closed is a flag to check if this file has been closed.
+	// Close is idempotent, and writing to a closed file returns os.ErrClosed.
+	closed bool
+
+	// mu is a lock for safe concurrency.
+	mu *sync.Mutex
+}
+
+const (
+	// defaultCheckInterval is the default interval of checking if path still refers to file.
+	defaultCheckInterval = time.Second
+)
+
+// NewPlainFile creates a new plain file with given path.
+// If the file of this path doesn't exist, a new file will be created.
+// Return an error if failed.
+func NewPlainFile(path string) (*PlainFile, error) {
+	file, err := CreateFileOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlainFile{
+		file:          file,
+		path:          path,
+		checkInterval: defaultCheckInterval,
+		lastCheckTime: time.Now(),
+		mu:            &sync.Mutex{},
+	}, nil
+}
+
+// reopenIfInodeChanged reopens pf.file if path doesn't refer to pf.file anymore.
+// This happens when the file is removed or rotated by others, such as logrotate.
+func (pf *PlainFile) reopenIfInodeChanged() {
+	now := time.Now()
+	if now.Sub(pf.lastCheckTime) < pf.checkInterval {
+		return
+	}
+	pf.lastCheckTime = now
+
+	// os.SameFile 会比较 dev 和 ino，两者一样说明 path 指向的还是当前文件
+	currentInfo, err := pf.file.Stat()
+	if err == nil {
+		pathInfo, err := os.Stat(pf.path)
+		if err == nil && os.SameFile(currentInfo, pathInfo) {
+			return
+		}
+	}
+
+	// 如果重新打开文件发生错误，就继续使用当前的文件，等到下一次检查再重试
+	newFile, err := CreateFileOf(pf.path)
+	if err != nil {
+		return
+	}
+
+	if pf.writeBOM {
+		writeBOMIfEmpty(newFile, newFile)
+	}
+
+	pf.file.Close()
+	pf.file = newFile
+}
+
+// Write writes len(p) bytes from p to the underlying data stream.
+// It returns the number of bytes written from p (0 <= n <= len(p))
+// and any error encountered that caused the write to stop early.
+// Return os.ErrClosed if the file has been closed.
+func (pf *PlainFile) Write(p []byte) (n int, err error) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	if pf.closed {
+		return 0, os.ErrClosed
+	}
+
+	if pf.reopenOnInodeChange {
+		pf.reopenIfInodeChanged()
+	}
+	return pf.file.Write(p)
+}
+
+// Close releases any resources using just moment.
+// It returns error when closing. It's safe to call it more than once, and the
+// calls after the first one will do nothing and return nil.
+func (pf *PlainFile) Close() error {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	if pf.closed {
+		return nil
+	}
+	pf.closed = true
+	return pf.file.Close()
+}
+
+// SetReopenOnInodeChange sets if pf should reopen its path when the path doesn't
+// refer to the opened file anymore. This is useful when the file is removed or rotated
+// by others, and logs will keep flowing to the new file without any signals.
+// Notice that it checks the path every second, not every writing.
+func (pf *PlainFile) SetReopenOnInodeChange(reopen bool) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	pf.reopenOnInodeChange = reopen
+}
+
+// SetWriteBOM sets if a UTF-8 BOM should be written at the start of new file, which helps some
+// Windows tools recognize the encoding. The BOM will be written to current file if it's empty, and
+// to the file reopened, too. See SetReopenOnInodeChange.
+func (pf *PlainFile) SetWriteBOM(writeBOM bool) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	// 当前的文件在创建的时候还没有设置，所以需要补写 BOM
+	pf.writeBOM = writeBOM
+	if writeBOM && !pf.closed {
+		writeBOMIfEmpty(pf.file, pf.file)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/06 21:02:49
+
+// +build !windows
+
+package files
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 测试文件被外部删除并重建之后重新打开文件
+func TestPlainFileSetReopenOnInodeChange(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "TestPlainFileSetReopenOnInodeChange_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	file, err := NewPlainFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	file.SetReopenOnInodeChange(true)
+	file.checkInterval = 0
+	file.Write([]byte("before\n"))
+
+	// 模拟外部的日志轮转：删除原文件，并在原路径上创建新文件
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("rotated\n"), 0664); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Write([]byte("after\n"))
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "rotated\nafter\n" {
+		t.Fatalf("文件被重建之后没有写入新文件！%q", content)
+	}
+
+	// 文件被删除但没有重建，也需要重新创建文件
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Write([]byte("recreated\n"))
+	content, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "recreated\n" {
+		t.Fatalf("文件被删除之后没有重新创建文件！%q", content)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 10:16:32
+
+package files
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// preallocator creates the next file of a rolling file in background, so rolling to next file
+// doesn't need to create a file on the write path. Notice that it's not safe for concurrency,
+// so lock the mutex of rolling file before calling its methods.
+//
+// The next file is created with a temporary name exclusively, and it will be linked to the real
+// name when taking it. So an existing file with the real name, such as the file being written,
+// is never opened, overwritten or removed by it.
+type preallocator struct {
+
+	// enabled is a flag to check if the next file should be created in advance.
+	enabled bool
+
+	// running is a flag to check if a goroutine is creating the next file.
+	running bool
+
+	// file is the next file created in advance with a temporary name, and it's nil if it isn't ready.
+	file *os.File
+
+	// name is the real name of file, which will be used when taking it.
+	name string
+
+	// current is the path of the file being written, and it won't be created in advance.
+	current string
+
+	// generation increases when discarding, so the file created before discarding won't be used.
+	generation uint64
+}
+
+// start starts a goroutine creating the next file named nextName() if it's enabled and there
+// is no next file. The mu is the mutex of rolling file, which will be locked after creating.
+// The next file won't be created if nextName() returns the path of the file being written.
+func (p *preallocator) start(mu *sync.Mutex, nextName func() string) {
+	if !p.enabled || p.running || p.file != nil {
+		return
+	}
+
+	p.running = true
+	generation, current := p.generation, p.current
+	go func() {
+		name := nextName()
+
+		// 下一个文件的名字和当前的文件一样的话，滚动的时候会继续使用当前的文件，不需要提前创建
+		var file *os.File
+		var err error
+		if name != current {
+			file, err = createPreallocatedFile(name)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// 创建失败就等到下一次滚动再重试，而文件已经关闭或者不再需要的话，就删除创建的文件
+		p.running = false
+		if file == nil || err != nil {
+			return
+		}
+
+		if !p.enabled || p.file != nil || p.generation != generation {
+			removeFile(file)
+			return
+		}
+		p.file, p.name = file, name
+	}()
+}
+
+// take returns the next file created in advance and nil if it isn't ready.
+// The file will be linked to its real name, and nil will be returned if the real name exists.
+func (p *preallocator) take() *os.File {
+	file, name := p.file, p.name
+	p.file, p.name = nil, ""
+	if file == nil {
+		return nil
+	}
+
+	// 硬链接在目标文件已经存在的时候会失败，这样就不会覆盖任何已有的文件，失败的话就同步创建文件
+	if err := os.Link(file.Name(), name); err != nil {
+		removeFile(file)
+		return nil
+	}
+
+	os.Remove(file.Name())
+	p.current = name
+	return file
+}
+
+// use records path as the path of the file being written, which is created without preallocator.
+func (p *preallocator) use(path string) {
+	p.current = path
+}
+
+// discard removes the next file created in advance if it exists.
+// The file being created now will be removed after creating, too.
+func (p *preallocator) discard() {
+	p.generation++
+	if p.file != nil {
+		removeFile(p.file)
+		p.file, p.name = nil, ""
+	}
+}
+
+// createPreallocatedFile creates a file with a temporary name in the same directory of name.
+// The file is created exclusively, so only the file created by it will be removed.
+func createPreallocatedFile(name string) (*os.File, error) {
+	tempName := name + "." + strconv.FormatInt(time.Now().UnixNano(), 36) + ".preallocated"
+	return os.OpenFile(tempName, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0664)
+}
+
+// removeFile closes file and removes it.
+// Notice that file should be created by createPreallocatedFile, so it's never a log file in use.
+func removeFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 17:05:48
+
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Schedule is the type for computing the next rolling time of ScheduledRollingFile.
+// The parameter is the time of current moment, and the returned time should be after it.
+type Schedule func(time.Time) time.Time
+
+// Next is for code-readable.
+// Return the next rolling time after now.
+func (s Schedule) Next(now time.Time) time.Time {
+	return s(now)
+}
+
+// Hourly returns a schedule rolling at the start of every hour, like 13:00 and 14:00.
+func Hourly() Schedule {
+	return func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location()).Add(time.Hour)
+	}
+}
+
+// DailyAt returns a schedule rolling at clocks every day. A clock is like "00:00" or "12:30",
+// which is in the location of the time passed to schedule, and more than one clock can be given.
+// Return an error if no clock is given or one of clocks isn't in the form of "HH:MM".
+func DailyAt(clocks ...string) (Schedule, error) {
+	if len(clocks) < 1 {
+		return nil, errors.New("at least one clock should be given")
+	}
+
+	minutes := make([]int, 0, len(clocks))
+	for _, clock := range clocks {
+		parsed, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("clock %s should be in the form of HH:MM", clock)
+		}
+		minutes = append(minutes, parsed.Hour()*60+parsed.Minute())
+	}
+
+	return func(now time.Time) time.Time {
+		var next time.Time
+		for _, minute := range minutes {
+			at := time.Date(now.Year(), now.Month(), now.Day(), minute/60, minute%60, 0, 0, now.Location())
+
+			// 今天的这个时间点已经过了，就用明天的
+			if !at.After(now) {
+				at = at.AddDate(0, 0, 1)
+			}
+
+			if next.IsZero() || at.Before(next) {
+				next = at
+			}
+		}
+		return next
+	}, nil
+}
+
+// ScheduledRollingFile is a file rolling at the instants of a schedule.
+//
+//  schedule, err := DailyAt("00:00", "12:00")
+//  if err != nil {
+//      panic(err)
+//  }
+//  file, err := NewScheduledRollingFile("D:/", schedule)
+//  if err != nil {
+//      panic(err)
+//  }
+//  defer file.Close()
+//  file.Write([]byte("Hello!"))
+//
+// You can use it like using os.File!
+type ScheduledRollingFile struct {
+
+	// file points the writer which will be used this moment.
+	file *os.File
+
+	// directory is the target storing all created files.
+	directory string
+
+	// schedule computes the next rolling time.
+	schedule Schedule
+
+	// nextTime is the time of next rolling.
+	nextTime time.Time
+
+	// nameGenerator is for generating the name of every created file.
+	// Default is DefaultNameGenerator().
+	nameGenerator NameGenerator
+
+	// now returns the time of current moment, and it's replaceable for testing.
+	now func() time.Time
+
+	// closed is a flag to check if this file has been closed.
+	// Close is idempotent, and writing to a closed file returns os.ErrClosed.
+	closed bool
+
+	// mu is a lock for safe concurrency.
+	mu *sync.Mutex
+}
+
+// NewScheduledRollingFile creates a new file rolling at the instants of schedule, such as
+// every day at 00:00 and 12:00. See Hourly and DailyAt.
+// Return an error if directory isn't an existing directory.
+func NewScheduledRollingFile(directory string, schedule Schedule) (*ScheduledRollingFile, error) {
+	if err := checkDirectory(directory); err != nil {
+		return nil, err
+	}
+
+	return &ScheduledRollingFile{
+		directory:     directory,
+		schedule:      schedule,
+		nameGenerator: DefaultNameGenerator(),
+		now:           time.Now,
+		mu:            &sync.Mutex{},
+	}, nil
+}
+
+// ensureFileIsCorrect ensures srf is writing to a correct file this moment.
+// If creating new file failed, current file will be used until next writing.
+func (srf *ScheduledRollingFile) ensureFileIsCorrect() {
+	now := srf.now()
+	if srf.file != nil && now.Before(srf.nextTime) {
+		return
+	}
+
+	newFile, err := CreateFileOf(srf.nameGenerator.NextName(srf.directory, now))
+	if err != nil {
+		return
+	}
+
+	if srf.file != nil {
+		srf.file.Close()
+	}
+
+	srf.file = newFile
+	srf.nextTime = srf.schedule.Next(now)
+}
+
+// Write writes len(p) bytes from p to the underlying data stream.
+// It returns the number of bytes written from p (0 <= n <= len(p))
+// and any error encountered that caused the write to stop early.
+// Return os.ErrClosed if the file has been closed.
+func (srf *ScheduledRollingFile) Write(p []byte) (n int, err error) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+
+	if srf.closed {
+		return 0, os.ErrClosed
+	}
+
+	// 确保当前文件对于当前时间点来说是正确的
+	srf.ensureFileIsCorrect()
+	if srf.file == nil {
+		return 0, os.ErrInvalid
+	}
+	return srf.file.Write(p)
+}
+
+// Close releases any resources using just moment.
+// It's safe to call it more than once, and the calls after the first one will do nothing and return nil.
+func (srf *ScheduledRollingFile) Close() error {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+
+	if srf.closed {
+		return nil
+	}
+	srf.closed = true
+
+	if srf.file == nil {
+		return nil
+	}
+	return srf.file.Close()
+}
+
+// SetNameGenerator replaces srf.nameGenerator to newNameGenerator.
+// It takes effect from the next file.
+func (srf *ScheduledRollingFile) SetNameGenerator(newNameGenerator NameGenerator) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+	srf.nameGenerator = newNameGenerator
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 17:31:20
+
+package files
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试计划表计算下一次滚动的时间
+func TestSchedule(t *testing.T) {
+	if _, err := DailyAt(); err == nil {
+		t.Fatal("没有时间点的计划表应该返回错误！")
+	}
+
+	if _, err := DailyAt("24:00"); err == nil {
+		t.Fatal("错误的时间点应该返回错误！")
+	}
+
+	daily, err := DailyAt("12:00", "00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		schedule Schedule
+		now      string
+		next     string
+	}{
+		{Hourly(), "2020-08-30 17:31:20", "2020-08-30 18:00:00"},
+		{Hourly(), "2020-08-30 23:00:00", "2020-08-31 00:00:00"},
+		{daily, "2020-08-30 08:00:00", "2020-08-30 12:00:00"},
+		{daily, "2020-08-30 12:00:00", "2020-08-31 00:00:00"},
+		{daily, "2020-08-31 23:59:59", "2020-09-01 00:00:00"},
+	}
+
+	for i, c := range cases {
+		now, _ := time.ParseInLocation("2006-01-02 15:04:05", c.now, time.Local)
+		if next := c.schedule.Next(now).Format("2006-01-02 15:04:05"); next != c.next {
+			t.Fatalf("第 %d 个案例的下一次滚动时间不正确！%s", i+1, next)
+		}
+	}
+}
+
+// 测试按照计划表滚动文件
+func TestScheduledRollingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestScheduledRollingFile_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	schedule, err := DailyAt("00:00", "12:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := NewScheduledRollingFile(dir, schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// 使用假的时钟，只在计划的时间点滚动
+	now := time.Date(2020, 8, 30, 11, 0, 0, 0, time.Local)
+	file.now = func() time.Time { return now }
+	file.SetNameGenerator(TimeFormatNameGenerator("20060102-1504", ".log"))
+
+	writes := []struct {
+		offset time.Duration
+		data   string
+	}{
+		{0, "a"},
+		{59 * time.Minute, "b"},
+		{time.Hour, "c"},
+		{12*time.Hour + 59*time.Minute, "d"},
+		{13 * time.Hour, "e"},
+	}
+
+	begin := now
+	for _, w := range writes {
+		now = begin.Add(w.offset)
+		if _, err := file.Write([]byte(w.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expects := map[string]string{
+		"20200830-1100.log": "ab",
+		"20200830-1200.log": "cd",
+		"20200831-0000.log": "e",
+	}
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fileInfos) != len(expects) {
+		t.Fatalf("滚动的文件个数不正确！%d", len(fileInfos))
+	}
+
+	for name, expect := range expects {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != expect {
+			t.Fatalf("文件 %s 的内容不正确！%s", name, content)
+		}
+	}
+
+	file.Close()
+	if _, err := file.Write([]byte("closed")); err != os.ErrClosed {
+		t.Fatalf("关闭之后写入应该返回 os.ErrClosed！%v", err)
+	}
+}
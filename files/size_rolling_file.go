@@ -20,9 +20,11 @@ package files
 
 import (
 	"errors"
+	"io"
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -70,6 +72,34 @@ type SizeRollingFile struct {
 	// Default is DefaultNameGenerator().
 	nameGenerator NameGenerator
 
+	// writeTimeout is the max duration of one writing, and <= 0 means no timeout.
+	// See SetWriteTimeout.
+	writeTimeout time.Duration
+
+	// timeoutWriting writes with writeTimeout and allows at most one writing in flight.
+	timeoutWriting timeoutWriting
+
+	// writeBOM is a flag to check if a UTF-8 BOM should be written at the start of new file.
+	// See SetWriteBOM.
+	writeBOM bool
+
+	// gzip is a flag to check if new files should be compressed in gzip. See SetGzip.
+	gzip bool
+
+	// gzipWriter compresses data to file if the file is compressed, or it's nil.
+	gzipWriter *GzipWriter
+
+	// compressedFile counts the compressed bytes written to file if the file is compressed, or it's nil.
+	// The currentSize of a compressed file is the count of it instead of the bytes written to gzipWriter.
+	compressedFile *countingFile
+
+	// preallocator creates the next file in advance if it's enabled. See SetPreallocateNext.
+	preallocator preallocator
+
+	// closed is a flag to check if this file has been closed.
+	// Close is idempotent, and writing to a closed file returns os.ErrClosed.
+	closed bool
+
 	// mu is a lock for safe concurrency.
 	mu *sync.Mutex
 }
@@ -86,11 +116,26 @@ const (
 // Every times rolling to next file will call nextFilename first.
 // now is the created time of next file. Notice that limitedSize's min value
 // is 64 KB (64 * 1024 bytes). See minLimitedSize.
+// It panics if failed, and see NewSizeRollingFileE if you want an error instead.
 func NewSizeRollingFile(directory string, limitedSize int64) *SizeRollingFile {
+	file, err := NewSizeRollingFileE(directory, limitedSize)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// NewSizeRollingFileE is the same as NewSizeRollingFile except it returns an error instead of
+// panicking if limitedSize is too small or directory isn't an existing directory.
+func NewSizeRollingFileE(directory string, limitedSize int64) (*SizeRollingFile, error) {
 
 	// 防止文件限制尺寸太小导致滚动文件时 IO 的疯狂蠕动
 	if limitedSize < minLimitedSize {
-		panic(errors.New("LimitedSize is smaller than " + strconv.FormatUint(uint64(minLimitedSize)>>10, 10) + " KB!\n"))
+		return nil, errors.New("LimitedSize is smaller than " + strconv.FormatUint(uint64(minLimitedSize)>>10, 10) + " KB!\n")
+	}
+
+	if err := checkDirectory(directory); err != nil {
+		return nil, err
 	}
 
 	return &SizeRollingFile{
@@ -99,22 +144,50 @@ func NewSizeRollingFile(directory string, limitedSize int64) *SizeRollingFile {
 		currentSize:   0,
 		nameGenerator: DefaultNameGenerator(),
 		mu:            &sync.Mutex{},
-	}
+	}, nil
 }
 
 // rollingToNextFile will roll to next file for srf.
 func (srf *SizeRollingFile) rollingToNextFile(now time.Time) {
 
+	// 优先使用提前创建好的文件，没有的话再同步创建
 	// 如果创建新文件发生错误，就继续使用当前的文件，等到下一次时间间隔再重试
-	newFile, err := CreateFileOf(srf.nameGenerator.NextName(srf.directory, now))
-	if err != nil {
-		return
+	newFile := srf.preallocator.take()
+	if newFile == nil {
+		var err error
+		path := srf.nameGenerator.NextName(srf.directory, now)
+		newFile, err = CreateFileOf(path)
+		if err != nil {
+			return
+		}
+		srf.preallocator.use(path)
 	}
 
 	// 关闭当前使用的文件，初始化新文件
-	srf.file.Close()
-	srf.file = newFile
-	srf.currentSize = 0
+	srf.closeFile()
+	srf.file, srf.gzipWriter, srf.compressedFile = newFile, nil, nil
+	if srf.gzip {
+		srf.compressedFile = &countingFile{File: newFile}
+		srf.gzipWriter = NewGzipWriter(srf.compressedFile)
+	}
+
+	// BOM 也算在文件大小里面
+	var bomSize int
+	if srf.writeBOM {
+		bomSize, _ = writeBOMIfEmpty(newFile, srf.writer())
+	}
+	srf.currentSize = int64(bomSize)
+	srf.updateCompressedSize()
+	srf.preallocateNextFile()
+}
+
+// preallocateNextFile creates the next file in background if it's enabled.
+// The time of rolling by size is unknown, so the name is generated in the time of creating.
+func (srf *SizeRollingFile) preallocateNextFile() {
+	directory, nameGenerator := srf.directory, srf.nameGenerator
+	srf.preallocator.start(srf.mu, func() string {
+		return nameGenerator.NextName(directory, time.Now())
+	})
 }
 
 // ensureFileIsCorrect ensures srf is writing to a correct file this moment.
@@ -145,36 +218,216 @@ func (srf *SizeRollingFile) ensureFileIsCorrect() {
 	}
 }
 
-// writeAndUpdateCurrentSize writes p to srf.file and updates srf.currentSize with n.
-func (srf *SizeRollingFile) writeAndUpdateCurrentSize(p []byte) (int, error) {
-	n, err := srf.file.Write(p)
-	srf.currentSize += int64(n)
+// writeAndUpdateCurrentSize writes p to writer and updates srf.currentSize with n.
+// Notice that n is the count of bytes really written, which may be less than len(p) if
+// writing failed partially, such as the disk is full. So only n should be counted.
+// The bytes of a writing timeout will be counted when it finishes in background.
+func (srf *SizeRollingFile) writeAndUpdateCurrentSize(writer io.Writer, p []byte) (int, error) {
+	var late func(n int)
+	if srf.writeTimeout > 0 {
+		late = srf.lateWritten(len(p))
+	}
+
+	n, err := srf.timeoutWriting.write(writer, p, srf.writeTimeout, late)
+	srf.currentSize += int64(validWrittenCount(n, len(p)))
+	srf.updateCompressedSize()
 	return n, err
 }
 
+// lateWritten returns a function adding the bytes of a writing of size bytes, which finishes after timeout,
+// to the size of current file. The bytes will be ignored if the file has rolled, and the compressed file
+// doesn't need it, because the compressed bytes are counted when they are written to file.
+func (srf *SizeRollingFile) lateWritten(size int) func(n int) {
+	file := srf.file
+	return func(n int) {
+		srf.mu.Lock()
+		defer srf.mu.Unlock()
+
+		if srf.file == file && srf.compressedFile == nil {
+			srf.currentSize += int64(validWrittenCount(n, size))
+		}
+	}
+}
+
+// validWrittenCount returns n limited in [0, size].
+// It prevents a bad writer returning an invalid n from messing up the count.
+func validWrittenCount(n int, size int) int {
+	if n < 0 {
+		return 0
+	}
+
+	if n > size {
+		return size
+	}
+	return n
+}
+
+// updateCompressedSize sets srf.currentSize to the compressed bytes written to file if it's compressed.
+// The data written to gzip writer is buffered before compressing, so the compressed size grows when
+// the gzip stream is flushed.
+func (srf *SizeRollingFile) updateCompressedSize() {
+	if srf.compressedFile != nil {
+		srf.currentSize = srf.compressedFile.written()
+	}
+}
+
+// writer returns the writer which data should be written to.
+// It's the gzip writer if current file is compressed, or the file itself.
+func (srf *SizeRollingFile) writer() io.Writer {
+	if srf.gzipWriter != nil {
+		return srf.gzipWriter
+	}
+	return srf.file
+}
+
+// closeFile closes current file, and the gzip stream will be closed first if it's compressed.
+func (srf *SizeRollingFile) closeFile() error {
+
+	// 文件可能还没有创建过，比如还没有写入过数据的滚动文件
+	if srf.file == nil {
+		return nil
+	}
+
+	// 压缩流关闭时会写入尾部信息，并且会关闭文件，这样滚动出来的每个文件都是完整的压缩文件
+	if srf.gzipWriter != nil {
+		return srf.gzipWriter.Close()
+	}
+	return srf.file.Close()
+}
+
 // Write writes len(p) bytes from p to the underlying data stream.
 // It returns the number of bytes written from p (0 <= n <= len(p))
 // and any error encountered that caused the write to stop early.
+// Return os.ErrClosed if the file has been closed.
 func (srf *SizeRollingFile) Write(p []byte) (n int, err error) {
 	srf.mu.Lock()
 	defer srf.mu.Unlock()
 
+	if srf.closed {
+		return 0, os.ErrClosed
+	}
+
 	// 确保当前文件对于当前时间点来说是正确的
 	srf.ensureFileIsCorrect()
-	return srf.writeAndUpdateCurrentSize(p)
+	return srf.writeAndUpdateCurrentSize(srf.writer(), p)
 }
 
 // Close releases any resources using just moment.
-// It returns error when closing.
+// It returns error when closing. It's safe to call it more than once, and the
+// calls after the first one will do nothing and return nil.
 func (srf *SizeRollingFile) Close() error {
 	srf.mu.Lock()
 	defer srf.mu.Unlock()
-	return srf.file.Close()
+
+	if srf.closed {
+		return nil
+	}
+	srf.closed = true
+
+	// 提前创建的文件还没有被使用过，直接删除，正在创建的文件会在创建完之后删除
+	srf.preallocator.enabled = false
+	srf.preallocator.discard()
+	return srf.closeFile()
 }
 
 // SetNameGenerator replaces srf.nameGenerator to newNameGenerator.
+// The next file created in advance will be discarded, so the next name is from newNameGenerator.
 func (srf *SizeRollingFile) SetNameGenerator(nameGenerator NameGenerator) {
 	srf.mu.Lock()
 	defer srf.mu.Unlock()
 	srf.nameGenerator = nameGenerator
+	srf.preallocator.discard()
+	srf.preallocateNextFile()
+}
+
+// SetWriteTimeout sets the max duration of one writing to timeout, and <= 0 means no timeout.
+// If a writing doesn't finish in timeout, WriteTimeoutError will be returned instead of blocking
+// forever, which is useful on network file systems. Notice that it adds a goroutine per writing
+// when enabled, and the timeout writing will keep going in background. The bytes of it will be counted
+// in the size of file when it finishes.
+// Only one writing is allowed in flight, so writings before the timeout one finishes will fail
+// with WritePendingError immediately, and no goroutine piles up on a hung file system.
+func (srf *SizeRollingFile) SetWriteTimeout(timeout time.Duration) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+	srf.writeTimeout = timeout
+}
+
+// SetWriteBOM sets if a UTF-8 BOM should be written at the start of every new file when rolling,
+// which helps some Windows tools recognize the encoding. The BOM won't be written to a file which
+// isn't empty, such as an existing file with the same name.
+func (srf *SizeRollingFile) SetWriteBOM(writeBOM bool) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+	srf.writeBOM = writeBOM
+}
+
+// SetGzip sets if new files should be compressed in gzip continuously, so the files on disk are
+// always compressed. The gzip stream will be closed properly when rolling, so every file rolled is
+// a valid archive. It takes effect from the next file, and you may want to change the name generator
+// to use a ".gz" suffix. Notice that the limited size is the size of compressed data written to file,
+// and the data buffered in the gzip stream isn't counted until it's flushed, so a file may exceed the
+// limited size by the data written between two flushes. See GzipWriter.SetFlushInterval.
+func (srf *SizeRollingFile) SetGzip(gzip bool) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+	srf.gzip = gzip
+}
+
+// Flush flushes the gzip stream of current file if it's compressed.
+func (srf *SizeRollingFile) Flush() error {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+
+	if srf.closed || srf.gzipWriter == nil {
+		return nil
+	}
+
+	err := srf.gzipWriter.Flush()
+	srf.updateCompressedSize()
+	return err
+}
+
+// SetPreallocateNext sets if the next file should be created in advance in background, so rolling
+// to next file is instantaneous without creating a file on the write path. The time in name is the
+// time of creating it, because the time of rolling by size is unknown. The next file is created with
+// a temporary name and renamed when rolling, so an existing file with the same name won't be touched.
+// The next file not used will be removed when closing.
+func (srf *SizeRollingFile) SetPreallocateNext(preallocate bool) {
+	srf.mu.Lock()
+	defer srf.mu.Unlock()
+
+	if srf.closed {
+		return
+	}
+
+	srf.preallocator.enabled = preallocate
+	if !preallocate {
+		srf.preallocator.discard()
+		return
+	}
+	srf.preallocateNextFile()
+}
+
+// countingFile is a file counting the bytes written to it.
+// It's safe to write concurrently, because a writing timeout may keep going in background.
+type countingFile struct {
+
+	// count is the count of bytes written to file.
+	// It's the first field to be 64-bit aligned for atomic operations on 32-bit platforms.
+	count int64
+
+	*os.File
+}
+
+// Write writes p to file and counts the bytes written.
+func (cf *countingFile) Write(p []byte) (n int, err error) {
+	n, err = cf.File.Write(p)
+	atomic.AddInt64(&cf.count, int64(n))
+	return n, err
+}
+
+// written returns the count of bytes written to file.
+func (cf *countingFile) written() int64 {
+	return atomic.LoadInt64(&cf.count)
 }
@@ -19,9 +19,12 @@
 package files
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -95,3 +98,267 @@ func TestSizeRollingFileSetNameGenerator(t *testing.T) {
 		file.Write([]byte("   hi!!   "))
 	}
 }
+
+// 测试多次关闭文件大小滚动文件
+func TestSizeRollingFileClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileClose_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 还没有写入过数据，文件还没有创建
+	file := NewSizeRollingFile(dir, 64*KB)
+	if err := file.Close(); err != nil {
+		t.Fatalf("关闭没有创建过的文件出现错误！%v", err)
+	}
+
+	file = NewSizeRollingFile(dir, 64*KB)
+	file.Write([]byte("hello!"))
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatalf("第二次关闭文件出现错误！%v", err)
+	}
+
+	if _, err := file.Write([]byte("closed!")); err != os.ErrClosed {
+		t.Fatalf("写入已经关闭的文件没有返回 os.ErrClosed！%v", err)
+	}
+}
+
+// 测试每个滚动出来的文件都以 BOM 开头
+func TestSizeRollingFileSetWriteBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileSetWriteBOM_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := NewSizeRollingFile(dir, 64*KB)
+	defer file.Close()
+	file.SetWriteBOM(true)
+
+	// 第一次写入就达到限制大小，第二次写入会滚动到新文件
+	file.Write(make([]byte, 64*KB))
+	file.Write([]byte("rolled"))
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fileInfos) != 2 {
+		t.Fatalf("文件滚动出现问题！%d", len(fileInfos))
+	}
+
+	for _, fileInfo := range fileInfos {
+		content, err := ioutil.ReadFile(filepath.Join(dir, fileInfo.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(content) < 3 || string(content[:3]) != string(utf8BOM) {
+			t.Fatalf("文件 %s 没有以 BOM 开头！", fileInfo.Name())
+		}
+
+		if len(content) != 64*int(KB)+3 && string(content[3:]) != "rolled" {
+			t.Fatalf("文件 %s 的内容不正确！", fileInfo.Name())
+		}
+	}
+}
+
+// 测试创建文件失败时返回错误而不是 panic
+func TestNewSizeRollingFileE(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewSizeRollingFileE_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewSizeRollingFileE(dir, KB); err == nil {
+		t.Fatal("限制值太小没有返回错误！")
+	}
+
+	// 不存在的目录和普通文件都不能作为目录
+	if _, err := NewSizeRollingFileE(filepath.Join(dir, "not_existed"), 64*KB); err == nil {
+		t.Fatal("不存在的目录没有返回错误！")
+	}
+
+	path := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(path, nil, 0664); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewSizeRollingFileE(path, 64*KB); err == nil {
+		t.Fatal("普通文件作为目录没有返回错误！")
+	}
+
+	file, err := NewSizeRollingFileE(dir, 64*KB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+}
+
+// 只写入一半数据的 writer，模拟磁盘空间不足的情况
+type shortWriter struct {
+	written int
+}
+
+func (sw *shortWriter) Write(p []byte) (n int, err error) {
+	n = len(p) / 2
+	sw.written += n
+	return n, io.ErrShortWrite
+}
+
+// 测试部分写入成功时文件大小的计数
+func TestSizeRollingFileShortWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileShortWrite_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := NewSizeRollingFile(dir, 64*KB)
+	defer file.Close()
+
+	writer := &shortWriter{}
+	for i := 0; i < 10; i++ {
+		n, err := file.writeAndUpdateCurrentSize(writer, []byte("0123456789"))
+		if n != 5 || err != io.ErrShortWrite {
+			t.Fatalf("部分写入的返回值不正确！%d %v", n, err)
+		}
+	}
+
+	if file.currentSize != int64(writer.written) || file.currentSize != 50 {
+		t.Fatalf("部分写入之后文件大小的计数不正确！%d %d", file.currentSize, writer.written)
+	}
+
+	// 滚动到下一个文件之后，计数需要重置为新文件的大小
+	file.rollingToNextFile(time.Now())
+	if file.currentSize != 0 {
+		t.Fatalf("滚动之后文件大小的计数没有重置！%d", file.currentSize)
+	}
+
+	file.Write([]byte("hello"))
+	if file.currentSize != 5 {
+		t.Fatalf("滚动之后文件大小的计数不正确！%d", file.currentSize)
+	}
+}
+
+// 等待提前创建的文件准备好
+func waitForPreallocated(t *testing.T, mu *sync.Mutex, p *preallocator) {
+	for i := 0; i < 1000; i++ {
+		mu.Lock()
+		ready := p.file != nil
+		mu.Unlock()
+
+		if ready {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("等待提前创建的文件超时！")
+}
+
+// 测试提前创建下一个文件
+func TestSizeRollingFileSetPreallocateNext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileSetPreallocateNext_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 名字生成器每次都需要拿到令牌才能返回，用来检测写入的时候有没有创建文件
+	tokens := make(chan struct{}, 1)
+	tokens <- struct{}{}
+	names := make(chan string, 16)
+	count := 0
+	file := NewSizeRollingFile(dir, 64*KB)
+	file.SetNameGenerator(func(directory string, now time.Time) string {
+		<-tokens
+		count++
+		name := filepath.Join(directory, strconv.Itoa(count)+SuffixOfLogFile)
+		names <- name
+		return name
+	})
+
+	file.SetPreallocateNext(true)
+	waitForPreallocated(t, file.mu, &file.preallocator)
+
+	// 写入时直接使用提前创建的文件，如果写入时创建了文件，就会因为拿不到令牌而阻塞
+	written := make(chan struct{})
+	go func() {
+		file.Write([]byte("hello"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("写入的时候创建了文件！")
+	}
+
+	content, err := ioutil.ReadFile(<-names)
+	if err != nil || string(content) != "hello" {
+		t.Fatalf("没有写入提前创建的文件！%s %v", content, err)
+	}
+
+	// 放行后台创建的下一个文件，关闭之后没有使用过的文件需要被删除
+	tokens <- struct{}{}
+	waitForPreallocated(t, file.mu, &file.preallocator)
+	next := <-names
+	file.Close()
+
+	if _, err := os.Stat(next); !os.IsNotExist(err) {
+		t.Fatalf("关闭之后没有删除提前创建的文件！%v", err)
+	}
+}
+
+// 测试名字固定的时候，提前创建文件不会删除正在写入的文件
+func TestSizeRollingFileSetPreallocateNextFixedName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileSetPreallocateNextFixedName_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fixed"+SuffixOfLogFile)
+	file := NewSizeRollingFile(dir, 64*KB)
+	file.SetNameGenerator(func(directory string, now time.Time) string {
+		return path
+	})
+
+	file.SetPreallocateNext(true)
+	file.Write([]byte("hello"))
+
+	// 等待后台创建下一个文件的协程结束
+	for i := 0; i < 1000; i++ {
+		file.mu.Lock()
+		running := file.preallocator.running
+		file.mu.Unlock()
+
+		if !running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 替换名字生成器和关闭文件都会丢弃提前创建的文件
+	file.SetNameGenerator(func(directory string, now time.Time) string {
+		return path
+	})
+	file.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != "hello" {
+		t.Fatalf("关闭之后正在写入的文件被删除了！%s %v", content, err)
+	}
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil || len(fileInfos) != 1 {
+		t.Fatalf("关闭之后还有提前创建的临时文件！%d %v", len(fileInfos), err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/18 21:14:26
+
+package files
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// WriteTimeoutError is an error happening on a writing which doesn't finish in write timeout.
+	// See DurationRollingFile.SetWriteTimeout and SizeRollingFile.SetWriteTimeout.
+	WriteTimeoutError = errors.New("writing to file timeout! Maybe the file system is hung")
+
+	// WritePendingError is an error happening on a writing when the last writing timeout is still
+	// in flight. Only one writing is allowed in flight, so the hung file system won't pile up goroutines.
+	WritePendingError = errors.New("the last writing timeout is still pending! Maybe the file system is hung")
+)
+
+// writeResult is the result of writing in another goroutine.
+type writeResult struct {
+	n   int
+	err error
+}
+
+// timeoutWriting writes with timeout and allows at most one writing in flight.
+// The zero value is ready to use.
+type timeoutWriting struct {
+
+	// pending is 1 if a writing is in flight, including the one timeout and still going in background.
+	pending int32
+}
+
+// write writes p to writer in a new goroutine and waits for it at most timeout.
+// If timeout <= 0, p will be written to writer directly without any goroutine.
+// Return WriteTimeoutError if timeout, and the writing will keep going in background
+// because there is no way to stop a goroutine. That's why p will be copied before writing.
+// The late will be called with the count of bytes written when the writing timeout finishes,
+// and it can be nil. Return WritePendingError immediately if the writing timeout is still in flight.
+func (tw *timeoutWriting) write(writer io.Writer, p []byte, timeout time.Duration, late func(n int)) (int, error) {
+	if !atomic.CompareAndSwapInt32(&tw.pending, 0, 1) {
+		return 0, WritePendingError
+	}
+
+	if timeout <= 0 {
+		defer atomic.StoreInt32(&tw.pending, 0)
+		return writer.Write(p)
+	}
+
+	// 超时返回之后，调用者可能会复用 p，所以要拷贝一份给后台的 goroutine 使用
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	// 使用带缓冲的 channel，超时之后 goroutine 依然可以写入结果并退出，不会泄露
+	results := make(chan writeResult, 1)
+	go func() {
+		n, err := writer.Write(data)
+		results <- writeResult{n: n, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		atomic.StoreInt32(&tw.pending, 0)
+		return result.n, result.err
+	case <-timer.C:
+		// 超时的写入最终还是会写到文件里，写完之后需要把字节数算上，并且允许下一次写入
+		go func() {
+			result := <-results
+			if late != nil {
+				late(result.n)
+			}
+			atomic.StoreInt32(&tw.pending, 0)
+		}()
+		return 0, WriteTimeoutError
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/18 21:40:53
+
+package files
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// 一直阻塞的 writer，模拟挂起的文件系统
+type blockingWriter struct {
+	unblock chan struct{}
+}
+
+func (bw *blockingWriter) Write(p []byte) (n int, err error) {
+	<-bw.unblock
+	return len(p), nil
+}
+
+// 测试带超时的写入
+func TestWriteWithTimeout(t *testing.T) {
+	writer := &blockingWriter{unblock: make(chan struct{})}
+	defer close(writer.unblock)
+
+	tw := &timeoutWriting{}
+	begin := time.Now()
+	n, err := tw.write(writer, []byte("hung"), 10*time.Millisecond, nil)
+	if err != WriteTimeoutError || n != 0 {
+		t.Fatalf("写入阻塞的 writer 没有超时！%d %v", n, err)
+	}
+
+	if time.Since(begin) > time.Second {
+		t.Fatalf("超时的时间不正确！%v", time.Since(begin))
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	tw = &timeoutWriting{}
+	n, err = tw.write(buffer, []byte("ok"), time.Second, nil)
+	if err != nil || n != 2 || buffer.String() != "ok" {
+		t.Fatalf("正常的写入结果不正确！%d %v %s", n, err, buffer.String())
+	}
+
+	n, err = tw.write(buffer, []byte("!"), 0, nil)
+	if err != nil || n != 1 || buffer.String() != "ok!" {
+		t.Fatalf("不设置超时的写入结果不正确！%d %v %s", n, err, buffer.String())
+	}
+}
+
+// 测试超时的写入还没有完成的时候，后面的写入立即失败，完成之后统计写入的字节数
+func TestTimeoutWritingPending(t *testing.T) {
+	writer := &blockingWriter{unblock: make(chan struct{})}
+	tw := &timeoutWriting{}
+
+	late := make(chan int, 1)
+	n, err := tw.write(writer, []byte("hung"), 10*time.Millisecond, func(n int) {
+		late <- n
+	})
+
+	if err != WriteTimeoutError || n != 0 {
+		t.Fatalf("写入阻塞的 writer 没有超时！%d %v", n, err)
+	}
+
+	begin := time.Now()
+	n, err = tw.write(writer, []byte("next"), time.Second, nil)
+	if err != WritePendingError || n != 0 {
+		t.Fatalf("超时的写入还没完成的时候应该立即失败！%d %v", n, err)
+	}
+
+	if time.Since(begin) > 100*time.Millisecond {
+		t.Fatalf("超时的写入还没完成的时候没有立即失败！%v", time.Since(begin))
+	}
+
+	close(writer.unblock)
+	if n := <-late; n != len("hung") {
+		t.Fatalf("超时的写入完成之后统计的字节数不正确！%d", n)
+	}
+
+	// 超时的写入完成之后可以继续写入
+	for i := 0; i < 1000; i++ {
+		if n, err = tw.write(writer, []byte("ok"), time.Second, nil); err != WritePendingError {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err != nil || n != 2 {
+		t.Fatalf("超时的写入完成之后不能继续写入！%d %v", n, err)
+	}
+}
+
+// 测试超时的写入完成之后，写入的字节数算在文件大小里
+func TestSizeRollingFileLateWritten(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSizeRollingFileLateWritten_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := NewSizeRollingFile(dir, 64*KB)
+	defer file.Close()
+
+	file.Write([]byte("hello"))
+	file.lateWritten(len("hung"))(len("hung"))
+	if file.currentSize != int64(len("hello")+len("hung")) {
+		t.Fatalf("超时的写入没有算在文件大小里！%d", file.currentSize)
+	}
+
+	// 文件滚动之后，旧文件的写入不能算在新文件里
+	late := file.lateWritten(len("hung"))
+	file.rollingToNextFile(time.Now())
+	late(len("hung"))
+	if file.currentSize != 0 {
+		t.Fatalf("旧文件的写入算在了新文件里！%d", file.currentSize)
+	}
+}
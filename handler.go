@@ -23,22 +23,45 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	// DefaultTimeFormat is the default format for formatting time.
 	DefaultTimeFormat = "2006-01-02 15:04:05"
+
+	// levelParam is the param of handlers in config which points the min level of logs they handle.
+	levelParam = "level"
+
+	// fieldsParam is the param of handlers in config which points the static fields added to logs they handle.
+	fieldsParam = "fields"
 )
 
 var (
 	// handlers stores all handlers registered.
 	// mutexOfHandlers is for concurrency.
-	handlers        = map[string]func(params map[string]interface{}) Handler{}
+	// The built-in handlers return errors instead of panicking, so the constructors like
+	// NewLoggerFromE can return them. See registerHandlerE.
+	handlers        = map[string]func(params map[string]interface{}) (Handler, error){}
 	mutexOfHandlers = &sync.RWMutex{}
 
 	// HandlerIsExistedError is an error happening on repeating handler name.
 	HandlerIsExistedError = errors.New("the name of handler you want to register already exists! May be you should give it an another name")
+
+	// CircuitOpenError is an error happening on dropping a log because the circuit is open.
+	// See NewCircuitBreakerHandler.
+	CircuitOpenError = errors.New("the circuit is open, so the log is dropped")
+
+	// OutputNotSwappableError is an error happening on setting output of a logger whose handlers
+	// aren't one standard handler. See Logger.SetOutput.
+	OutputNotSwappableError = errors.New("the output of logger can't be swapped because it doesn't have only one standard handler")
+
+	// NoHandlerError is an error happening on replacing the handlers of a logger with no handler.
+	// See Logger.ReplaceHandlers.
+	NoHandlerError = errors.New("at least one handler should be set")
 )
 
 // Handler is an interface representation of log handler.
@@ -55,6 +78,52 @@ type Handler interface {
 	Handle(log *Log) bool
 }
 
+// Flusher is an interface representation of something buffering data inside.
+// Handlers buffering logs should implement it, so the logger can flush them.
+// Also, writers like bufio.Writer implement it, and the standard handler will
+// flush its writer if the writer is a Flusher.
+type Flusher interface {
+
+	// Flush writes all buffered data to the underlying destination.
+	Flush() error
+}
+
+// FlushResult is the summary of flushing, including how many logs and bytes were flushed.
+// It's useful to tell whether the pipeline was backed up.
+type FlushResult struct {
+
+	// Entries is the number of logs flushed.
+	Entries int
+
+	// Bytes is the number of bytes flushed.
+	Bytes int
+}
+
+// add adds another result to fr.
+func (fr *FlushResult) add(another FlushResult) {
+	fr.Entries += another.Entries
+	fr.Bytes += another.Bytes
+}
+
+// ResultFlusher is a Flusher which can report how many logs and bytes were flushed.
+// Handlers which can't report the result should only implement Flusher.
+type ResultFlusher interface {
+	Flusher
+
+	// FlushWithResult is the same as Flush but returns the result of flushing.
+	FlushWithResult() (FlushResult, error)
+}
+
+// ErrorReportingHandler is a handler which can report the error of handling a log.
+// Handle returns a bool to decide if handlers after it should be used, so it can't tell
+// if a log is handled successfully. Wrappers like NewFailoverHandler use this interface to know it.
+type ErrorReportingHandler interface {
+	Handler
+
+	// HandleWithError handles log and returns the error happening in handling.
+	HandleWithError(log *Log) error
+}
+
 // RegisterHandler registers your handler to logit so that you can use them in config file.
 // Return an error if the name is existed, and you should change another name for your handler.
 // Notice that newHandler has a parameter called params, which will be injected into newHandler
@@ -80,7 +149,17 @@ type Handler interface {
 //        } will be injected to params.
 //
 // So you can use these params written in config file.
+//
+// If newHandler panics, the panic will be returned as an error by NewLoggerFromE.
 func RegisterHandler(name string, newHandler func(params map[string]interface{}) Handler) error {
+	return registerHandlerE(name, func(params map[string]interface{}) (Handler, error) {
+		return newHandler(params), nil
+	})
+}
+
+// registerHandlerE is the same as RegisterHandler except newHandler returns an error instead of panicking.
+// The built-in handlers are registered by it.
+func registerHandlerE(name string, newHandler func(params map[string]interface{}) (Handler, error)) error {
 	mutexOfHandlers.Lock()
 	defer mutexOfHandlers.Unlock()
 	if _, ok := handlers[name]; ok {
@@ -90,22 +169,75 @@ func RegisterHandler(name string, newHandler func(params map[string]interface{})
 	return nil
 }
 
+// RegisteredHandlers returns the names of all handlers registered, sorted by name.
+// The built-in handlers like "console" and "file" are included, too.
+func RegisteredHandlers() []string {
+	mutexOfHandlers.RLock()
+	defer mutexOfHandlers.RUnlock()
+
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DeregisterHandler removes the handler registered with name.
+// Nothing will happen if the name doesn't exist.
+// It's useful in testing, so registrations won't leak across cases.
+func DeregisterHandler(name string) {
+	mutexOfHandlers.Lock()
+	defer mutexOfHandlers.Unlock()
+	delete(handlers, name)
+}
+
 // handlerOf returns handler whose name is given name and params.
 // Different handler may have different params, so what params should
 // be injected into newHandler is dependent to specific handler.
-// Notice that we use tips+exit mechanism to check the name.
-// This is a more convenient way to use handlers (we think).
-// so if the handler doesn't exist, a tip will be printed and
-// the program will exit with status code 1.
-func handlerOf(name string, params map[string]interface{}) Handler {
+// Return an error wrapping InvalidConfigError if the handler doesn't exist, and the error
+// of newHandler if it fails or panics.
+// If params has a "level" param, the handler will be wrapped by a min level handler,
+// so it only handles logs not lower than this level. See NewMinLevelHandler.
+// If params has a "fields" param, the handler will be wrapped by a fields handler,
+// so logs it handles carry these static fields. See NewFieldsHandler.
+func handlerOf(name string, params map[string]interface{}) (handler Handler, err error) {
 	mutexOfHandlers.RLock()
-	defer mutexOfHandlers.RUnlock()
 	newHandler, ok := handlers[name]
+	mutexOfHandlers.RUnlock()
 	if !ok {
-		fmt.Fprintf(os.Stderr, "Error: The handler \"%s\" doesn't exist! Please change it to another handler.\n", name)
-		os.Exit(1)
+		return nil, fmt.Errorf("%w: handler \"%s\" doesn't exist", InvalidConfigError, name)
 	}
-	return newHandler(params)
+
+	var minLevel Level
+	level, hasLevel := params[levelParam].(string)
+	hasLevel = hasLevel && strings.TrimSpace(level) != ""
+	if hasLevel {
+		if minLevel, err = parseLevel(level); err != nil {
+			return nil, err
+		}
+	}
+
+	// 用户注册的日志处理器可能会 panic，转换成错误返回
+	defer func() {
+		if r := recover(); r != nil {
+			handler, err = nil, fmt.Errorf("creating handler \"%s\" panicked: %v", name, r)
+		}
+	}()
+
+	handler, err = newHandler(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields, ok := params[fieldsParam].(map[string]interface{}); ok && len(fields) > 0 {
+		handler = NewFieldsHandler(fields, handler)
+	}
+
+	if hasLevel {
+		return NewMinLevelHandler(minLevel, handler), nil
+	}
+	return handler, nil
 }
 
 // ================================= standard handler =================================
@@ -118,22 +250,206 @@ type standardHandler struct {
 	writer     io.Writer
 	encoder    Encoder
 	timeFormat string
+
+	// mu serializes writings, so lines written concurrently never interleave.
+	mu *sync.Mutex
 }
 
 // NewStandardHandler returns a standardHandler holder with given writer and encoder.
 // Encoder is how to encode a log to bytes, and we provide TextEncoder and JsonEncoder.
+// Each log will be written in one Write call, and writings are serialized by the handler,
+// so lines never interleave even if the writer isn't safe for concurrency, like bytes.Buffer.
 // See logit.Encoder, logit.TextEncoder and logit.JsonEncoder.
 func NewStandardHandler(writer io.Writer, encoder Encoder, timeFormat string) Handler {
 	return &standardHandler{
 		writer:     writer,
 		encoder:    encoder,
 		timeFormat: timeFormat,
+		mu:         &sync.Mutex{},
 	}
 }
 
 // Handle will encode log and write log by internal writer.
+// If writing failed, the error will be reported to the error callback of logger.
 // Return true so that handlers after it will be used.
 func (sh *standardHandler) Handle(log *Log) bool {
-	sh.writer.Write(sh.encoder.Encode(log, sh.timeFormat))
+	err := sh.HandleWithError(log)
+	if err != nil && log.logger != nil {
+		log.logger.reportError(err)
+	}
 	return true
 }
+
+// HandleWithError will encode log and write log by internal writer.
+// If the writer accepts a part of the encoded log only, the rest will be written again until
+// all of it is written or an error happens.
+// Return the error of writing, which won't be reported to the error callback of logger.
+func (sh *standardHandler) HandleWithError(log *Log) error {
+
+	if log.profiler != nil {
+		return sh.handleWithProfiling(log)
+	}
+
+	// 编码不需要加锁，只有写入需要串行化
+	encoded := sh.encoder.Encode(log, sh.timeFormat)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return writeFull(sh.writer, encoded)
+}
+
+// handleWithProfiling is the same as HandleWithError except the latencies of encoding and writing
+// will be recorded by the profiler of log.
+func (sh *standardHandler) handleWithProfiling(log *Log) error {
+	begin := time.Now()
+	encoded := sh.encoder.Encode(log, sh.timeFormat)
+	log.profiler.recordEncode(begin)
+
+	begin = time.Now()
+	sh.mu.Lock()
+	err := writeFull(sh.writer, encoded)
+	sh.mu.Unlock()
+	log.profiler.recordWrite(begin)
+	return err
+}
+
+// writeFull writes all of p to writer, and keeps writing the rest if writer accepts a part of p only,
+// so a line is delivered as a whole. Return io.ErrShortWrite if writer writes nothing without an error.
+func writeFull(writer io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := writer.Write(p)
+		if err != nil {
+			return err
+		}
+
+		// 没有写入任何数据也没有返回错误，继续写入可能会死循环
+		if n <= 0 {
+			return io.ErrShortWrite
+		}
+
+		if n > len(p) {
+			n = len(p)
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+// Flush flushes the internal writer if it is a Flusher.
+// Return nil if the internal writer doesn't buffer anything.
+func (sh *standardHandler) Flush() error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if flusher, ok := sh.writer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the internal writer if it is an io.Closer.
+// Notice that os.Stdout and os.Stderr will never be closed.
+func (sh *standardHandler) Close() error {
+	err := sh.Flush()
+	if sh.writer == os.Stdout || sh.writer == os.Stderr {
+		return err
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if closer, ok := sh.writer.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil {
+			return closeErr
+		}
+	}
+	return err
+}
+
+// multiError is an error combining several errors, like errors.Join in newer Go.
+type multiError struct {
+	errs []error
+}
+
+// joinErrors returns an error combining all non-nil errs.
+// Return nil if all errs are nil, and the error itself if there is only one non-nil error.
+// The errors combined by joinErrors before will be flattened, so the result is always one level.
+func joinErrors(errs ...error) error {
+	var joined []error
+	for _, err := range errs {
+		if me, ok := err.(*multiError); ok {
+			joined = append(joined, me.errs...)
+		} else if err != nil {
+			joined = append(joined, err)
+		}
+	}
+
+	switch len(joined) {
+	case 0:
+		return nil
+	case 1:
+		return joined[0]
+	default:
+		return &multiError{errs: joined}
+	}
+}
+
+// Error returns all messages of errors, separated by newlines.
+func (me *multiError) Error() string {
+	builder := strings.Builder{}
+	for i, err := range me.errs {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(err.Error())
+	}
+	return builder.String()
+}
+
+// Unwrap returns all errors combined, so errors.Is and errors.As can check each of them.
+func (me *multiError) Unwrap() []error {
+	return me.errs
+}
+
+// closeHandlers closes all handlers which are io.Closers.
+// All handlers will be closed even if some of them failed, and all errors will be combined.
+func closeHandlers(handlers []Handler) error {
+	var errs []error
+	for _, handler := range handlers {
+		closer, ok := handler.(io.Closer)
+		if !ok {
+			continue
+		}
+
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// flushHandlers flushes all handlers which are Flushers, and returns the sum of results.
+// Handlers which are not ResultFlushers contribute zero to the result.
+// All handlers will be flushed even if some of them failed, and all errors will be combined.
+func flushHandlers(handlers []Handler) (FlushResult, error) {
+	result := FlushResult{}
+	var errs []error
+	for _, handler := range handlers {
+		var err error
+		switch flusher := handler.(type) {
+		case ResultFlusher:
+			var r FlushResult
+			r, err = flusher.FlushWithResult()
+			result.add(r)
+		case Flusher:
+			err = flusher.Flush()
+		default:
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, joinErrors(errs...)
+}
@@ -27,6 +27,11 @@ import (
 )
 
 func init() {
+	registerExtensionHandlers()
+}
+
+// registerExtensionHandlers registers console, file and rolling handlers.
+func registerExtensionHandlers() {
 	registerConsoleHandler()
 	registerFileHandler()
 	registerDurationRollingHandler()
@@ -65,10 +70,23 @@ func init() {
 //             }
 //         }
 //
+// Want every line prepended with a label? Try this:
+//
+//         "handlers": {
+//             "console": {
+//                 "label": "myHandler: "
+//             }
+//         }
+//
 func registerConsoleHandler() {
-	RegisterHandler("console", func(params map[string]interface{}) Handler {
-		encoder, timeFormat := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
-		return NewConsoleHandler(encoder, timeFormat)
+	registerHandlerE("console", func(params map[string]interface{}) (Handler, error) {
+		encoder, timeFormat, err := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
+		if err != nil {
+			return nil, err
+		}
+
+		label, _ := params["label"].(string)
+		return NewConsoleHandlerLabeled(label, encoder, timeFormat), nil
 	})
 }
 
@@ -107,10 +125,13 @@ func registerConsoleHandler() {
 //         }
 //
 func registerFileHandler() {
-	RegisterHandler("file", func(params map[string]interface{}) Handler {
+	registerHandlerE("file", func(params map[string]interface{}) (Handler, error) {
 		path := pathOf(params, "./logit-"+strconv.FormatInt(time.Now().Unix(), 10)+files.SuffixOfLogFile)
-		encoder, timeFormat := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
-		return NewFileHandler(path, encoder, timeFormat)
+		encoder, timeFormat, err := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileHandlerE(path, encoder, timeFormat)
 	})
 }
 
@@ -155,11 +176,14 @@ func registerFileHandler() {
 //         }
 //
 func registerDurationRollingHandler() {
-	RegisterHandler("duration", func(params map[string]interface{}) Handler {
+	registerHandlerE("duration", func(params map[string]interface{}) (Handler, error) {
 		// 滚动的时间间隔，单位是秒，默认是 1 天
 		limit, directory := limitAndDirectoryOf(params, 24*60*60, "./")
-		encoder, timeFormat := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
-		return NewDurationRollingHandler(directory, time.Duration(limit)*time.Second, encoder, timeFormat)
+		encoder, timeFormat, err := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
+		if err != nil {
+			return nil, err
+		}
+		return NewDurationRollingHandlerE(directory, time.Duration(limit)*time.Second, encoder, timeFormat)
 	})
 }
 
@@ -203,11 +227,14 @@ func registerDurationRollingHandler() {
 //         }
 //
 func registerSizeRollingHandler() {
-	RegisterHandler("size", func(params map[string]interface{}) Handler {
+	registerHandlerE("size", func(params map[string]interface{}) (Handler, error) {
 		// 滚动的文件大小，单位是 MB，默认是 64 MB
 		limit, directory := limitAndDirectoryOf(params, 64, "./")
-		encoder, timeFormat := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
-		return NewSizeRollingHandler(directory, int64(limit)*files.MB, encoder, timeFormat)
+		encoder, timeFormat, err := encoderAndTimeFormatOf(params, TextEncoder(), DefaultTimeFormat)
+		if err != nil {
+			return nil, err
+		}
+		return NewSizeRollingHandlerE(directory, int64(limit)*files.MB, encoder, timeFormat)
 	})
 }
 
@@ -215,25 +242,26 @@ func registerSizeRollingHandler() {
 
 // encoderAndTimeFormatOf returns encoder and time format in this params.
 // defaultEncoder and defaultTimeFormat will be used if you don't set to params.
-func encoderAndTimeFormatOf(params map[string]interface{}, defaultEncoder Encoder, defaultTimeFormat string) (Encoder, string) {
+// Return an error if the encoder doesn't exist.
+func encoderAndTimeFormatOf(params map[string]interface{}, defaultEncoder Encoder, defaultTimeFormat string) (Encoder, string, error) {
 
 	// 日志编码器参数
 	encoder := defaultEncoder
 	if encoderName, ok := params["encoder"]; ok && strings.TrimSpace(encoderName.(string)) != "" {
-		encoder = encoderOf(encoderName.(string))
+		var err error
+		if encoder, err = encoderOf(encoderName.(string), params); err != nil {
+			return nil, "", err
+		}
 	}
 
 	// 时间格式化参数
 	timeFormat := defaultTimeFormat
 	if format, ok := params["timeFormat"]; ok && strings.TrimSpace(format.(string)) != "" {
-		timeFormat = format.(string)
-		// 如果参数是 unix，则直接使用空字符串
-		if timeFormat == "unix" {
-			timeFormat = ""
-		}
+		// 参数可以是预设的名字，比如 unix 和 rfc3339，也可以是时间格式本身
+		timeFormat = TimeFormat(format.(string))
 	}
 
-	return encoder, timeFormat
+	return encoder, timeFormat, nil
 }
 
 // pathOf returns path in this params.
@@ -277,16 +305,46 @@ func NewConsoleHandler(encoder Encoder, timeFormat string) Handler {
 	return NewStandardHandler(os.Stdout, encoder, timeFormat)
 }
 
+// NewConsoleHandlerLabeled is the same as NewConsoleHandler except label will be prepended to
+// every line, like "myHandler: ", so you can tell the output of several console handlers apart.
+func NewConsoleHandlerLabeled(label string, encoder Encoder, timeFormat string) Handler {
+	return NewConsoleHandler(labeledEncoder(label, encoder), timeFormat)
+}
+
+// labeledEncoder returns an encoder prepending label to the output of encoder.
+func labeledEncoder(label string, encoder Encoder) Encoder {
+	if label == "" {
+		return encoder
+	}
+
+	return NewPipelineEncoder(encoder, func(encoded []byte) []byte {
+		labeled := make([]byte, 0, len(label)+len(encoded))
+		labeled = append(labeled, label...)
+		return append(labeled, encoded...)
+	})
+}
+
 // NewFileHandler returns a handler which writes logs to a file.
 // You can point a path (the path of log file) to be used to write logs.
 // If the file of this path doesn't exist, a new file will be created.
+// It panics if failed, and see NewFileHandlerE if you want an error instead.
 // See logit.Encoder, logit.TextEncoder, logit.JsonEncoder.
 func NewFileHandler(path string, encoder Encoder, timeFormat string) Handler {
-	file, err := files.CreateFileOf(path)
+	handler, err := NewFileHandlerE(path, encoder, timeFormat)
 	if err != nil {
 		panic(err)
 	}
-	return NewStandardHandler(file, encoder, timeFormat)
+	return handler
+}
+
+// NewFileHandlerE is the same as NewFileHandler except it returns an error instead of panicking.
+// It's friendly for library use, so you can handle the failure gracefully at startup.
+func NewFileHandlerE(path string, encoder Encoder, timeFormat string) (Handler, error) {
+	file, err := files.NewPlainFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardHandler(file, encoder, timeFormat), nil
 }
 
 // NewDurationRollingHandler returns a handler which uses
@@ -296,8 +354,21 @@ func NewFileHandler(path string, encoder Encoder, timeFormat string) Handler {
 // See logit.Encoder, logit.TextEncoder, logit.JsonEncoder.
 // See files.NewDurationRollingFile.
 func NewDurationRollingHandler(directory string, limit time.Duration, encoder Encoder, timeFormat string) Handler {
-	file := files.NewDurationRollingFile(directory, limit)
-	return NewStandardHandler(file, encoder, timeFormat)
+	handler, err := NewDurationRollingHandlerE(directory, limit, encoder, timeFormat)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+// NewDurationRollingHandlerE is the same as NewDurationRollingHandler except it returns an error
+// instead of panicking. See files.NewDurationRollingFileE.
+func NewDurationRollingHandlerE(directory string, limit time.Duration, encoder Encoder, timeFormat string) (Handler, error) {
+	file, err := files.NewDurationRollingFileE(directory, limit)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardHandler(file, encoder, timeFormat), nil
 }
 
 // NewSizeRollingHandler returns a handler which uses
@@ -307,6 +378,19 @@ func NewDurationRollingHandler(directory string, limit time.Duration, encoder En
 // See logit.Encoder, logit.TextEncoder, logit.JsonEncoder.
 // See files.NewSizeRollingFile.
 func NewSizeRollingHandler(directory string, limit int64, encoder Encoder, timeFormat string) Handler {
-	file := files.NewSizeRollingFile(directory, limit)
-	return NewStandardHandler(file, encoder, timeFormat)
+	handler, err := NewSizeRollingHandlerE(directory, limit, encoder, timeFormat)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+// NewSizeRollingHandlerE is the same as NewSizeRollingHandler except it returns an error
+// instead of panicking. See files.NewSizeRollingFileE.
+func NewSizeRollingHandlerE(directory string, limit int64, encoder Encoder, timeFormat string) (Handler, error) {
+	file, err := files.NewSizeRollingFileE(directory, limit)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardHandler(file, encoder, timeFormat), nil
 }
@@ -19,9 +19,11 @@
 package logit
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,3 +68,65 @@ func TestNewSizeRollingHandler(t *testing.T) {
 		logger.Error("error...")
 	}
 }
+
+// 测试创建日志处理器失败时返回错误而不是 panic
+func TestNewHandlersE(t *testing.T) {
+	file, err := ioutil.TempFile("", "TestNewHandlersE_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	// 使用普通文件作为目录，路径是无法写入的
+	if _, err := NewFileHandlerE(filepath.Join(file.Name(), "test.log"), TextEncoder(), ""); err == nil {
+		t.Fatal("无法写入的路径没有返回错误！")
+	}
+
+	if _, err := NewDurationRollingHandlerE(file.Name(), time.Second, TextEncoder(), ""); err == nil {
+		t.Fatal("无法写入的目录没有返回错误！")
+	}
+
+	if _, err := NewSizeRollingHandlerE(file.Name(), 64*files.KB, TextEncoder(), ""); err == nil {
+		t.Fatal("无法写入的目录没有返回错误！")
+	}
+
+	if _, err := NewSizeRollingHandlerE(os.TempDir(), files.KB, TextEncoder(), ""); err == nil {
+		t.Fatal("文件大小限制太小没有返回错误！")
+	}
+
+	handler, err := NewSizeRollingHandlerE(os.TempDir(), 64*files.KB, TextEncoder(), "")
+	if err != nil || handler == nil {
+		t.Fatalf("创建日志处理器出现错误！%v", err)
+	}
+}
+
+// 测试带标签的控制台日志处理器只给自己输出的每一行加上标签
+func TestNewConsoleHandlerLabeled(t *testing.T) {
+	output := captureStdout(t, func() {
+		logger := NewLogger(DebugLevel, NewConsoleHandler(TextEncoder(), ""), NewConsoleHandlerLabeled("myHandler: ", TextEncoder(), ""))
+		logger.Info("first")
+		logger.Error("second")
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("日志的行数不正确！%s", output)
+	}
+
+	for i, line := range lines {
+		labeled := strings.HasPrefix(line, "myHandler: [")
+		if labeled != (i%2 == 1) {
+			t.Fatalf("第 %d 行日志的标签不正确！%s", i+1, line)
+		}
+	}
+
+	// 配置文件中的标签
+	output = captureStdout(t, func() {
+		NewLoggerFrom(strings.NewReader(`"handlers": {"console": {"label": "fromConfig: "}}`)).Info("config")
+	})
+
+	if !strings.HasPrefix(output, "fromConfig: [info]") {
+		t.Fatalf("配置文件中的标签不正确！%s", output)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:03:45
+
+package logit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	// HandlerPanicError is an error happening on a handler panicking in handling a log.
+	// The panic is recovered and the handlers after it still handle the log. See Logger.SetRecoverHandlers.
+	HandlerPanicError = errors.New("a handler panicked in handling a log")
+)
+
+// SetRecoverHandlers sets whether to recover the panics of handlers, so a panicking handler won't
+// take down the logging call and your application. If recover is true, the panic will be reported
+// to the error callback as a HandlerPanicError carrying the type of handler, or printed to stderr
+// if there is no error callback, and the handlers after it will still handle the log.
+// Default is true.
+func (l *Logger) SetRecoverHandlers(recover bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recoverHandlers = recover
+}
+
+// handleWithRecovery handles log with handler, and recovers the panic of handler if it happens.
+// A panicking handler is regarded as handled, so the handlers after it can still handle log.
+func (l *Logger) handleWithRecovery(handler Handler, log *Log) (handled bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.reportHandlerPanic(fmt.Errorf("%w: %T: %v", HandlerPanicError, handler, r))
+			handled = true
+		}
+	}()
+	return handler.Handle(log)
+}
+
+// reportHandlerPanic reports err to the error callback of l, or prints it to stderr if there is no callback.
+func (l *Logger) reportHandlerPanic(err error) {
+	l.mu.RLock()
+	callback := l.errorCallback
+	l.mu.RUnlock()
+
+	if callback != nil {
+		callback(err)
+		return
+	}
+
+	// 没有错误回调的时候，直接输出到标准错误，不能让 panic 悄无声息地消失
+	fmt.Fprintln(os.Stderr, err)
+}
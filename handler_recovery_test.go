@@ -0,0 +1,75 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:03:45
+
+package logit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// panicHandler is a handler panicking in handling every log.
+type panicHandler struct{}
+
+func (panicHandler) Handle(log *Log) bool {
+	panic("handler is broken")
+}
+
+// 测试日志处理器 panic 之后，后面的日志处理器依然会处理日志
+func TestLoggerSetRecoverHandlers(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, panicHandler{}, handler)
+
+	var errs []error
+	logger.SetErrorCallback(func(err error) {
+		errs = append(errs, err)
+	})
+
+	logger.Info("first")
+	logger.Error("second")
+
+	if len(handler.logs) != 2 || handler.logs[0]["msg"] != "first" || handler.logs[1]["msg"] != "second" {
+		t.Fatalf("panic 之后的日志处理器没有处理日志！%v", handler.logs)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("错误回调的次数不正确！%d", len(errs))
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, HandlerPanicError) || !strings.Contains(err.Error(), "logit.panicHandler") ||
+			!strings.Contains(err.Error(), "handler is broken") {
+			t.Fatalf("错误回调收到的错误不正确！%v", err)
+		}
+	}
+
+	// 关闭之后 panic 会直接抛出
+	logger.SetRecoverHandlers(false)
+	defer func() {
+		if r := recover(); r != "handler is broken" {
+			t.Fatalf("关闭之后 panic 没有被抛出！%v", r)
+		}
+
+		if len(handler.logs) != 2 {
+			t.Fatalf("关闭之后 panic 的日志处理器后面还在处理日志！%d", len(handler.logs))
+		}
+	}()
+
+	logger.Info("third")
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/17 20:36:12
+
+package logit
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 检查 names 中是否包含 name
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 测试注册、列出和注销日志处理器
+func TestRegisteredHandlersAndDeregisterHandler(t *testing.T) {
+	names := RegisteredHandlers()
+	for _, name := range []string{"console", "file", "duration", "size"} {
+		if !containsName(names, name) {
+			t.Fatalf("内置的日志处理器 %s 不在注册列表中！%v", name, names)
+		}
+	}
+
+	const name = "TestRegisteredHandlersAndDeregisterHandler"
+	err := RegisterHandler(name, func(params map[string]interface{}) Handler {
+		return &myHandler{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterHandler(name)
+
+	if !containsName(RegisteredHandlers(), name) {
+		t.Fatalf("注册的日志处理器 %s 不在注册列表中！", name)
+	}
+
+	DeregisterHandler(name)
+	if containsName(RegisteredHandlers(), name) {
+		t.Fatalf("注销的日志处理器 %s 还在注册列表中！", name)
+	}
+
+	// 注销之后可以使用同样的名字重新注册
+	err = RegisterHandler(name, func(params map[string]interface{}) Handler {
+		return &myHandler{}
+	})
+	if err != nil {
+		t.Fatalf("注销之后不能重新注册日志处理器！%v", err)
+	}
+}
+
+// 每次只写入一个字节的 writer，模拟不是原子写入的 writer
+type byteByByteWriter struct {
+	buffer bytes.Buffer
+}
+
+func (bbw *byteByByteWriter) Write(p []byte) (n int, err error) {
+	for _, b := range p {
+		bbw.buffer.WriteByte(b)
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+// 每次最多只写入 n 个字节的 writer，模拟部分写入但不返回错误的 writer
+type partialWriter struct {
+	buffer bytes.Buffer
+	n      int
+	zero   bool
+}
+
+func (pw *partialWriter) Write(p []byte) (n int, err error) {
+	if pw.zero {
+		return 0, nil
+	}
+
+	if len(p) > pw.n {
+		p = p[:pw.n]
+	}
+	return pw.buffer.Write(p)
+}
+
+// 测试部分写入时会继续写入剩下的数据
+func TestStandardHandlerPartialWrites(t *testing.T) {
+	writer := &partialWriter{n: 3}
+	logger := NewLogger(DebugLevel, NewStandardHandler(writer, JsonEncoder(), ""))
+
+	var reported error
+	logger.SetErrorCallback(func(err error) {
+		reported = err
+	})
+
+	logger.Info("partial writes")
+	logger.WithFields(map[string]interface{}{"id": 123}).Error("whole line")
+
+	lines := strings.Split(strings.TrimSuffix(writer.buffer.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("日志的行数不正确！%s", writer.buffer.String())
+	}
+
+	for _, line := range lines {
+		if _, err := DecodeJsonLog([]byte(line)); err != nil {
+			t.Fatalf("日志行不完整！%s", line)
+		}
+	}
+
+	// 一直写不进去数据的时候返回错误，而不是死循环
+	writer.zero = true
+	logger.Info("nothing written")
+	if reported != io.ErrShortWrite {
+		t.Fatalf("没有写入数据时应该回调 io.ErrShortWrite！%v", reported)
+	}
+}
+
+// 测试并发写入时每一行日志都是完整的
+func TestStandardHandlerConcurrentWrites(t *testing.T) {
+	writer := &byteByByteWriter{}
+	logger := NewLogger(DebugLevel, NewStandardHandler(writer, JsonEncoder(), ""))
+
+	group := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		group.Add(1)
+		go func(goroutine int) {
+			defer group.Done()
+			for j := 0; j < 20; j++ {
+				logger.Info(strings.Repeat(strconv.Itoa(goroutine%10), 100))
+			}
+		}(i)
+	}
+	group.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(writer.buffer.String(), "\n"), "\n")
+	if len(lines) != 20*20 {
+		t.Fatalf("日志的行数不正确！%d", len(lines))
+	}
+
+	// 每一行的 msg 都必须是同一个数字重复 100 次，否则说明行之间交错了
+	for _, line := range lines {
+		log, err := DecodeJsonLog([]byte(line))
+		if err != nil {
+			t.Fatalf("日志行被破坏了！%s", line)
+		}
+
+		msg := log.Msg()
+		if len(msg) != 100 || strings.Count(msg, msg[:1]) != 100 {
+			t.Fatalf("日志行之间出现了交错！%s", line)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 16:20:45
+
+package logit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// HeartbeatMsg is the msg of heartbeat logs. See Logger.StartHeartbeat.
+	HeartbeatMsg = "heartbeat"
+
+	// UptimeKey is the key of the field carrying the time since the heartbeat started.
+	UptimeKey = "uptime"
+
+	// The keys of the fields carrying the counts of logs in each level.
+	DebugCountKey = "debug_count"
+	InfoCountKey  = "info_count"
+	WarnCountKey  = "warn_count"
+	ErrorCountKey = "error_count"
+)
+
+// levelCounts counts logs in each level, and it's shared by child loggers.
+type levelCounts struct {
+	counts [ErrorLevel + 1]uint64
+}
+
+// inc increases the count of level.
+func (lc *levelCounts) inc(level Level) {
+	if level <= ErrorLevel {
+		atomic.AddUint64(&lc.counts[level], 1)
+	}
+}
+
+// get returns the count of level.
+func (lc *levelCounts) get(level Level) uint64 {
+	if level <= ErrorLevel {
+		return atomic.LoadUint64(&lc.counts[level])
+	}
+	return 0
+}
+
+// StartHeartbeat logs a heartbeat in level every interval, so the absence of heartbeats signals
+// a hung process. The msg of heartbeats is HeartbeatMsg, and they carry the uptime since starting
+// and the counts of logs in each level logged by l and its child loggers, including heartbeats.
+// It returns a function to stop the heartbeat, and it's safe to call it more than once.
+func (l *Logger) StartHeartbeat(interval time.Duration, level Level) (stop func()) {
+	ticker := time.NewTicker(interval)
+	stop = l.startHeartbeat(ticker.C, time.Now(), level)
+	return func() {
+		stop()
+		ticker.Stop()
+	}
+}
+
+// startHeartbeat logs a heartbeat in level every time ticks ticks, and the uptime is the time ticked since begin.
+// It's the same as StartHeartbeat except the clock is replaceable, which is useful in testing.
+func (l *Logger) startHeartbeat(ticks <-chan time.Time, begin time.Time, level Level) (stop func()) {
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case tick := <-ticks:
+				l.log(callDepth, level, HeartbeatMsg, []Field{
+					{Key: UptimeKey, Value: tick.Sub(begin)},
+					{Key: DebugCountKey, Value: l.counts.get(DebugLevel)},
+					{Key: InfoCountKey, Value: l.counts.get(InfoLevel)},
+					{Key: WarnCountKey, Value: l.counts.get(WarnLevel)},
+					{Key: ErrorCountKey, Value: l.counts.get(ErrorLevel)},
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 16:36:02
+
+package logit
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试每个时间间隔输出一条心跳日志，并且停止之后不再输出
+func TestLoggerStartHeartbeat(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(InfoLevel, handler)
+
+	// 使用一个假的时钟，每次发送的时间就是心跳的时间
+	now := time.Unix(1598774400, 0)
+	ticks := make(chan time.Time)
+	stop := logger.startHeartbeat(ticks, now, InfoLevel)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+
+	for i := 1; i <= 3; i++ {
+		ticks <- now.Add(time.Duration(i) * time.Minute)
+	}
+
+	// 多次停止也是安全的
+	stop()
+	stop()
+
+	// 停止之后不会再输出心跳
+	select {
+	case ticks <- now.Add(time.Hour):
+		t.Fatal("停止之后还在输出心跳日志！")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	logs := handler.logs
+	if len(logs) != 5 {
+		t.Fatalf("日志条数不正确！%d", len(logs))
+	}
+
+	for i, log := range logs[2:] {
+		expects := map[string]interface{}{
+			"msg":         HeartbeatMsg,
+			UptimeKey:     time.Duration(i+1) * time.Minute,
+			DebugCountKey: uint64(0),
+			InfoCountKey:  uint64(1 + i),
+			WarnCountKey:  uint64(0),
+			ErrorCountKey: uint64(1),
+		}
+
+		for key, value := range expects {
+			if log[key] != value {
+				t.Fatalf("第 %d 条心跳日志的 %s 不正确！%v", i+1, key, log)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/28 20:12:36
+
+package logit
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// jsonArrayHandler is a handler writing logs as elements of a Json array.
+type jsonArrayHandler struct {
+
+	// writer is the writer which the Json array will be written to.
+	writer io.WriteCloser
+
+	// timeFormat is the format of time in logs.
+	timeFormat string
+
+	// started is a flag to check if the open bracket has been written.
+	started bool
+
+	// closed is a flag to check if the close bracket has been written.
+	closed bool
+
+	// mu is for writing elements in order.
+	mu *sync.Mutex
+}
+
+// NewJsonArrayHandler returns a handler writing logs to writer as elements of a Json array,
+// which is useful for viewers reading a continuous Json stream. The open bracket will be written
+// before the first log, and the close bracket will be written when closing, so remember to call
+// Logger.Close or the output won't be valid Json. An empty stream will be "[]" after closing.
+func NewJsonArrayHandler(writer io.WriteCloser, timeFormat string) Handler {
+	return &jsonArrayHandler{
+		writer:     writer,
+		timeFormat: timeFormat,
+		mu:         &sync.Mutex{},
+	}
+}
+
+// Handle writes log as an element of the Json array.
+// If writing failed, the error will be reported to the error callback of logger.
+// Return true so that handlers after it will be used.
+func (jah *jsonArrayHandler) Handle(log *Log) bool {
+	err := jah.HandleWithError(log)
+	if err != nil && log.logger != nil {
+		log.logger.reportError(err)
+	}
+	return true
+}
+
+// HandleWithError writes log as an element of the Json array and returns the error of writing.
+// Logs handled after closing will be ignored.
+func (jah *jsonArrayHandler) HandleWithError(log *Log) error {
+	encoded := bytes.TrimSuffix(JsonEncoder().Encode(log, jah.timeFormat), []byte("\n"))
+
+	jah.mu.Lock()
+	defer jah.mu.Unlock()
+
+	if jah.closed {
+		return nil
+	}
+
+	// 第一个元素前面是左括号，后面的元素前面是逗号，每个元素占一行方便阅读
+	prefix := ",\n"
+	if !jah.started {
+		prefix = "[\n"
+		jah.started = true
+	}
+
+	_, err := jah.writer.Write(append([]byte(prefix), encoded...))
+	return err
+}
+
+// Close writes the close bracket and closes the writer.
+// It's safe to call it more than once.
+func (jah *jsonArrayHandler) Close() error {
+	jah.mu.Lock()
+	defer jah.mu.Unlock()
+
+	if jah.closed {
+		return nil
+	}
+	jah.closed = true
+
+	closing := "\n]\n"
+	if !jah.started {
+		closing = "[]\n"
+	}
+
+	if _, err := jah.writer.Write([]byte(closing)); err != nil {
+		jah.writer.Close()
+		return err
+	}
+	return jah.writer.Close()
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/28 20:47:19
+
+package logit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// 可以关闭的 buffer
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (cb *closingBuffer) Close() error {
+	cb.closed = true
+	return nil
+}
+
+// 测试以 Json 数组的形式输出日志
+func TestJsonArrayHandler(t *testing.T) {
+	buffer := &closingBuffer{}
+	logger := NewLogger(DebugLevel, NewJsonArrayHandler(buffer, DefaultTimeFormat))
+	logger.InfoWith(Fields{{Key: "id", Value: 1}}, "first")
+	logger.Warn("second")
+	logger.Error("third")
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs []map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &logs); err != nil {
+		t.Fatalf("输出的不是合法的 Json！%v\n%s", err, buffer.String())
+	}
+
+	if len(logs) != 3 || logs[0]["msg"] != "first" || logs[0]["id"] != float64(1) || logs[2]["level"] != "error" {
+		t.Fatalf("输出的 Json 数组不正确！%v", logs)
+	}
+
+	if !buffer.closed {
+		t.Fatal("关闭日志处理器时没有关闭 writer！")
+	}
+
+	// 关闭之后的日志会被忽略，重复关闭也不能破坏输出
+	logger.Info("after closing")
+	logger.Close()
+	if err := json.Unmarshal(buffer.Bytes(), &logs); err != nil || len(logs) != 3 {
+		t.Fatalf("关闭之后输出被破坏了！%v\n%s", err, buffer.String())
+	}
+}
+
+// 测试没有日志时输出空的 Json 数组
+func TestJsonArrayHandlerEmpty(t *testing.T) {
+	buffer := &closingBuffer{}
+	handler := NewJsonArrayHandler(buffer, DefaultTimeFormat)
+	handler.(*jsonArrayHandler).Close()
+
+	var logs []map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &logs); err != nil || logs == nil || len(logs) != 0 {
+		t.Fatalf("空的输出不是空的 Json 数组！%v %s", err, buffer.String())
+	}
+}
@@ -21,7 +21,6 @@ package logit
 import (
 	"fmt"
 	"math"
-	"os"
 )
 
 // Level is the type representation of the logger level.
@@ -49,17 +48,14 @@ var (
 )
 
 // parseLevel parses level and returns the Level of it.
-// If the level doesn't exist, a tip will be printed and
-// the program will exit with status code 3.
-func parseLevel(level string) Level {
+// Return an error wrapping InvalidConfigError if the level doesn't exist.
+func parseLevel(level string) (Level, error) {
 	for k, v := range levels {
 		if v == level {
-			return k
+			return k, nil
 		}
 	}
-	fmt.Fprintf(os.Stderr, "Error: Level \"%s\" doesn't exist! Be sure your level is one of them: debug, info, warn, error, off\n", level)
-	os.Exit(3)
-	return OffLevel
+	return OffLevel, fmt.Errorf("%w: level \"%s\" doesn't exist, be sure your level is one of them: debug, info, warn, error, off", InvalidConfigError, level)
 }
 
 // String returns the name of Level ll.
@@ -67,3 +63,44 @@ func parseLevel(level string) Level {
 func (ll Level) String() string {
 	return levels[ll]
 }
+
+// LevelFromSeverity returns the Level of severity, which is a syslog number.
+// It's useful for bridging logs from other systems, such as syslog and GELF.
+// The mapping table is:
+//
+//     severity                     level
+//     0 (emergency) ~ 3 (error)    ErrorLevel
+//     4 (warning)                  WarnLevel
+//     5 (notice) ~ 6 (info)        InfoLevel
+//     7 (debug)                    DebugLevel
+//
+// The severity out of range will be clamped to the nearest level, so a negative severity
+// is ErrorLevel and a severity larger than 7 is DebugLevel.
+func LevelFromSeverity(severity int) Level {
+	switch {
+	case severity <= 3:
+		return ErrorLevel
+	case severity == 4:
+		return WarnLevel
+	case severity <= 6:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+// ToSeverity returns the syslog number of ll, which is the reverse of LevelFromSeverity.
+// DebugLevel is 7, InfoLevel is 6, WarnLevel is 4 and ErrorLevel is 3.
+// The levels higher than ErrorLevel like OffLevel will be clamped to 3, too.
+func (ll Level) ToSeverity() int {
+	switch {
+	case ll <= DebugLevel:
+		return 7
+	case ll == InfoLevel:
+		return 6
+	case ll == WarnLevel:
+		return 4
+	default:
+		return 3
+	}
+}
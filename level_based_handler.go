@@ -18,7 +18,14 @@
 
 package logit
 
+import "fmt"
+
 func init() {
+	registerLevelBasedHandlers()
+}
+
+// registerLevelBasedHandlers registers all level based handlers.
+func registerLevelBasedHandlers() {
 	registerDebugLevelHandler()
 	registerInfoLevelHandler()
 	registerWarnLevelHandler()
@@ -66,13 +73,46 @@ func (lbh *levelBasedHandler) Handle(log *Log) bool {
 	return true
 }
 
+// Flush flushes all handlers inside which are Flushers.
+func (lbh *levelBasedHandler) Flush() error {
+	_, err := flushHandlers(lbh.handlers)
+	return err
+}
+
+// FlushWithResult flushes all handlers inside which are Flushers, and returns the sum of results.
+func (lbh *levelBasedHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers(lbh.handlers)
+}
+
+// Close closes all handlers inside which are io.Closers.
+func (lbh *levelBasedHandler) Close() error {
+	return closeHandlers(lbh.handlers)
+}
+
 // handlersOf returns handlers parsed from params.
-func handlersOf(params map[string]interface{}) []Handler {
+// The "level" and "fields" params aren't handlers, so they will be skipped. See handlerOf.
+// If one of them failed, the handlers created will be closed and the error will be returned.
+func handlersOf(params map[string]interface{}) ([]Handler, error) {
 	handlers := make([]Handler, 0, len(params)+2)
 	for name, paramsOfHandler := range params {
-		handlers = append(handlers, handlerOf(name, paramsOfHandler.(map[string]interface{})))
+		if name == levelParam || name == fieldsParam {
+			continue
+		}
+
+		p, ok := paramsOfHandler.(map[string]interface{})
+		if !ok {
+			closeHandlers(handlers)
+			return nil, fmt.Errorf("%w: params of handler \"%s\" should be an object", InvalidConfigError, name)
+		}
+
+		handler, err := handlerOf(name, p)
+		if err != nil {
+			closeHandlers(handlers)
+			return nil, err
+		}
+		handlers = append(handlers, handler)
 	}
-	return handlers
+	return handlers, nil
 }
 
 // ================================ debug level handler ================================
@@ -96,8 +136,12 @@ func handlersOf(params map[string]interface{}) []Handler {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerDebugLevelHandler() {
-	RegisterHandler("debug", func(params map[string]interface{}) Handler {
-		return NewLevelBasedHandler(DebugLevel, handlersOf(params)...)
+	registerHandlerE("debug", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelBasedHandler(DebugLevel, handlers...), nil
 	})
 }
 
@@ -122,8 +166,12 @@ func registerDebugLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerInfoLevelHandler() {
-	RegisterHandler("info", func(params map[string]interface{}) Handler {
-		return NewLevelBasedHandler(InfoLevel, handlersOf(params)...)
+	registerHandlerE("info", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelBasedHandler(InfoLevel, handlers...), nil
 	})
 }
 
@@ -148,8 +196,12 @@ func registerInfoLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerWarnLevelHandler() {
-	RegisterHandler("warn", func(params map[string]interface{}) Handler {
-		return NewLevelBasedHandler(WarnLevel, handlersOf(params)...)
+	registerHandlerE("warn", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelBasedHandler(WarnLevel, handlers...), nil
 	})
 }
 
@@ -174,7 +226,11 @@ func registerWarnLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerErrorLevelHandler() {
-	RegisterHandler("error", func(params map[string]interface{}) Handler {
-		return NewLevelBasedHandler(ErrorLevel, handlersOf(params)...)
+	registerHandlerE("error", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelBasedHandler(ErrorLevel, handlers...), nil
 	})
 }
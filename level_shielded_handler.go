@@ -19,6 +19,11 @@
 package logit
 
 func init() {
+	registerLevelShieldedHandlers()
+}
+
+// registerLevelShieldedHandlers registers all level shielded handlers.
+func registerLevelShieldedHandlers() {
 	registerNonDebugLevelHandler()
 	registerNonInfoLevelHandler()
 	registerNonWarnLevelHandler()
@@ -65,6 +70,22 @@ func (lsh *levelShieldedHandler) Handle(log *Log) bool {
 	return true
 }
 
+// Flush flushes all handlers inside which are Flushers.
+func (lsh *levelShieldedHandler) Flush() error {
+	_, err := flushHandlers(lsh.handlers)
+	return err
+}
+
+// FlushWithResult flushes all handlers inside which are Flushers, and returns the sum of results.
+func (lsh *levelShieldedHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers(lsh.handlers)
+}
+
+// Close closes all handlers inside which are io.Closers.
+func (lsh *levelShieldedHandler) Close() error {
+	return closeHandlers(lsh.handlers)
+}
+
 // ================================ non-debug level handler ================================
 
 // registerNonDebugLevelHandler registers non-debug level handler which
@@ -86,8 +107,12 @@ func (lsh *levelShieldedHandler) Handle(log *Log) bool {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerNonDebugLevelHandler() {
-	RegisterHandler("!debug", func(params map[string]interface{}) Handler {
-		return NewLevelShieldedHandler(DebugLevel, handlersOf(params)...)
+	registerHandlerE("!debug", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelShieldedHandler(DebugLevel, handlers...), nil
 	})
 }
 
@@ -112,8 +137,12 @@ func registerNonDebugLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerNonInfoLevelHandler() {
-	RegisterHandler("!info", func(params map[string]interface{}) Handler {
-		return NewLevelShieldedHandler(InfoLevel, handlersOf(params)...)
+	registerHandlerE("!info", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelShieldedHandler(InfoLevel, handlers...), nil
 	})
 }
 
@@ -138,8 +167,12 @@ func registerNonInfoLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerNonWarnLevelHandler() {
-	RegisterHandler("!warn", func(params map[string]interface{}) Handler {
-		return NewLevelShieldedHandler(WarnLevel, handlersOf(params)...)
+	registerHandlerE("!warn", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelShieldedHandler(WarnLevel, handlers...), nil
 	})
 }
 
@@ -164,7 +197,11 @@ func registerNonWarnLevelHandler() {
 // to different handlers. Check other handlers' documents to know more about information.
 // See logit.Handler.
 func registerNonErrorLevelHandler() {
-	RegisterHandler("!error", func(params map[string]interface{}) Handler {
-		return NewLevelShieldedHandler(ErrorLevel, handlersOf(params)...)
+	registerHandlerE("!error", func(params map[string]interface{}) (Handler, error) {
+		handlers, err := handlersOf(params)
+		if err != nil {
+			return nil, err
+		}
+		return NewLevelShieldedHandler(ErrorLevel, handlers...), nil
 	})
 }
@@ -0,0 +1,64 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 21:10:52
+
+package logit
+
+import "testing"
+
+// 测试从 syslog 的严重程度转换为日志级别
+func TestLevelFromSeverity(t *testing.T) {
+	cases := map[int]Level{
+		-1:  ErrorLevel,
+		0:   ErrorLevel,
+		3:   ErrorLevel,
+		4:   WarnLevel,
+		5:   InfoLevel,
+		6:   InfoLevel,
+		7:   DebugLevel,
+		8:   DebugLevel,
+		100: DebugLevel,
+	}
+
+	for severity, level := range cases {
+		if LevelFromSeverity(severity) != level {
+			t.Fatalf("严重程度 %d 转换的日志级别不正确！%s", severity, LevelFromSeverity(severity))
+		}
+	}
+}
+
+// 测试日志级别转换为 syslog 的严重程度
+func TestLevelToSeverity(t *testing.T) {
+	cases := map[Level]int{
+		DebugLevel: 7,
+		InfoLevel:  6,
+		WarnLevel:  4,
+		ErrorLevel: 3,
+		OffLevel:   3,
+	}
+
+	for level, severity := range cases {
+		if level.ToSeverity() != severity {
+			t.Fatalf("日志级别 %s 转换的严重程度不正确！%d", level, level.ToSeverity())
+		}
+
+		// 转换回来需要是同一个级别，除了 OffLevel
+		if level != OffLevel && LevelFromSeverity(level.ToSeverity()) != level {
+			t.Fatalf("日志级别 %s 转换之后再转换回来不一致！", level)
+		}
+	}
+}
@@ -18,7 +18,10 @@
 
 package logit
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Log is representation of a logging message, including all information about this message.
 type Log struct {
@@ -32,14 +35,32 @@ type Log struct {
 	// now is the publishing time of this log.
 	now time.Time
 
+	// mono is the duration since process start measured by the monotonic clock, and it's 0 if disabled.
+	mono time.Duration
+
 	// file is the file path of this log.
 	file string
 
 	// line is the line number in file.
 	line int
 
+	// callerFunc is the fully-qualified name of the function calling logger.
+	callerFunc string
+
 	// msg is the message of this log.
 	msg string
+
+	// fields is the structured fields of this log.
+	// Notice that it may be shared by loggers, so never modify it in place.
+	fields []Field
+
+	// timeLayout is the layout of formattedTime, and formattedTime is now formatted in it.
+	// Formatting time is expensive, so it's cached for handlers using the same layout.
+	timeLayout    string
+	formattedTime string
+
+	// profiler records the latencies of encoding and writing this log, and it's nil if disabled.
+	profiler *profiler
 }
 
 // Logger returns the publisher of this log.
@@ -57,6 +78,13 @@ func (l *Log) Now() time.Time {
 	return l.now
 }
 
+// Mono returns the duration since process start measured by the monotonic clock when this log is published.
+// Unlike Now, it never goes backward, so it's reliable for computing latencies between logs of a process.
+// It's 0 if monotonic reading is disabled. See Logger.SetMonotonic.
+func (l *Log) Mono() time.Duration {
+	return l.mono
+}
+
 // File returns the file path of this log.
 func (l *Log) File() string {
 	return l.file
@@ -67,7 +95,186 @@ func (l *Log) Line() int {
 	return l.line
 }
 
+// CallerFunc returns the fully-qualified name of the function emitting this log, like "main.main"
+// and "github.com/you/app/pkg.(*Server).Serve". It's empty if file info is disabled.
+func (l *Log) CallerFunc() string {
+	return l.callerFunc
+}
+
+// formatTime returns now of this log formatted in layout.
+// The result is cached, so formatting in the same layout again will reuse it.
+// Only the last layout is cached, which is enough because most handlers use the same one.
+func (l *Log) formatTime(layout string) string {
+	if l.formattedTime == "" || l.timeLayout != layout {
+		l.timeLayout = layout
+		l.formattedTime = l.now.Format(layout)
+	}
+	return l.formattedTime
+}
+
 // Msg returns the message of this log.
 func (l *Log) Msg() string {
 	return l.msg
 }
+
+// Fields returns a copy of the structured fields of this log.
+// Modifying the returned fields won't affect this log.
+func (l *Log) Fields() Fields {
+	return Fields(l.fields).Clone()
+}
+
+// Field returns the value of the field whose key is key, and false if this log doesn't have it.
+func (l *Log) Field(key string) (interface{}, bool) {
+	for _, field := range l.fields {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+// FieldString returns the value of the field whose key is key if it's a string.
+// Return false if this log doesn't have it or it isn't a string.
+func (l *Log) FieldString(key string) (string, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return "", false
+	}
+
+	s, ok := value.(string)
+	return s, ok
+}
+
+// FieldInt returns the value of the field whose key is key if it's an integer.
+// All int and uint types are accepted, and so is a float64 without fraction, like the numbers decoded
+// by DecodeJsonLog. Return false if this log doesn't have it or it can't be an int64 without loss.
+func (l *Log) FieldInt(key string) (int64, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// FieldFloat returns the value of the field whose key is key if it's a number.
+// Both float and int types are accepted. Return false if this log doesn't have it or it isn't a number.
+func (l *Log) FieldFloat(key string) (float64, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+
+	if i, ok := l.FieldInt(key); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// FieldBool returns the value of the field whose key is key if it's a bool.
+// Return false if this log doesn't have it or it isn't a bool.
+func (l *Log) FieldBool(key string) (bool, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return false, false
+	}
+
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// FieldDuration returns the value of the field whose key is key if it's a time.Duration.
+// Return false if this log doesn't have it or it isn't a time.Duration.
+func (l *Log) FieldDuration(key string) (time.Duration, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return 0, false
+	}
+
+	d, ok := value.(time.Duration)
+	return d, ok
+}
+
+// FieldError returns the value of the field whose key is key if it's an error.
+// Return false if this log doesn't have it or it isn't an error.
+func (l *Log) FieldError(key string) (error, bool) {
+	value, ok := l.Field(key)
+	if !ok {
+		return nil, false
+	}
+
+	err, ok := value.(error)
+	return err, ok
+}
+
+// Map returns the structured view of this log, so you can assert on it without parsing strings.
+// The level is in "level" and its type is Level, the time is in "time" and its type is time.Time,
+// the msg is in "msg". The "file", "line" and "func" exist only if this log has file info,
+// and MonoKey exists only if this log has a monotonic reading, whose type is int64 in nanoseconds.
+// All fields of this log will be put into the map directly, so they may override the keys above.
+func (l *Log) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(l.fields)+5)
+	m["level"] = l.level
+	m["time"] = l.now
+	m["msg"] = l.msg
+
+	if l.file != "" && l.line != 0 {
+		m["file"] = l.file
+		m["line"] = l.line
+	}
+
+	if l.callerFunc != "" {
+		m["func"] = l.callerFunc
+	}
+
+	if l.mono > 0 {
+		m[MonoKey] = int64(l.mono)
+	}
+
+	for _, field := range l.fields {
+		m[field.Key] = field.Value
+	}
+	return m
+}
+
+// EncodedSize returns the count of bytes which encoder will produce when encoding this log
+// with timeFormat. It encodes this log and measures the result without writing it anywhere,
+// so it's useful for estimating the volume of logs, such as bytes per second of each level.
+func (l *Log) EncodedSize(encoder Encoder, timeFormat string) int {
+	return len(encoder.Encode(l, timeFormat))
+}
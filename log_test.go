@@ -0,0 +1,167 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/15 16:27:30
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 记录日志结构化视图的日志处理器
+type mapHandler struct {
+	logs []map[string]interface{}
+}
+
+func (mh *mapHandler) Handle(log *Log) bool {
+	mh.logs = append(mh.logs, log.Map())
+	return true
+}
+
+// 测试按照类型获取日志的字段
+func TestLogTypedFields(t *testing.T) {
+	timeout := errors.New("timeout")
+	log := &Log{fields: []Field{
+		{Key: "user", Value: "fish"},
+		{Key: "age", Value: 18},
+		{Key: "big", Value: uint64(math.MaxUint64)},
+		{Key: "decoded", Value: float64(123)},
+		{Key: "ratio", Value: 0.5},
+		{Key: "ok", Value: true},
+		{Key: "cost", Value: 3 * time.Second},
+		{Key: "err", Value: timeout},
+	}}
+
+	if v, ok := log.FieldString("user"); !ok || v != "fish" {
+		t.Fatalf("FieldString 的结果不正确！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldInt("age"); !ok || v != 18 {
+		t.Fatalf("FieldInt 的结果不正确！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldInt("decoded"); !ok || v != 123 {
+		t.Fatalf("FieldInt 应该接受没有小数的 float64！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldFloat("ratio"); !ok || v != 0.5 {
+		t.Fatalf("FieldFloat 的结果不正确！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldFloat("age"); !ok || v != 18 {
+		t.Fatalf("FieldFloat 应该接受整数！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldBool("ok"); !ok || !v {
+		t.Fatalf("FieldBool 的结果不正确！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldDuration("cost"); !ok || v != 3*time.Second {
+		t.Fatalf("FieldDuration 的结果不正确！%v %v", v, ok)
+	}
+
+	if v, ok := log.FieldError("err"); !ok || v != timeout {
+		t.Fatalf("FieldError 的结果不正确！%v %v", v, ok)
+	}
+
+	// 不存在的字段和类型不匹配的字段都返回 false
+	mismatches := map[string]func() bool{
+		"不存在的字符串":  func() bool { _, ok := log.FieldString("missing"); return ok },
+		"不存在的整数":   func() bool { _, ok := log.FieldInt("missing"); return ok },
+		"不存在的布尔值":  func() bool { _, ok := log.FieldBool("missing"); return ok },
+		"整数不是字符串":  func() bool { _, ok := log.FieldString("age"); return ok },
+		"字符串不是整数":  func() bool { _, ok := log.FieldInt("user"); return ok },
+		"小数不是整数":   func() bool { _, ok := log.FieldInt("ratio"); return ok },
+		"溢出的整数":    func() bool { _, ok := log.FieldInt("big"); return ok },
+		"字符串不是小数":  func() bool { _, ok := log.FieldFloat("user"); return ok },
+		"整数不是布尔值":  func() bool { _, ok := log.FieldBool("age"); return ok },
+		"整数不是时间间隔": func() bool { _, ok := log.FieldDuration("age"); return ok },
+		"字符串不是错误":  func() bool { _, ok := log.FieldError("user"); return ok },
+	}
+
+	for name, mismatch := range mismatches {
+		if mismatch() {
+			t.Fatalf("%s应该返回 false！", name)
+		}
+	}
+}
+
+// 测试日志的结构化视图
+func TestLogMap(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	logger.WithFields(map[string]interface{}{"user": "fish"}).InfoWith(Fields{{Key: "age", Value: 18}}, "map")
+
+	if len(handler.logs) != 1 {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	m := handler.logs[0]
+	if _, ok := m["time"].(time.Time); !ok {
+		t.Fatalf("time 的类型不正确！%T", m["time"])
+	}
+	delete(m, "time")
+
+	want := map[string]interface{}{
+		"level": InfoLevel,
+		"msg":   "map",
+		"user":  "fish",
+		"age":   18,
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("日志的结构化视图不正确！%v", m)
+	}
+}
+
+// 测试获取日志的字段
+func TestLogFields(t *testing.T) {
+	log := &Log{fields: []Field{{Key: "k", Value: "v"}}}
+	fields := log.Fields()
+	fields.Set("k", "modified")
+
+	if log.fields[0].Value != "v" {
+		t.Fatalf("修改返回的字段影响了日志本身！%v", log.fields)
+	}
+}
+
+// 测试日志编码之后的大小
+func TestLogEncodedSize(t *testing.T) {
+	handler := &sizeHandler{}
+
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, handler, NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat))
+	logger.InfoWith(Fields{{Key: "user", Value: "fish"}}, "size")
+
+	if len(handler.sizes) != 1 || handler.sizes[0] != buffer.Len() {
+		t.Fatalf("日志编码之后的大小不正确！%v %d", handler.sizes, buffer.Len())
+	}
+}
+
+// 记录日志编码之后的大小
+type sizeHandler struct {
+	sizes []int
+}
+
+func (sh *sizeHandler) Handle(log *Log) bool {
+	sh.sizes = append(sh.sizes, log.EncodedSize(JsonEncoder(), DefaultTimeFormat))
+	return true
+}
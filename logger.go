@@ -19,9 +19,13 @@
 package logit
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"regexp"
 	"runtime"
 	"sync"
 	"time"
@@ -52,6 +56,96 @@ type Logger struct {
 	// This step is useful but too expensive, so default is false.
 	needCaller bool
 
+	// reentrancyGuard detects logs emitted while handling another log in the same goroutine,
+	// and it's nil if disabled. This step is useful but expensive, so default is disabled.
+	// Notice that it's shared by child loggers, so logging through them is detected, too.
+	// See Logger.EnableReentrancyGuard.
+	reentrancyGuard *reentrancyGuard
+
+	// fields is the static fields of this logger, and every log will carry them.
+	// Notice that this slice will never be modified after being set, because it
+	// may be shared by child loggers. See Logger.WithFields.
+	fields []Field
+
+	// redactedKeys is the set of field keys whose values should be redacted.
+	// Notice that this map will never be modified after being set, and a new map
+	// will be created when redacting more keys. See Logger.RedactFields.
+	redactedKeys map[string]struct{}
+
+	// scrubbers rewrite sensitive data in msg and string field values.
+	// Notice that this slice will never be modified after being set, and a new slice
+	// will be created when adding more scrubbers. See Logger.AddScrubber.
+	scrubbers []scrubber
+
+	// errorCallback will be called when an error happens in handling logs, such as writing failed.
+	// See Logger.SetErrorCallback.
+	errorCallback func(err error)
+
+	// fieldMergeMode decides what to do when a field with existing key is merged.
+	// See Logger.SetFieldMergeMode.
+	fieldMergeMode FieldMergeMode
+
+	// onSuppressed will be called when a handler returns false and stops handling a log.
+	// See Logger.SetOnSuppressed.
+	onSuppressed func(log *Log)
+
+	// maxFields is the max count of fields of a log, and 0 means unlimited.
+	// See Logger.SetMaxFields.
+	maxFields int
+
+	// maxFieldValueLength is the max length of a string field value in bytes, and 0 means unlimited.
+	// See Logger.SetMaxFieldValueLength.
+	maxFieldValueLength int
+
+	// metricsSink receives the counts of logs, and it's never nil.
+	// See Logger.SetMetricsSink.
+	metricsSink MetricsSink
+
+	// captures receive copies of logs until they are stopped.
+	// Notice that this slice will never be modified after being set, and a new slice
+	// will be created when starting or stopping a capture. See Logger.StartCapture.
+	captures []*Capture
+
+	// profiler records the latencies of handling logs, and it's nil if disabled.
+	// See Logger.SetProfiling.
+	profiler *profiler
+
+	// counts counts logs in each level, and it's shared by child loggers.
+	// See Logger.StartHeartbeat.
+	counts *levelCounts
+
+	// byteBudget limits the bytes of logs in every window, and it's nil if unlimited.
+	// See Logger.SetByteBudget.
+	byteBudget *byteBudget
+
+	// stackDedup removes stacks seen in a window, and it's nil if disabled.
+	// See Logger.SetStackDedupWindow.
+	stackDedup *stackDedup
+
+	// clock returns the time assigned to every log, and it's never nil.
+	// See Logger.SetClock.
+	clock func() time.Time
+
+	// normalizeNewlines is whether to normalize line endings of msg and string field values.
+	// See Logger.SetNormalizeNewlines.
+	normalizeNewlines bool
+
+	// recoverHandlers is whether to recover the panics of handlers.
+	// See Logger.SetRecoverHandlers.
+	recoverHandlers bool
+
+	// monotonic is whether logs carry a monotonic clock reading.
+	// See Logger.SetMonotonic.
+	monotonic bool
+
+	// fatalThreshold is the level from which logs are fatal, and OffLevel means never.
+	// See Logger.SetFatalThreshold.
+	fatalThreshold Level
+
+	// handling counts logs being handled by handlers, and it's replaced with handlers,
+	// so the logs being handled by old handlers can be waited. See Logger.ReplaceHandlers.
+	handling *sync.WaitGroup
+
 	// logs is an object pool cache some Log holders.
 	// Use a pool is for reducing memory allocation.
 	logs *sync.Pool
@@ -73,22 +167,30 @@ func NewLogger(level Level, handlers ...Handler) *Logger {
 
 	// 创建 logger 对象
 	logger := &Logger{
-		level:      level,
-		handlers:   handlers,
-		needCaller: false,
-		mu:         &sync.RWMutex{},
+		level:           level,
+		handlers:        handlers,
+		needCaller:      false,
+		metricsSink:     nopMetricsSink{},
+		counts:          &levelCounts{},
+		clock:           time.Now,
+		recoverHandlers: true,
+		fatalThreshold:  OffLevel,
+		handling:        &sync.WaitGroup{},
+		mu:              &sync.RWMutex{},
 	}
 
 	// 初始化 logs 对象池
-	logger.logs = &sync.Pool{
+	logger.logs = newLogPool()
+	return logger
+}
+
+// newLogPool returns an object pool of Log holders.
+func newLogPool() *sync.Pool {
+	return &sync.Pool{
 		New: func() interface{} {
-			return &Log{
-				logger: logger,
-			}
+			return &Log{}
 		},
 	}
-
-	return logger
 }
 
 // ChangeLevelTo will change the logger level of current logger to newLevel.
@@ -108,6 +210,24 @@ func (l *Logger) Level() Level {
 	return l.level
 }
 
+// IsLevelEnabled returns true if a log in level will be handled by current logger.
+// It's cheap, so you can use it to guard the expensive work of building a log:
+//
+//     if logger.IsLevelEnabled(logit.DebugLevel) {
+//         logger.Debug(expensiveDump())
+//     }
+//
+// Notice that OffLevel is never enabled because no log is in OffLevel.
+func (l *Logger) IsLevelEnabled(level Level) bool {
+	if level == OffLevel {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.level <= level
+}
+
 // AddHandlers adds more handlers to current logger, and all handlers added before
 // will be retained. If you want to remove all handlers, try l.SetHandlers().
 // See logit.Handler.
@@ -137,6 +257,93 @@ func (l *Logger) SetHandlers(newHandlers ...Handler) bool {
 	return true
 }
 
+// ReplaceHandlers replaces l.handlers with newHandlers for future logs, and returns a function to drain
+// the old handlers, which waits for the logs being handled by them, then flushes and closes them.
+// It's useful for reloading config without losing logs buffered in old handlers:
+//
+//     drainOld := logger.ReplaceHandlers(newHandlers)
+//     if err := drainOld(); err != nil {
+//         // The old handlers failed to flush or close.
+//     }
+//
+// The old handlers which are in newHandlers, too, won't be flushed or closed by drainOld, because they're still in use.
+// Calling drainOld more than once is safe and returns the same error. If newHandlers is empty,
+// nothing will be replaced and drainOld returns NoHandlerError. Notice that child loggers created
+// before replacing still use the old handlers, so don't drain them until those loggers are discarded.
+// Also, don't call drainOld in handlers, because it waits for the log being handled by the handler.
+func (l *Logger) ReplaceHandlers(newHandlers []Handler) (drainOld func() error) {
+	if len(newHandlers) < 1 {
+		return func() error {
+			return NoHandlerError
+		}
+	}
+
+	l.mu.Lock()
+	oldHandlers := l.handlers
+	oldHandling := l.handling
+	l.handlers = append(make([]Handler, 0, len(newHandlers)+2), newHandlers...)
+	l.handling = &sync.WaitGroup{}
+	l.mu.Unlock()
+
+	// 新旧日志处理器中都有的日志处理器还会继续使用，不能被关闭
+	retired := make([]Handler, 0, len(oldHandlers))
+	for _, oldHandler := range oldHandlers {
+		if !containsHandler(newHandlers, oldHandler) {
+			retired = append(retired, oldHandler)
+		}
+	}
+
+	once := &sync.Once{}
+	var err error
+	return func() error {
+		once.Do(func() {
+			// 等待正在使用旧日志处理器的日志处理完成，再刷新和关闭它们，保证日志不会丢失
+			oldHandling.Wait()
+			_, flushErr := flushHandlers(retired)
+			err = joinErrors(flushErr, closeHandlers(retired))
+		})
+		return err
+	}
+}
+
+// containsHandler returns true if handler is one of handlers.
+// A handler whose type isn't comparable is never contained, because comparing it panics.
+func containsHandler(handlers []Handler, handler Handler) bool {
+	if !reflect.TypeOf(handler).Comparable() {
+		return false
+	}
+
+	for _, h := range handlers {
+		if h == handler {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOutput sets the destination of logs to w, which is like log.SetOutput.
+// It works only if current logger has one standard handler, such as the console handler and
+// the handlers created by NewStandardHandler. The encoder and time format will be retained.
+// Return OutputNotSwappableError if current logger has a more complex setup, and nothing will be changed.
+// Notice that the old writer won't be closed, and other loggers sharing the handler won't be affected.
+func (l *Logger) SetOutput(w io.Writer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.handlers) != 1 {
+		return OutputNotSwappableError
+	}
+
+	handler, ok := l.handlers[0].(*standardHandler)
+	if !ok {
+		return OutputNotSwappableError
+	}
+
+	// 日志处理器可能正在被使用或者被共享，所以创建一个新的日志处理器替换它，而不是直接修改它
+	l.handlers = []Handler{NewStandardHandler(w, handler.encoder, handler.timeFormat)}
+	return nil
+}
+
 // Handlers returns all handlers of current logger in a copy slice.
 func (l *Logger) Handlers() []Handler {
 	l.mu.RLock()
@@ -147,6 +354,329 @@ func (l *Logger) Handlers() []Handler {
 	return append(handlers, l.handlers...)
 }
 
+// WithFields returns a child logger carrying fields, and all fields of current logger
+// will be carried, too. If a key already exists in current logger, the value of it
+// will be overridden in the child logger by default. See SetFieldMergeMode. The child logger has a snapshot of the level,
+// handlers and other settings of current logger, so changing one of them won't affect another.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	child := l.copy()
+	child.fields = mergeFieldsWithMode(l.fields, fieldsOf(fields), l.fieldMergeMode)
+	return child
+}
+
+// SetFieldMergeMode sets mode which decides what to do when a field with existing key is merged,
+// such as a key of WithFields already exists in current logger, or a key of InfoWith already exists
+// in the fields of logger. Default is OverrideMode, and AppendMode will collect all values into a slice.
+// Notice that LazyField always overrides the existing field.
+func (l *Logger) SetFieldMergeMode(mode FieldMergeMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fieldMergeMode = mode
+}
+
+// SetMaxFields sets the max count of fields of a log to n, including the fields of logger.
+// The extra fields of a log will be truncated, and a field whose key is FieldsTruncatedKey and
+// value is true will be added as a marker. It protects downstream systems from oversized records.
+// Default is 0, which means unlimited.
+func (l *Logger) SetMaxFields(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxFields = n
+}
+
+// SetMaxFieldValueLength sets the max length of a string field value of a log to n bytes.
+// The longer values will be truncated to n bytes with TruncatedValueSuffix, and the count of fields
+// is retained. Values are never cut in the middle of a UTF-8 character. It bounds the size of huge
+// values like a stack dump in a field. Default is 0, which means unlimited.
+func (l *Logger) SetMaxFieldValueLength(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxFieldValueLength = n
+}
+
+// LazyField returns a child logger carrying a field whose value is generated by gen.
+// The gen will be called only when a log is really handled, so it won't be called if the
+// level of log is lower than the level of logger. This is useful for expensive fields:
+//
+//     logger.LazyField("request", func() interface{} { return dump(request) }).Debug("request received")
+//
+// Notice that gen will be called once for every log, and it's called concurrently if logging concurrently.
+func (l *Logger) LazyField(key string, gen func() interface{}) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	child := l.copy()
+	child.fields = mergeFields(l.fields, []Field{{Key: key, Value: lazyValue(gen)}})
+	return child
+}
+
+// SetErrorCallback sets callback which will be called when an error happens in handling logs,
+// such as a writing failed or timeout. Logging never returns an error, so this is the only way
+// to know that something is wrong with your handlers. Notice that callback may be called concurrently.
+func (l *Logger) SetErrorCallback(callback func(err error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errorCallback = callback
+}
+
+// SetOnSuppressed sets callback which will be called with the log when a handler returns false,
+// which means the handlers after it won't handle this log anymore. This makes the suppression
+// observable, so you can debug your over-aggressive filters. Default is nil, which means no callback.
+// Notice that the log will be reused after callback returns, so copy it if you want to retain it.
+func (l *Logger) SetOnSuppressed(callback func(log *Log)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onSuppressed = callback
+}
+
+// SetMetricsSink sets sink which receives the counts of logs, such as LogsCounter of each level
+// and DroppedCounter of handlers dropping logs. Default is a sink doing nothing, and setting
+// nil will reset to it. See MetricsSink.
+func (l *Logger) SetMetricsSink(sink MetricsSink) {
+	if sink == nil {
+		sink = nopMetricsSink{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.metricsSink = sink
+}
+
+// SetClock sets clock which returns the time assigned to every log, so you can control the time
+// of logs in one place, such as a fixed time for deterministic tests. Default is time.Now, and
+// setting nil will reset to it. Notice that clock may be called concurrently.
+func (l *Logger) SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = clock
+}
+
+// SetNormalizeNewlines sets whether to normalize line endings of logs, which is useful when logs come
+// from different platforms. If normalize is true, "\r\n" and "\r" in msg and string field values will be
+// replaced with "\n", and the trailing line endings of msg will be removed, so every log ends with a
+// single "\n". Default is false, which means line endings are written as they are.
+func (l *Logger) SetNormalizeNewlines(normalize bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.normalizeNewlines = normalize
+}
+
+// metrics returns the metrics sink of l.
+func (l *Logger) metrics() MetricsSink {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.metricsSink
+}
+
+// fieldPolicies returns the settings of l processing fields of logs, so handlers adding fields
+// to logs can process them in the same way. See NewFieldsHandler.
+func (l *Logger) fieldPolicies() (redactedKeys map[string]struct{}, scrubbers []scrubber, maxFields int, maxFieldValueLength int) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.redactedKeys, l.scrubbers, l.maxFields, l.maxFieldValueLength
+}
+
+// reportError calls the error callback of l with err if it exists.
+func (l *Logger) reportError(err error) {
+	l.mu.RLock()
+	callback := l.errorCallback
+	l.mu.RUnlock()
+
+	if callback != nil {
+		callback(err)
+	}
+}
+
+// Clone returns an independent copy of current logger, including the level, handlers in order,
+// fields, redacted keys, scrubbers and callbacks. Changing one of them won't affect another.
+// Unlike WithFields, it's a complete snapshot of current logger without any new settings.
+// The clone starts with its own level counts, profiling latencies, byte budget and stack dedup window
+// using the same settings, so they're not shared like child loggers do.
+// Notice that the handlers themselves are shared, only the slice of them is copied. So the logs being
+// handled by the clone are waited in closing or replacing handlers of current logger and vice versa,
+// the reentrancy guard is shared to detect logs emitted while handling through either of them,
+// and the captures started before cloning receive logs of both.
+func (l *Logger) Clone() *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// 克隆出来的日志记录器是独立的，所以计数和限制之类的状态需要重新创建
+	logger := l.copy()
+	logger.counts = &levelCounts{}
+
+	if l.profiler != nil {
+		logger.profiler = &profiler{}
+	}
+
+	if l.byteBudget != nil {
+		logger.byteBudget = &byteBudget{
+			limit:  l.byteBudget.limit,
+			window: l.byteBudget.window,
+		}
+	}
+
+	if l.stackDedup != nil {
+		logger.stackDedup = newStackDedup(l.stackDedup.window)
+	}
+	return logger
+}
+
+// copy returns a copy of current logger.
+// Notice that it's not safe for concurrency, so lock l.mu before calling it.
+func (l *Logger) copy() *Logger {
+
+	// 浅拷贝出所有的配置，再替换掉不能共享的部分
+	// 注意 fields 和 redactedKeys 都是写时复制的，所以可以直接共享
+	logger := *l
+	logger.handlers = make([]Handler, 0, len(l.handlers)+2)
+	logger.handlers = append(logger.handlers, l.handlers...)
+	logger.logs = newLogPool()
+	logger.mu = &sync.RWMutex{}
+	return &logger
+}
+
+// SetVersion sets the version and the commit of your application to l, and every log will carry
+// them as fields, whose keys are VersionKey and CommitKey. It's useful for correlating logs to deploys.
+// Setting again will override the values set before.
+func (l *Logger) SetVersion(version string, commit string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// mergeFields 会创建新的切片，原有的字段可能正在被使用
+	l.fields = mergeFields(l.fields, []Field{{Key: VersionKey, Value: version}, {Key: CommitKey, Value: commit}})
+}
+
+// RedactFields registers keys whose values should be redacted.
+// The value of a field whose key is one of keys will be replaced with RedactedValue
+// before being handled, so no encoder will ever see the value in clear text.
+// Keys registered before will be retained.
+func (l *Logger) RedactFields(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 写时复制，原有的集合可能正在被使用
+	redactedKeys := make(map[string]struct{}, len(l.redactedKeys)+len(keys))
+	for key := range l.redactedKeys {
+		redactedKeys[key] = struct{}{}
+	}
+	for _, key := range keys {
+		redactedKeys[key] = struct{}{}
+	}
+	l.redactedKeys = redactedKeys
+}
+
+// AddScrubber adds a scrubber which rewrites the parts matching pattern to replacement.
+// Scrubbers will rewrite msg and all string field values before handlers see them,
+// and multiple scrubbers will be applied in adding order. The replacement supports
+// expanding like $1, see regexp.Regexp.ReplaceAllString.
+// For example, this scrubber hides all card-number-like digits:
+//
+//     logger.AddScrubber(regexp.MustCompile(`\d{4}-?\d{4}-?\d{4}-?\d{4}`), "****-****-****-****")
+//
+func (l *Logger) AddScrubber(pattern *regexp.Regexp, replacement string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 写时复制，原有的切片可能正在被使用
+	scrubbers := make([]scrubber, 0, len(l.scrubbers)+1)
+	scrubbers = append(scrubbers, l.scrubbers...)
+	l.scrubbers = append(scrubbers, scrubber{pattern: pattern, replacement: replacement})
+}
+
+// Flush flushes all handlers of current logger which are Flushers.
+// It returns how many logs and bytes were flushed, and handlers which can't report
+// the result contribute zero. All handlers will be flushed even if some of them failed,
+// and all errors will be combined. See logit.Flusher and logit.ResultFlusher.
+func (l *Logger) Flush() (FlushResult, error) {
+	return flushHandlers(l.Handlers())
+}
+
+// Close flushes and closes all handlers of current logger.
+// Handlers which are io.Closers will be closed, and others will only be flushed.
+// All handlers will be flushed and closed even if some of them failed, and all errors will be
+// combined into one, which can be checked by errors.Is and errors.As in Go 1.20 and later.
+// Notice that you shouldn't log anything after closing.
+func (l *Logger) Close() error {
+	handlers := l.Handlers()
+	_, flushErr := flushHandlers(handlers)
+	return joinErrors(flushErr, closeHandlers(handlers))
+}
+
+// StartPeriodicFlush starts a goroutine flushing current logger every interval.
+// It's better than each handler spinning its own timer, because only one goroutine is used.
+// It returns a function to stop flushing, and it's safe to call stop more than once.
+// Notice that stop will wait for the flushing goroutine to exit.
+// The errors of flushing will be reported to the error callback. See SetErrorCallback.
+// If interval <= 0, nothing will be started and stop does nothing.
+func (l *Logger) StartPeriodicFlush(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	stopFlushing := l.flushOnTicks(ticker.C)
+	return func() {
+		ticker.Stop()
+		stopFlushing()
+	}
+}
+
+// flushOnTicks starts a goroutine flushing current logger every time ticks sends a time.
+// It returns a function to stop flushing, and it's safe to call stop more than once.
+func (l *Logger) flushOnTicks(ticks <-chan time.Time) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-ticks:
+				if _, err := l.Flush(); err != nil {
+					l.reportError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+// FlushOnContext starts a goroutine flushing current logger when ctx is done, so the logs buffered
+// for a request won't be lost after the request is cancelled or finished:
+//
+//     logger.FlushOnContext(r.Context())
+//
+// The goroutine exits after flushing once, and the error of flushing will be reported to the error callback.
+// If ctx can never be done, like context.Background, no goroutine will be started.
+func (l *Logger) FlushOnContext(ctx context.Context) {
+	done := ctx.Done()
+	if done == nil {
+		return
+	}
+
+	go func() {
+		<-done
+		if _, err := l.Flush(); err != nil {
+			l.reportError(err)
+		}
+	}()
+}
+
 // EnableFileInfo means every log will contain file info like line number.
 // However, you should know that this is expensive in time.
 // So be sure you really need it or keep it disabled.
@@ -164,12 +694,34 @@ func (l *Logger) DisableFileInfo() {
 	l.needCaller = false
 }
 
-// newLog returns a Log holder from object pool.
+// EnableReentrancyGuard means a log emitted while handling another log in the same goroutine will be
+// dropped, and ReentrantLogError will be reported to the error callback. It prevents infinite recursion
+// and deadlock when a handler logs through the same logger, such as a handler using a http client which
+// logs. Child loggers created after enabling share the guard, so logging through them is detected, too.
+// However, it needs the id of goroutine for every log, which is expensive, so it's disabled by default.
+func (l *Logger) EnableReentrancyGuard() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.reentrancyGuard == nil {
+		l.reentrancyGuard = &reentrancyGuard{}
+	}
+}
+
+// DisableReentrancyGuard means logs emitted while handling another log won't be detected anymore.
+// If you want to detect them again, try l.EnableReentrancyGuard().
+func (l *Logger) DisableReentrancyGuard() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reentrancyGuard = nil
+}
+
+// newLog returns a Log holder from object pool, and now is the time of it.
 // Notice that not every holder returned is new, as you know, that is why we use a pool.
-func (l *Logger) newLog(level Level, msg string) *Log {
+func (l *Logger) newLog(level Level, msg string, now time.Time) *Log {
 	log := l.logs.Get().(*Log)
+	log.logger = l
 	log.level = level
-	log.now = time.Now()
+	log.now = now
 	log.msg = msg
 	return log
 }
@@ -178,6 +730,12 @@ func (l *Logger) newLog(level Level, msg string) *Log {
 func (l *Logger) releaseLog(log *Log) {
 	log.file = ""
 	log.line = 0
+	log.callerFunc = ""
+	log.mono = 0
+	log.fields = nil
+	log.timeLayout = ""
+	log.formattedTime = ""
+	log.profiler = nil
 	l.logs.Put(log)
 }
 
@@ -187,8 +745,9 @@ const (
 )
 
 // log handles msg by l.handlers, and level will affect the visibility of this msg.
+// The fields will be carried by this log only, and static fields of logger will be carried, too.
 // Notice that callDepth is caller sensitive.
-func (l *Logger) log(callDepth int, level Level, msg string) {
+func (l *Logger) log(callDepth int, level Level, msg string, fields []Field) {
 
 	// 加上读锁
 	l.mu.RLock()
@@ -205,28 +764,120 @@ func (l *Logger) log(callDepth int, level Level, msg string) {
 	// 这个属性的值就已经确定了，并且不允许被修改了，这类似于 copy on write 的解决思路
 	// 这个解决并发竞争的方案是否没有问题，需要时间的验证才知道
 	needCaller := l.needCaller
+	handlers := l.handlers
+	staticFields := l.fields
+	redactedKeys := l.redactedKeys
+	scrubbers := l.scrubbers
+	fieldMergeMode := l.fieldMergeMode
+	maxFields := l.maxFields
+	maxFieldValueLength := l.maxFieldValueLength
+	onSuppressed := l.onSuppressed
+	metricsSink := l.metricsSink
+	reentrancyGuard := l.reentrancyGuard
+	captures := l.captures
+	profiler := l.profiler
+	byteBudget := l.byteBudget
+	stackDedup := l.stackDedup
+	clock := l.clock
+	normalizeNewlines := l.normalizeNewlines
+	recoverHandlers := l.recoverHandlers
+	monotonic := l.monotonic
+	fatal := level >= l.fatalThreshold
+
+	// 在读锁里登记正在处理的日志，替换日志处理器之后就可以等待这些日志处理完成
+	handling := l.handling
+	handling.Add(1)
+	defer handling.Done()
 	l.mu.RUnlock()
 
+	// 正在处理日志的协程又记录了日志，直接丢弃，防止无限递归或者死锁
+	if reentrancyGuard != nil {
+		id, ok := reentrancyGuard.enter()
+		if !ok {
+			l.reportError(ReentrantLogError)
+			return
+		}
+		defer reentrancyGuard.leave(id)
+	}
+
+	// 致命的日志处理完之后退出程序，即使它被丢弃了也一样
+	if fatal {
+		defer l.exitOnFatal()
+	}
+
+	reportLogs(metricsSink, level)
+	l.counts.inc(level)
+
+	// 统一换行符之后再脱敏，避免 \r 影响脱敏规则的匹配
+	if normalizeNewlines {
+		msg = normalizeMsg(msg)
+	}
+
 	// 处理日志
-	log := l.newLog(level, msg)
+	log := l.newLog(level, scrubString(msg, scrubbers), clock())
+	log.profiler = profiler
+	if monotonic {
+		log.mono = monoSince()
+	}
+	log.fields = truncateFields(withStaticFields(staticFields, fields, fieldMergeMode), maxFields)
+	log.fields = resolveLazyFields(log.fields)
+	if normalizeNewlines {
+		log.fields = normalizeFields(log.fields)
+	}
+	log.fields = scrubFields(redactFields(log.fields, redactedKeys), scrubbers)
+	if stackDedup != nil {
+		log.fields = stackDedup.dedup(log.fields, log.now)
+	}
+	log.fields = truncateFieldValues(log.fields, maxFieldValueLength)
 	defer l.releaseLog(log)
 
 	// 如果需要调用者的信息，对当前的 msg 进行包装
 	if needCaller {
 		wrapLogWithCaller(callDepth, log)
 	}
-	l.handleLog(log)
+
+	// 超过字节预算的日志直接丢弃
+	if byteBudget != nil && !l.takeByteBudget(byteBudget, handlers, log, recoverHandlers) {
+		return
+	}
+
+	for _, capture := range captures {
+		capture.add(log)
+	}
+
+	var begin time.Time
+	if profiler != nil {
+		begin = time.Now()
+	}
+
+	handled := l.handleLog(handlers, log, recoverHandlers)
+	if profiler != nil {
+		profiler.recordHandle(begin)
+	}
+
+	if !handled && onSuppressed != nil {
+		onSuppressed(log)
+	}
 }
 
-// handleLog handles log with l.handlers.
+// handleLog handles log with handlers, which is a snapshot of l.handlers taken under the lock.
 // Notice that if one handler returns false, then all handlers after it
-// will not be used anymore.
-func (l *Logger) handleLog(log *Log) {
-	for _, handler := range l.handlers {
+// will not be used anymore, and false will be returned.
+// If recoverHandlers is true, a panicking handler won't stop the handlers after it.
+func (l *Logger) handleLog(handlers []Handler, log *Log, recoverHandlers bool) bool {
+	for _, handler := range handlers {
+		if recoverHandlers {
+			if !l.handleWithRecovery(handler, log) {
+				return false
+			}
+			continue
+		}
+
 		if !handler.Handle(log) {
-			return
+			return false
 		}
 	}
+	return true
 }
 
 // wrapLogWithCaller wraps log with caller info.
@@ -235,34 +886,94 @@ func (l *Logger) handleLog(log *Log) {
 func wrapLogWithCaller(callDepth int, log *Log) {
 
 	// 这个 callDepth 是 runtime.Caller 方法的参数，表示要获取第几层调用者的信息
-	_, file, line, ok := runtime.Caller(callDepth)
+	pc, file, line, ok := runtime.Caller(callDepth)
 	if !ok {
 		log.file = "unknown file"
 		log.line = -1
+		return
 	}
 
 	log.file = file
 	log.line = line
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		log.callerFunc = fn.Name()
+	}
 }
 
 // Debug will output msg as a debug message.
 func (l *Logger) Debug(msg string) {
-	l.log(callDepth, DebugLevel, msg)
+	l.log(callDepth, DebugLevel, msg, nil)
 }
 
 // Info will output msg as an info message.
 func (l *Logger) Info(msg string) {
-	l.log(callDepth, InfoLevel, msg)
+	l.log(callDepth, InfoLevel, msg, nil)
 }
 
 // Warn will output msg as a warn message.
 func (l *Logger) Warn(msg string) {
-	l.log(callDepth, WarnLevel, msg)
+	l.log(callDepth, WarnLevel, msg, nil)
 }
 
 // Error will output msg as an error message.
 func (l *Logger) Error(msg string) {
-	l.log(callDepth, ErrorLevel, msg)
+	l.log(callDepth, ErrorLevel, msg, nil)
+}
+
+// DebugWith will output msg as a debug message with fields.
+// The fields will be carried by this log only, so you can reuse them in next log.
+// See logit.Fields.
+func (l *Logger) DebugWith(fields Fields, msg string) {
+	l.log(callDepth, DebugLevel, msg, fields)
+}
+
+// InfoWith will output msg as an info message with fields.
+// The fields will be carried by this log only, so you can reuse them in next log.
+// See logit.Fields.
+func (l *Logger) InfoWith(fields Fields, msg string) {
+	l.log(callDepth, InfoLevel, msg, fields)
+}
+
+// WarnWith will output msg as a warn message with fields.
+// The fields will be carried by this log only, so you can reuse them in next log.
+// See logit.Fields.
+func (l *Logger) WarnWith(fields Fields, msg string) {
+	l.log(callDepth, WarnLevel, msg, fields)
+}
+
+// ErrorWith will output msg as an error message with fields.
+// The fields will be carried by this log only, so you can reuse them in next log.
+// See logit.Fields.
+func (l *Logger) ErrorWith(fields Fields, msg string) {
+	l.log(callDepth, ErrorLevel, msg, fields)
+}
+
+// DebugWithFields will output msg as a debug message with fields.
+// The fields will be carried by this log only without creating a child logger, and they
+// will be sorted by key. Use DebugWith instead if you log in a tight loop.
+func (l *Logger) DebugWithFields(fields map[string]interface{}, msg string) {
+	l.log(callDepth, DebugLevel, msg, fieldsOf(fields))
+}
+
+// InfoWithFields will output msg as an info message with fields.
+// The fields will be carried by this log only without creating a child logger, and they
+// will be sorted by key. Use InfoWith instead if you log in a tight loop.
+func (l *Logger) InfoWithFields(fields map[string]interface{}, msg string) {
+	l.log(callDepth, InfoLevel, msg, fieldsOf(fields))
+}
+
+// WarnWithFields will output msg as a warn message with fields.
+// The fields will be carried by this log only without creating a child logger, and they
+// will be sorted by key. Use WarnWith instead if you log in a tight loop.
+func (l *Logger) WarnWithFields(fields map[string]interface{}, msg string) {
+	l.log(callDepth, WarnLevel, msg, fieldsOf(fields))
+}
+
+// ErrorWithFields will output msg as an error message with fields.
+// The fields will be carried by this log only without creating a child logger, and they
+// will be sorted by key. Use ErrorWith instead if you log in a tight loop.
+func (l *Logger) ErrorWithFields(fields map[string]interface{}, msg string) {
+	l.log(callDepth, ErrorLevel, msg, fieldsOf(fields))
 }
 
 // ================================== extension ==================================
@@ -279,20 +990,53 @@ func (l *Logger) Error(msg string) {
 //     }
 //
 // Check config file templates to know about more information.
+//
+// It panics if failed, and see NewLoggerFromE if you want an error instead.
 func NewLoggerFrom(reader io.Reader) *Logger {
+	logger, err := NewLoggerFromE(reader)
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// NewLoggerFromE is the same as NewLoggerFrom except it returns an error instead of
+// panicking if the config can't be parsed, is invalid or has no handler, or a handler can't be created.
+// The config will be validated before creating any handler, see ValidateConfig.
+func NewLoggerFromE(reader io.Reader) (*Logger, error) {
 
 	// 解析配置
 	conf, err := parseConfigFrom(reader)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	// 先校验配置，避免创建了一部分日志处理器之后才发现配置有问题
+	if err := validateConfig(conf); err != nil {
+		return nil, err
+	}
+
+	level, err := parseLevel(conf.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	handlers, err := parseHandlersFrom(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// NewLogger 在没有日志处理器的时候会 panic
+	if len(handlers) < 1 {
+		return nil, fmt.Errorf("%w: at least one handler should be configured", InvalidConfigError)
 	}
 
 	// 根据配置创建并初始化 logger
-	logger := NewLogger(parseLevel(conf.Level), parseHandlersFrom(conf)...)
+	logger := NewLogger(level, handlers...)
 	if conf.Caller {
 		logger.EnableFileInfo()
 	}
-	return logger
+	return logger, nil
 }
 
 // NewLoggerFromPath returns a logger parsed from config file.
@@ -308,43 +1052,78 @@ func NewLoggerFrom(reader io.Reader) *Logger {
 //     }
 //
 // Check config file templates to know about more information.
+//
+// It panics if failed, and see NewLoggerFromPathE if you want an error instead.
 func NewLoggerFromPath(pathOfConfigFile string) *Logger {
+	logger, err := NewLoggerFromPathE(pathOfConfigFile)
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// NewLoggerFromPathE is the same as NewLoggerFromPath except it returns an error instead of
+// panicking if the config file can't be opened or parsed.
+func NewLoggerFromPathE(pathOfConfigFile string) (*Logger, error) {
 
 	// 打开配置文件
 	file, err := os.Open(pathOfConfigFile)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewLoggerFromE(file)
+}
+
+// NewTempFileLogger returns a logger writing logs to a new temp file in text form.
+// The pattern is the same as ioutil.TempFile, such as "myapp_*.log", and the file will be created
+// in the default directory for temp files. The cleanup closes the logger and removes the file,
+// so it's useful for short-lived tools and tests which need a real file but no litter.
+// It's safe to call cleanup more than once.
+func NewTempFileLogger(pattern string, level Level) (logger *Logger, cleanup func(), err error) {
+	file, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return NewLoggerFrom(file)
+	logger = NewLogger(level, NewStandardHandler(file, TextEncoder(), DefaultTimeFormat))
+	once := &sync.Once{}
+	cleanup = func() {
+		once.Do(func() {
+			logger.Close()
+			os.Remove(file.Name())
+		})
+	}
+	return logger, cleanup, nil
 }
 
 // DebugFunc will output msg as a debug message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func (l *Logger) DebugFunc(msgGenerator func() string) {
-	l.log(callDepth, DebugLevel, msgGenerator())
+	l.log(callDepth, DebugLevel, msgGenerator(), nil)
 }
 
 // InfoFunc will output msg as an info message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func (l *Logger) InfoFunc(msgGenerator func() string) {
-	l.log(callDepth, InfoLevel, msgGenerator())
+	l.log(callDepth, InfoLevel, msgGenerator(), nil)
 }
 
 // WarnFunc will output msg as a warn message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func (l *Logger) WarnFunc(msgGenerator func() string) {
-	l.log(callDepth, WarnLevel, msgGenerator())
+	l.log(callDepth, WarnLevel, msgGenerator(), nil)
 }
 
 // ErrorFunc will output msg as an error message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func (l *Logger) ErrorFunc(msgGenerator func() string) {
-	l.log(callDepth, ErrorLevel, msgGenerator())
+	l.log(callDepth, ErrorLevel, msgGenerator(), nil)
 }
 
 // generateMessage generates a message from format and params.
@@ -363,7 +1142,7 @@ func generateMessage(format string, params ...interface{}) string {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func (l *Logger) Debugf(msgFormat string, msgParams ...interface{}) {
-	l.log(callDepth, DebugLevel, generateMessage(msgFormat, msgParams...))
+	l.log(callDepth, DebugLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Infof will output msg as an info message.
@@ -377,7 +1156,7 @@ func (l *Logger) Debugf(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func (l *Logger) Infof(msgFormat string, msgParams ...interface{}) {
-	l.log(callDepth, InfoLevel, generateMessage(msgFormat, msgParams...))
+	l.log(callDepth, InfoLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Warnf will output msg as a warn message.
@@ -391,7 +1170,7 @@ func (l *Logger) Infof(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func (l *Logger) Warnf(msgFormat string, msgParams ...interface{}) {
-	l.log(callDepth, WarnLevel, generateMessage(msgFormat, msgParams...))
+	l.log(callDepth, WarnLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Errorf will output msg as an error message.
@@ -405,5 +1184,5 @@ func (l *Logger) Warnf(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func (l *Logger) Errorf(msgFormat string, msgParams ...interface{}) {
-	l.log(callDepth, ErrorLevel, generateMessage(msgFormat, msgParams...))
+	l.log(callDepth, ErrorLevel, generateMessage(msgFormat, msgParams...), nil)
 }
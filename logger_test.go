@@ -19,15 +19,21 @@
 package logit
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // 测试日志记录器的 Debug 方法
@@ -95,6 +101,40 @@ func TestLoggerEnableAndDisableFileInfo(t *testing.T) {
 	logger.Warn("现在应该没有文件信息了吧！")
 }
 
+// 测试文件信息中包含调用者的函数名
+func TestLoggerCallerFunc(t *testing.T) {
+	handler := &mapHandler{}
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, handler, NewStandardHandler(buffer, JsonEncoder(), ""))
+	logger.Info("without file info")
+	logger.EnableFileInfo()
+	logger.Info("with file info")
+
+	expect := "github.com/FishGoddess/logit.TestLoggerCallerFunc"
+	if _, ok := handler.logs[0]["func"]; ok {
+		t.Fatalf("没有开启文件信息时不应该有函数名！%v", handler.logs[0])
+	}
+
+	if handler.logs[1]["func"] != expect {
+		t.Fatalf("函数名不正确！%v", handler.logs[1]["func"])
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	log, err := DecodeJsonLog([]byte(lines[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if log.CallerFunc() != expect || !strings.HasSuffix(log.File(), "logger_test.go") {
+		t.Fatalf("Json 日志中的函数名不正确！%s", lines[1])
+	}
+
+	encoded := string(TextEncoder().Encode(log, ""))
+	if !strings.Contains(encoded, "logger_test.go:"+strconv.Itoa(log.Line())+" "+expect+"] with file info") {
+		t.Fatalf("文本日志中的函数名不正确！%s", encoded)
+	}
+}
+
 type myHandler struct{}
 
 // 定制自己的日志处理器
@@ -213,6 +253,81 @@ func TestNewLoggerFromPath(t *testing.T) {
 	logger.Info("Does it work? 这是测试日志信息，实际的日志信息可能比这个长，也可能比这个短！")
 }
 
+// 测试配置有问题的时候返回错误，而不是退出程序或者 panic
+func TestNewLoggerFromEWithBadConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "TestNewLoggerFromEWithBadConfig_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	err = RegisterHandler("panickingHandler", func(params map[string]interface{}) Handler {
+		panic("can't create handler")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterHandler("panickingHandler")
+
+	// 文件的父目录是一个普通文件，创建文件会失败
+	badPath := escapeString(filepath.Join(file.Name(), "logit.log"))
+
+	cases := []struct {
+		config  string
+		invalid bool
+	}{
+		{`"level": "loud", "handlers": {"console": {}}`, true},
+		{`"handlers": {"notExisted": {}}`, true},
+		{`"handlers": {"console": {"encoder": "notExisted"}}`, true},
+		{`"handlers": {"console": {"level": "loud"}}`, true},
+		{`"handlers": {"info": {"notExisted": {}}}`, true},
+		{`"handlers": {}`, true},
+		{`"handlers": {"file": {"path": "` + badPath + `"}}`, false},
+		{`"handlers": {"!error": {"file": {"path": "` + badPath + `"}}}`, false},
+		{`"handlers": {"panickingHandler": {}}`, false},
+	}
+
+	for i, c := range cases {
+		logger, err := NewLoggerFromE(strings.NewReader(c.config))
+		if err == nil || logger != nil {
+			t.Fatalf("第 %d 个配置应该返回错误！%s", i+1, c.config)
+		}
+
+		if errors.Is(err, InvalidConfigError) != c.invalid {
+			t.Fatalf("第 %d 个配置返回的错误不正确！%v", i+1, err)
+		}
+	}
+}
+
+// 测试创建写入临时文件的 logger
+func TestNewTempFileLogger(t *testing.T) {
+	logger, cleanup, err := NewTempFileLogger("TestNewTempFileLogger_*.log", InfoLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("debug")
+	logger.Info("info")
+	path := logger.Handlers()[0].(*standardHandler).writer.(*os.File).Name()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("临时文件在清理前就不存在了！%v", err)
+	}
+
+	if !strings.Contains(string(content), "info") || strings.Contains(string(content), "debug") {
+		t.Fatalf("临时文件中的日志不正确！%s", content)
+	}
+
+	// 清理之后文件应该被删除，并且多次清理不会有问题
+	cleanup()
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("清理之后临时文件仍然存在！%v", err)
+	}
+}
+
 // 测试输出日志是从函数中生成的几个方法
 func TestLoggerLogFunction(t *testing.T) {
 	logger := NewLogger(DebugLevel, NewConsoleHandler(JsonEncoder(), ""))
@@ -242,3 +357,893 @@ func TestLoggerOutputWithFormat(t *testing.T) {
 	logger.Warnf("Warnf... %d %s %.3f", 123, "幸福呢", 123.123456)
 	logger.Errorf("Errorf... %d %s %.3f", 123, "幸福呢", 123.123456)
 }
+
+// 测试带字段的子日志记录器
+func TestLoggerWithFields(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, JsonEncoder(), ""))
+	child := logger.WithFields(map[string]interface{}{"user": "fish", "age": 18})
+	child.WithFields(map[string]interface{}{"age": 19}).Info("grandchild")
+	child.Info("child")
+	logger.Info("parent")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("日志条数不正确！%d", len(lines))
+	}
+
+	if !strings.HasSuffix(lines[0], `"msg":"grandchild","age":19,"user":"fish"}`) {
+		t.Fatalf("孙日志记录器的字段不正确！%s", lines[0])
+	}
+
+	if !strings.HasSuffix(lines[1], `"msg":"child","age":18,"user":"fish"}`) {
+		t.Fatalf("子日志记录器的字段不正确！%s", lines[1])
+	}
+
+	if !strings.HasSuffix(lines[2], `"msg":"parent"}`) {
+		t.Fatalf("父日志记录器不应该有字段！%s", lines[2])
+	}
+}
+
+// 测试字段脱敏
+func TestLoggerRedactFields(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel,
+		NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat),
+		NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat),
+	)
+
+	// 子日志记录器会继承父日志记录器的脱敏设置
+	logger.RedactFields("password", "token")
+	child := logger.WithFields(map[string]interface{}{
+		"user":     "fish",
+		"password": "p@ssw0rd",
+		"token":    "t0ken-of-fish",
+	})
+
+	child = child.WithFields(map[string]interface{}{"ssn": "123-45-6789"})
+	child.RedactFields("ssn")
+	child.Info("login")
+
+	output := buffer.String()
+	for _, secret := range []string{"p@ssw0rd", "t0ken-of-fish", "123-45-6789"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("日志中出现了明文的敏感信息 %s！\n%s", secret, output)
+		}
+	}
+
+	if !strings.Contains(output, "password=***") || !strings.Contains(output, `"token":"***"`) {
+		t.Fatalf("脱敏后的字段不正确！\n%s", output)
+	}
+
+	if !strings.Contains(output, "user=fish") || !strings.Contains(output, `"user":"fish"`) {
+		t.Fatalf("未脱敏的字段不应该被修改！\n%s", output)
+	}
+}
+
+// 测试使用正则表达式擦除敏感信息
+func TestLoggerAddScrubber(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger.AddScrubber(regexp.MustCompile(`\d{4}-?\d{4}-?\d{4}-?(\d{4})`), "****-****-****-$1")
+	logger.AddScrubber(regexp.MustCompile(`\*{4}-\*{4}-\*{4}-`), "[card]")
+
+	logger.WithFields(map[string]interface{}{"card": "6222020202020202", "amount": 100}).
+		Info("paid by card 4111-1111-1111-1234")
+
+	output := buffer.String()
+	if strings.Contains(output, "4111-1111-1111") || strings.Contains(output, "622202020202") {
+		t.Fatalf("日志中出现了明文的卡号！\n%s", output)
+	}
+
+	// 多个擦除器需要按照添加顺序执行
+	if !strings.Contains(output, "paid by card [card]1234 ") || !strings.Contains(output, "card=[card]0202") {
+		t.Fatalf("擦除后的信息不正确！\n%s", output)
+	}
+
+	if !strings.Contains(output, "amount=100") {
+		t.Fatalf("非字符串的字段不应该被修改！\n%s", output)
+	}
+}
+
+// 记录刷新次数的日志处理器
+type flushCountingHandler struct {
+	flushed chan struct{}
+}
+
+func (fch *flushCountingHandler) Handle(log *Log) bool {
+	return true
+}
+
+func (fch *flushCountingHandler) Flush() error {
+	select {
+	case fch.flushed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// 测试定时刷新日志处理器
+func TestLoggerStartPeriodicFlush(t *testing.T) {
+	handler := &flushCountingHandler{flushed: make(chan struct{}, 16)}
+	logger := NewLogger(DebugLevel, NewLevelBasedHandler(InfoLevel, handler), &myHandler{})
+
+	// 使用假的时钟进行测试，每次 tick 都需要刷新一次
+	ticks := make(chan time.Time)
+	stop := logger.flushOnTicks(ticks)
+	for i := 0; i < 3; i++ {
+		ticks <- time.Now()
+		select {
+		case <-handler.flushed:
+		case <-time.After(time.Second):
+			t.Fatalf("第 %d 次 tick 没有触发刷新！", i+1)
+		}
+	}
+
+	stop()
+	stop()
+	select {
+	case ticks <- time.Now():
+		t.Fatal("停止之后不应该再接收 tick！")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// 真实的时钟也需要能正常工作
+	stop = logger.StartPeriodicFlush(time.Millisecond)
+	defer stop()
+	select {
+	case <-handler.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("定时刷新没有触发！")
+	}
+
+	// 时间间隔不合法的时候什么都不做
+	logger.StartPeriodicFlush(0)()
+	logger.StartPeriodicFlush(-time.Second)()
+}
+
+// 测试定时刷新失败的时候调用错误回调
+func TestLoggerStartPeriodicFlushError(t *testing.T) {
+	flushErr := errors.New("flush failed")
+	logger := NewLogger(DebugLevel, &failingFlushHandler{err: flushErr})
+
+	errs := make(chan error, 16)
+	logger.SetErrorCallback(func(err error) {
+		errs <- err
+	})
+
+	ticks := make(chan time.Time)
+	stop := logger.flushOnTicks(ticks)
+	defer stop()
+
+	ticks <- time.Now()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, flushErr) {
+			t.Fatalf("定时刷新的错误不正确！%v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("定时刷新失败的时候没有调用错误回调！")
+	}
+}
+
+// 测试 context 结束的时候刷新日志处理器
+func TestLoggerFlushOnContext(t *testing.T) {
+	handler := &flushCountingHandler{flushed: make(chan struct{}, 16)}
+	logger := NewLogger(DebugLevel, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	logger.FlushOnContext(ctx)
+
+	select {
+	case <-handler.flushed:
+		t.Fatal("context 还没有结束就刷新了！")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-handler.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("context 结束之后没有刷新！")
+	}
+
+	// 刷新一次之后就退出了
+	select {
+	case <-handler.flushed:
+		t.Fatal("context 结束之后刷新了多次！")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// 永远不会结束的 context 不需要监听
+	logger.FlushOnContext(context.Background())
+}
+
+// 缓冲日志的日志处理器
+type bufferedHandler struct {
+	entries [][]byte
+	writer  *bytes.Buffer
+}
+
+func (bh *bufferedHandler) Handle(log *Log) bool {
+	bh.entries = append(bh.entries, TextEncoder().Encode(log, DefaultTimeFormat))
+	return true
+}
+
+func (bh *bufferedHandler) Flush() error {
+	_, err := bh.FlushWithResult()
+	return err
+}
+
+func (bh *bufferedHandler) FlushWithResult() (FlushResult, error) {
+	result := FlushResult{Entries: len(bh.entries)}
+	for _, entry := range bh.entries {
+		n, err := bh.writer.Write(entry)
+		result.Bytes += n
+		if err != nil {
+			return result, err
+		}
+	}
+	bh.entries = nil
+	return result, nil
+}
+
+// 测试刷新日志处理器并返回刷新的结果
+func TestLoggerFlush(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	handler := &bufferedHandler{writer: buffer}
+	logger := NewLogger(DebugLevel, handler, NewLevelShieldedHandler(DebugLevel, &bufferedHandler{writer: buffer}), &myHandler{})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+
+	result, err := logger.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Entries != 5 || result.Bytes != buffer.Len() || result.Bytes == 0 {
+		t.Fatalf("刷新的结果不正确！%+v, 实际写入了 %d 字节", result, buffer.Len())
+	}
+
+	result, err = logger.Flush()
+	if err != nil || result.Entries != 0 || result.Bytes != 0 {
+		t.Fatalf("没有缓冲的日志时，刷新的结果应该为 0！%+v, %v", result, err)
+	}
+}
+
+// 关闭时会返回错误的日志处理器
+type failingCloseHandler struct {
+	err error
+}
+
+func (fch *failingCloseHandler) Handle(log *Log) bool {
+	return true
+}
+
+func (fch *failingCloseHandler) Close() error {
+	return fch.err
+}
+
+// 测试关闭时所有日志处理器的错误都会被返回
+func TestLoggerCloseJoinErrors(t *testing.T) {
+	err1 := errors.New("close error 1")
+	err2 := errors.New("close error 2")
+	closed := &closingHandler{}
+	logger := NewLogger(DebugLevel, &failingCloseHandler{err: err1}, closed, &failingCloseHandler{err: err2})
+
+	err := logger.Close()
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Fatalf("关闭返回的错误没有包含所有的错误！%v", err)
+	}
+
+	if !strings.Contains(err.Error(), err1.Error()) || !strings.Contains(err.Error(), err2.Error()) {
+		t.Fatalf("关闭返回的错误信息不正确！%s", err.Error())
+	}
+
+	if !closed.closed {
+		t.Fatal("出错之后的日志处理器没有被关闭！")
+	}
+
+	// 只有一个错误时直接返回这个错误
+	if err := NewLogger(DebugLevel, &failingCloseHandler{err: err1}).Close(); err != err1 {
+		t.Fatalf("只有一个错误时返回的错误不正确！%v", err)
+	}
+
+	if err := NewLogger(DebugLevel, &failingCloseHandler{}).Close(); err != nil {
+		t.Fatalf("没有错误时返回了错误！%v", err)
+	}
+}
+
+// 写入总是失败的 writer
+type failingWriter struct {
+	err error
+}
+
+func (fw *failingWriter) Write(p []byte) (n int, err error) {
+	return 0, fw.err
+}
+
+// 测试处理日志发生错误时调用错误回调
+func TestLoggerSetErrorCallback(t *testing.T) {
+	writeErr := fmt.Errorf("disk is full")
+	logger := NewLogger(DebugLevel, NewStandardHandler(&failingWriter{err: writeErr}, TextEncoder(), DefaultTimeFormat))
+
+	// 没有设置错误回调也不能出问题
+	logger.Info("without callback")
+
+	var errs []error
+	logger.SetErrorCallback(func(err error) {
+		errs = append(errs, err)
+	})
+
+	logger.Info("with callback")
+	logger.WithFields(map[string]interface{}{"child": true}).Info("child")
+	if len(errs) != 2 || errs[0] != writeErr || errs[1] != writeErr {
+		t.Fatalf("错误回调的调用结果不正确！%v", errs)
+	}
+}
+
+// 返回 false 的日志处理器，模拟过滤器
+type suppressingHandler struct{}
+
+func (sh *suppressingHandler) Handle(log *Log) bool {
+	return log.Level() != DebugLevel
+}
+
+// 测试日志处理器返回 false 时调用回调
+func TestLoggerSetOnSuppressed(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, &suppressingHandler{}, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+
+	// 没有设置回调也不能出问题
+	logger.Debug("without callback")
+
+	var suppressed []string
+	logger.SetOnSuppressed(func(log *Log) {
+		suppressed = append(suppressed, log.Msg())
+	})
+
+	logger.Debug("suppressed")
+	logger.Info("not suppressed")
+	if len(suppressed) != 1 || suppressed[0] != "suppressed" {
+		t.Fatalf("回调的调用结果不正确！%v", suppressed)
+	}
+
+	if strings.Contains(buffer.String(), "] suppressed") || !strings.Contains(buffer.String(), "not suppressed") {
+		t.Fatalf("日志处理器的输出不正确！%s", buffer.String())
+	}
+}
+
+// 并发安全的 buffer，日志处理器会被克隆出来的 logger 共享
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mu     sync.Mutex
+}
+
+func (sb *syncBuffer) Write(p []byte) (n int, err error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buffer.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buffer.String()
+}
+
+// 测试克隆出来的 logger 和原 logger 互不影响
+func TestLoggerClone(t *testing.T) {
+	buffer := &syncBuffer{}
+	logger := NewLogger(InfoLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+	logger = logger.WithFields(map[string]interface{}{"service": "order"})
+	logger.RedactFields("password")
+
+	cloned := logger.Clone()
+	if cloned.Level() != InfoLevel || len(cloned.Handlers()) != 1 {
+		t.Fatalf("克隆的 logger 配置不正确！%v %d", cloned.Level(), len(cloned.Handlers()))
+	}
+
+	// 并发修改两个 logger，使用 -race 运行可以检查数据竞争
+	wg := &sync.WaitGroup{}
+	for _, l := range []*Logger{logger, cloned} {
+		wg.Add(1)
+		go func(l *Logger) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.ChangeLevelTo(DebugLevel)
+				l.RedactFields("token" + strconv.Itoa(i))
+				l.InfoWith(Fields{{Key: "password", Value: "123"}}, "concurrency")
+			}
+		}(l)
+	}
+	wg.Wait()
+
+	// 修改其中一个，另一个不受影响
+	cloned.ChangeLevelTo(ErrorLevel)
+	cloned.AddHandlers(&myHandler{})
+	if logger.Level() != DebugLevel || len(logger.Handlers()) != 1 {
+		t.Fatalf("修改克隆的 logger 影响了原 logger！%v %d", logger.Level(), len(logger.Handlers()))
+	}
+
+	if !strings.Contains(buffer.String(), "service=order password="+RedactedValue) {
+		t.Fatalf("克隆的 logger 没有携带原 logger 的字段和脱敏配置！%s", buffer.String())
+	}
+}
+
+// 测试克隆的 logger 不共享计数和限制之类的状态
+func TestLoggerCloneOwnState(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	logger.SetByteBudget(16, time.Hour)
+	logger.SetStackDedupWindow(time.Hour)
+	logger.SetProfiling(true)
+
+	cloned := logger.Clone()
+	if cloned.byteBudget == logger.byteBudget || cloned.stackDedup == logger.stackDedup ||
+		cloned.profiler == logger.profiler || cloned.counts == logger.counts {
+		t.Fatal("克隆的 logger 和原 logger 共享了状态！")
+	}
+
+	if cloned.byteBudget.limit != 16 || cloned.byteBudget.window != time.Hour || cloned.stackDedup.window != time.Hour {
+		t.Fatalf("克隆的 logger 的配置不正确！%+v %v", cloned.byteBudget, cloned.stackDedup.window)
+	}
+
+	// 原 logger 用完了预算，克隆的 logger 依然可以记录日志
+	logger.Info("a log using the whole budget")
+	logger.Info("dropped")
+	cloned.Info("cloned")
+	if logger.ByteBudgetDropped() != 1 || cloned.ByteBudgetDropped() != 0 || len(handler.logs) != 2 {
+		t.Fatalf("克隆的 logger 共享了字节预算！%d %d %d", logger.ByteBudgetDropped(), cloned.ByteBudgetDropped(), len(handler.logs))
+	}
+
+	if logger.counts.get(InfoLevel) != 2 || cloned.counts.get(InfoLevel) != 1 {
+		t.Fatalf("克隆的 logger 共享了日志计数！%d %d", logger.counts.get(InfoLevel), cloned.counts.get(InfoLevel))
+	}
+}
+
+// 测试在运行中切换日志的输出目标
+func TestLoggerSetOutput(t *testing.T) {
+	before := bytes.NewBuffer(nil)
+	after := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(before, JsonEncoder(), ""))
+	child := logger.WithFields(map[string]interface{}{"child": true})
+
+	logger.Info("before")
+	if err := logger.SetOutput(after); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after")
+	child.Info("child")
+
+	if !strings.Contains(before.String(), `"msg":"before"`) || strings.Contains(before.String(), `"msg":"after"`) {
+		t.Fatalf("切换之前的输出不正确！%s", before.String())
+	}
+
+	// 编码器和时间格式需要保留，其他共享日志处理器的 logger 不受影响
+	if !strings.HasPrefix(after.String(), `{"level":"info","time":`) || !strings.Contains(after.String(), `"msg":"after"`) {
+		t.Fatalf("切换之后的输出不正确！%s", after.String())
+	}
+
+	if !strings.Contains(before.String(), `"msg":"child"`) {
+		t.Fatalf("共享日志处理器的 logger 受到了影响！%s", before.String())
+	}
+
+	logger.AddHandlers(&myHandler{})
+	if err := logger.SetOutput(before); err != OutputNotSwappableError {
+		t.Fatalf("多个日志处理器时没有返回错误！%v", err)
+	}
+
+	if err := NewLogger(DebugLevel, &myHandler{}).SetOutput(before); err != OutputNotSwappableError {
+		t.Fatalf("不是标准日志处理器时没有返回错误！%v", err)
+	}
+}
+
+// 测试判断日志级别是否会被记录
+func TestLoggerIsLevelEnabled(t *testing.T) {
+	logger := NewLogger(WarnLevel, &myHandler{})
+	expects := map[Level]bool{DebugLevel: false, InfoLevel: false, WarnLevel: true, ErrorLevel: true, OffLevel: false}
+	for level, expect := range expects {
+		if logger.IsLevelEnabled(level) != expect {
+			t.Fatalf("级别 %s 是否记录的结果不正确！", level)
+		}
+	}
+
+	// 修改级别之后需要反映最新的级别
+	logger.ChangeLevelTo(DebugLevel)
+	if !logger.IsLevelEnabled(DebugLevel) {
+		t.Fatal("修改级别之后 debug 级别应该被记录！")
+	}
+
+	logger.ChangeLevelTo(OffLevel)
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, OffLevel} {
+		if logger.IsLevelEnabled(level) {
+			t.Fatalf("关闭之后级别 %s 不应该被记录！", level)
+		}
+	}
+}
+
+// 处理日志的时候又通过同一个 logger 记录日志的日志处理器
+type reentrantHandler struct {
+	logger *Logger
+	msgs   []string
+	mu     sync.Mutex
+}
+
+func (rh *reentrantHandler) Handle(log *Log) bool {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	rh.msgs = append(rh.msgs, log.Msg())
+	rh.logger.Info("inside " + log.Msg())
+	return true
+}
+
+// 测试处理日志的时候又记录日志不会死锁或者无限递归
+func TestLoggerEnableReentrancyGuard(t *testing.T) {
+	handler := &reentrantHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	handler.logger = logger
+
+	var errs []error
+	logger.SetErrorCallback(func(err error) {
+		errs = append(errs, err)
+	})
+	logger.EnableReentrancyGuard()
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("outside")
+		logger.WithFields(map[string]interface{}{"child": true}).Info("child")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("处理日志的时候记录日志导致死锁了！")
+	}
+
+	if len(handler.msgs) != 2 || handler.msgs[0] != "outside" || handler.msgs[1] != "child" {
+		t.Fatalf("处理的日志不正确！%v", handler.msgs)
+	}
+
+	if len(errs) != 2 || errs[0] != ReentrantLogError {
+		t.Fatalf("丢弃的日志没有报告错误！%v", errs)
+	}
+
+	// 不同协程并发记录日志不应该被丢弃
+	logger.SetHandlers(NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat))
+	group := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			logger.Info("concurrent")
+		}()
+	}
+	group.Wait()
+
+	if len(errs) != 2 {
+		t.Fatalf("并发记录的日志被丢弃了！%v", errs)
+	}
+}
+
+// 测试使用固定的时钟，每条日志的时间都一样
+func TestLoggerSetClock(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+
+	fixed := time.Date(2020, 8, 30, 20, 40, 0, 0, time.UTC)
+	logger.SetClock(func() time.Time {
+		return fixed
+	})
+
+	logger.Debug("debug")
+	logger.WithFields(map[string]interface{}{"child": true}).Info("child")
+	time.Sleep(time.Millisecond)
+	logger.Error("error")
+
+	for i, m := range handler.logs {
+		if m["time"] != fixed {
+			t.Fatalf("第 %d 条日志的时间不正确！%v", i+1, m["time"])
+		}
+	}
+
+	// 设置为 nil 会恢复成 time.Now
+	logger.SetClock(nil)
+	logger.Info("now")
+	if now := handler.logs[3]["time"].(time.Time); now.Equal(fixed) || time.Since(now) > time.Minute {
+		t.Fatalf("恢复之后的时间不正确！%v", now)
+	}
+
+	// 并发设置时钟和输出日志
+	logger = NewLogger(DebugLevel, NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat))
+	group := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			logger.SetClock(func() time.Time { return fixed.Add(time.Duration(i) * time.Second) })
+			logger.Info("concurrent")
+		}(i)
+	}
+	group.Wait()
+}
+
+// 测试统一换行符之后，输出中没有 \r，并且每条日志只以一个 \n 结尾
+func TestLoggerSetNormalizeNewlines(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat))
+	logger.SetNormalizeNewlines(true)
+
+	logger.WithFields(map[string]interface{}{"reason": "a\r\nb\rc"}).Info("line1\r\nline2\r\n\r\n")
+	got := buffer.String()
+	if strings.Contains(got, "\r") || strings.Contains(got, `\u000d`) {
+		t.Fatalf("统一换行符之后还有 \\r！%q", got)
+	}
+
+	if !strings.HasSuffix(got, "}\n") || strings.Count(got, "\n") != 1 {
+		t.Fatalf("日志没有以一个 \\n 结尾！%q", got)
+	}
+
+	log, err := DecodeJsonLog([]byte(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Msg() != "line1\nline2" {
+		t.Fatalf("统一换行符之后的 msg 不正确！%q", log.Msg())
+	}
+
+	if reason, _ := log.FieldString("reason"); reason != "a\nb\nc" {
+		t.Fatalf("统一换行符之后的字段不正确！%q", reason)
+	}
+
+	// 关闭之后按原样输出
+	buffer.Reset()
+	logger.SetNormalizeNewlines(false)
+	logger.Info("line1\r\nline2")
+
+	log, err = DecodeJsonLog(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Msg() != "line1\r\nline2" {
+		t.Fatalf("关闭之后的 msg 不正确！%q", log.Msg())
+	}
+}
+
+// 测试墙上时钟往回调的时候，单调时钟的读数依然是递增的
+func TestLoggerSetMonotonic(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+
+	logger.Info("disabled")
+	if _, ok := handler.logs[0][MonoKey]; ok {
+		t.Fatalf("没有开启单调时钟的日志带有读数！%v", handler.logs[0])
+	}
+
+	// 墙上时钟每次都往回调一个小时
+	now := time.Date(2020, 8, 30, 21, 15, 0, 0, time.UTC)
+	logger.SetClock(func() time.Time {
+		now = now.Add(-time.Hour)
+		return now
+	})
+	logger.SetMonotonic(true)
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Millisecond)
+		logger.Info("enabled")
+	}
+
+	for i := 2; i < len(handler.logs); i++ {
+		previous, current := handler.logs[i-1], handler.logs[i]
+		if !current["time"].(time.Time).Before(previous["time"].(time.Time)) {
+			t.Fatalf("墙上时钟没有往回调！%v %v", previous["time"], current["time"])
+		}
+
+		if current[MonoKey].(int64) <= previous[MonoKey].(int64) {
+			t.Fatalf("单调时钟的读数没有递增！%v %v", previous[MonoKey], current[MonoKey])
+		}
+	}
+
+	// 编码之后也能解码出单调时钟的读数
+	buffer := &bytes.Buffer{}
+	logger = NewLogger(DebugLevel, NewStandardHandler(buffer, JsonEncoder(), DefaultTimeFormat))
+	logger.SetMonotonic(true)
+	logger.Info("encoded")
+
+	log, err := DecodeJsonLog(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Mono() <= 0 || !strings.Contains(buffer.String(), `"`+MonoKey+`":`) {
+		t.Fatalf("编码之后的单调时钟读数不正确！%s", buffer.String())
+	}
+}
+
+// 测试替换日志处理器之后，旧日志处理器缓冲的日志在排空的时候被写出
+func TestLoggerReplaceHandlers(t *testing.T) {
+	oldBuffer := &bytes.Buffer{}
+	oldWriter := bufio.NewWriterSize(oldBuffer, 4096)
+	logger := NewLogger(DebugLevel, NewStandardHandler(oldWriter, TextEncoder(), DefaultTimeFormat))
+	logger.Info("old")
+
+	newBuffer := &bytes.Buffer{}
+	drainOld := logger.ReplaceHandlers([]Handler{NewStandardHandler(newBuffer, TextEncoder(), DefaultTimeFormat)})
+	logger.Info("new")
+
+	if oldBuffer.Len() != 0 {
+		t.Fatalf("排空之前旧日志处理器的缓冲就被写出了！%s", oldBuffer.String())
+	}
+
+	if err := drainOld(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 多次排空是安全的
+	if err := drainOld(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(oldBuffer.String(), "old") || strings.Contains(oldBuffer.String(), "new") {
+		t.Fatalf("旧日志处理器输出的日志不正确！%s", oldBuffer.String())
+	}
+
+	if !strings.Contains(newBuffer.String(), "new") || strings.Contains(newBuffer.String(), "old") {
+		t.Fatalf("新日志处理器输出的日志不正确！%s", newBuffer.String())
+	}
+
+	// 没有新的日志处理器的时候不会替换
+	if err := logger.ReplaceHandlers(nil)(); err != NoHandlerError {
+		t.Fatalf("没有新的日志处理器的时候应该返回错误！%v", err)
+	}
+
+	if len(logger.Handlers()) != 1 {
+		t.Fatalf("没有新的日志处理器的时候不应该替换！%d", len(logger.Handlers()))
+	}
+}
+
+// 测试替换日志处理器的时候，两边都有的日志处理器不会被关闭
+func TestLoggerReplaceHandlersKeepsShared(t *testing.T) {
+	shared := &closingHandler{bufferedHandler: bufferedHandler{writer: bytes.NewBuffer(nil)}}
+	retired := &closingHandler{bufferedHandler: bufferedHandler{writer: bytes.NewBuffer(nil)}}
+	logger := NewLogger(DebugLevel, shared, retired)
+
+	if err := logger.ReplaceHandlers([]Handler{shared})(); err != nil {
+		t.Fatal(err)
+	}
+
+	if shared.closed || !retired.closed {
+		t.Fatalf("排空的时候关闭的日志处理器不正确！%v %v", shared.closed, retired.closed)
+	}
+}
+
+// 测试并发记录日志和替换日志处理器，需要使用 -race 运行
+func TestLoggerReplaceHandlersConcurrently(t *testing.T) {
+	logger := NewLogger(DebugLevel, NewStandardHandler(&syncBuffer{}, TextEncoder(), DefaultTimeFormat))
+	logger.SetByteBudget(1024, time.Millisecond)
+
+	done := make(chan struct{})
+	started := &sync.WaitGroup{}
+	group := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		started.Add(1)
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			logger.Info("started")
+			started.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					logger.Info("concurrent")
+				}
+			}
+		}()
+	}
+
+	// 等所有协程都开始记录日志之后再替换日志处理器
+	started.Wait()
+	for i := 0; i < 1000; i++ {
+		logger.ReplaceHandlers([]Handler{NewStandardHandler(&syncBuffer{}, TextEncoder(), DefaultTimeFormat)})
+		logger.SetOutput(&syncBuffer{})
+	}
+	close(done)
+	group.Wait()
+}
+
+// blockingHandler is a handler blocking in handling until release is closed.
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+	handled bool
+}
+
+func (bh *blockingHandler) Handle(log *Log) bool {
+	close(bh.entered)
+	<-bh.release
+	bh.handled = true
+	return true
+}
+
+// 测试排空旧日志处理器的时候，会等待正在处理的日志处理完成
+func TestLoggerReplaceHandlersWaitsHandling(t *testing.T) {
+	slow := &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+	logger := NewLogger(DebugLevel, slow)
+	go logger.Info("slow")
+	<-slow.entered
+
+	drainOld := logger.ReplaceHandlers([]Handler{NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat)})
+	drained := make(chan error)
+	go func() {
+		drained <- drainOld()
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("正在处理的日志还没有处理完成，就排空完成了！")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(slow.release)
+	if err := <-drained; err != nil {
+		t.Fatal(err)
+	}
+
+	if !slow.handled {
+		t.Fatal("排空完成的时候日志还没有处理完成！")
+	}
+}
+
+// 测试达到致命级别的日志会刷新日志处理器并退出程序
+func TestLoggerSetFatalThreshold(t *testing.T) {
+	var codes []int
+	defer func(original func(code int)) {
+		exit = original
+	}(exit)
+	exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	handler := &flushCountingHandler{flushed: make(chan struct{}, 16)}
+	logger := NewLogger(DebugLevel, handler)
+
+	// 默认不会退出
+	logger.Error("error")
+	if len(codes) != 0 {
+		t.Fatalf("默认情况下不应该退出！%v", codes)
+	}
+
+	logger.SetFatalThreshold(WarnLevel)
+	logger.Info("info")
+	if len(codes) != 0 {
+		t.Fatalf("低于致命级别的日志不应该退出！%v", codes)
+	}
+
+	logger.Warn("warn")
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Fatalf("达到致命级别的日志应该退出！%v", codes)
+	}
+
+	select {
+	case <-handler.flushed:
+	default:
+		t.Fatal("退出之前没有刷新日志处理器！")
+	}
+
+	// OffLevel 表示永远不退出
+	logger.SetFatalThreshold(OffLevel)
+	logger.Error("error")
+	if len(codes) != 1 {
+		t.Fatalf("关闭之后不应该退出！%v", codes)
+	}
+}
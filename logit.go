@@ -61,50 +61,50 @@ const (
 
 // Debug will output msg as a debug message.
 func Debug(msg string) {
-	globalLogger.log(callDepthOfGlobalLogger, DebugLevel, msg)
+	globalLogger.log(callDepthOfGlobalLogger, DebugLevel, msg, nil)
 }
 
 // Info will output msg as an info message.
 func Info(msg string) {
-	globalLogger.log(callDepthOfGlobalLogger, InfoLevel, msg)
+	globalLogger.log(callDepthOfGlobalLogger, InfoLevel, msg, nil)
 }
 
 // Warn will output msg as a warn message.
 func Warn(msg string) {
-	globalLogger.log(callDepthOfGlobalLogger, WarnLevel, msg)
+	globalLogger.log(callDepthOfGlobalLogger, WarnLevel, msg, nil)
 }
 
 // Error will output msg as an error message.
 func Error(msg string) {
-	globalLogger.log(callDepthOfGlobalLogger, ErrorLevel, msg)
+	globalLogger.log(callDepthOfGlobalLogger, ErrorLevel, msg, nil)
 }
 
 // DebugFunc will output msg as a debug message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func DebugFunc(msgGenerator func() string) {
-	globalLogger.log(callDepthOfGlobalLogger, DebugLevel, msgGenerator())
+	globalLogger.log(callDepthOfGlobalLogger, DebugLevel, msgGenerator(), nil)
 }
 
 // InfoFunc will output msg as an info message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func InfoFunc(msgGenerator func() string) {
-	globalLogger.log(callDepthOfGlobalLogger, InfoLevel, msgGenerator())
+	globalLogger.log(callDepthOfGlobalLogger, InfoLevel, msgGenerator(), nil)
 }
 
 // WarnFunc will output msg as a warn message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func WarnFunc(msgGenerator func() string) {
-	globalLogger.log(callDepthOfGlobalLogger, WarnLevel, msgGenerator())
+	globalLogger.log(callDepthOfGlobalLogger, WarnLevel, msgGenerator(), nil)
 }
 
 // ErrorFunc will output msg as an error message.
 // The msg is the return value of msgGenerator.
 // This is the better way to output a long log made from many variables.
 func ErrorFunc(msgGenerator func() string) {
-	globalLogger.log(callDepthOfGlobalLogger, ErrorLevel, msgGenerator())
+	globalLogger.log(callDepthOfGlobalLogger, ErrorLevel, msgGenerator(), nil)
 }
 
 // Debugf will output msg as a debug message.
@@ -118,7 +118,7 @@ func ErrorFunc(msgGenerator func() string) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func Debugf(msgFormat string, msgParams ...interface{}) {
-	globalLogger.log(callDepth, DebugLevel, generateMessage(msgFormat, msgParams...))
+	globalLogger.log(callDepth, DebugLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Infof will output msg as an info message.
@@ -132,7 +132,7 @@ func Debugf(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func Infof(msgFormat string, msgParams ...interface{}) {
-	globalLogger.log(callDepth, InfoLevel, generateMessage(msgFormat, msgParams...))
+	globalLogger.log(callDepth, InfoLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Warnf will output msg as a warn message.
@@ -146,7 +146,7 @@ func Infof(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func Warnf(msgFormat string, msgParams ...interface{}) {
-	globalLogger.log(callDepth, WarnLevel, generateMessage(msgFormat, msgParams...))
+	globalLogger.log(callDepth, WarnLevel, generateMessage(msgFormat, msgParams...), nil)
 }
 
 // Errorf will output msg as an error message.
@@ -160,5 +160,5 @@ func Warnf(msgFormat string, msgParams ...interface{}) {
 // but it's still faster than other logging libs. If you care about performance,
 // than you should think about it, and if you don't, just use it without thinking.
 func Errorf(msgFormat string, msgParams ...interface{}) {
-	globalLogger.log(callDepth, ErrorLevel, generateMessage(msgFormat, msgParams...))
+	globalLogger.log(callDepth, ErrorLevel, generateMessage(msgFormat, msgParams...), nil)
 }
@@ -0,0 +1,29 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/16 14:02:51
+
+/*
+Package logitgrpc provides an adapter routing the internal logging of gRPC to logit.
+
+1. LoggerV2:
+
+	// LoggerV2 implements grpclog.LoggerV2, so you can set it to gRPC directly.
+	// Notice that this package doesn't import gRPC, so gRPC won't be a dependency of logit.
+	grpclog.SetLoggerV2(logitgrpc.NewLoggerV2(logger))
+
+*/
+package logitgrpc // import "github.com/FishGoddess/logit/logitgrpc"
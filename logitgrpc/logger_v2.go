@@ -0,0 +1,144 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/16 14:10:37
+
+package logitgrpc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FishGoddess/logit"
+)
+
+var (
+	// exit is the function called by Fatal methods after logging.
+	// It's a variable so we can replace it in testing.
+	exit = os.Exit
+)
+
+// LoggerV2 is an adapter which implements grpclog.LoggerV2 with a logit logger.
+// The warning logs will be logged in warn level, and the fatal logs will be
+// logged in error level, then the logger will be closed and the process will exit.
+type LoggerV2 struct {
+	logger *logit.Logger
+}
+
+// NewLoggerV2 returns a LoggerV2 which logs by logger.
+// It implements grpclog.LoggerV2, so you can use it like this:
+//
+//     grpclog.SetLoggerV2(logitgrpc.NewLoggerV2(logger))
+//
+func NewLoggerV2(logger *logit.Logger) *LoggerV2 {
+	return &LoggerV2{
+		logger: logger,
+	}
+}
+
+// sprintln is fmt.Sprintln without the trailing newline, because encoders will add it.
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+// Info logs to INFO log. Arguments are handled in the manner of fmt.Print.
+func (lv *LoggerV2) Info(args ...interface{}) {
+	lv.logger.Info(fmt.Sprint(args...))
+}
+
+// Infoln logs to INFO log. Arguments are handled in the manner of fmt.Println.
+func (lv *LoggerV2) Infoln(args ...interface{}) {
+	lv.logger.Info(sprintln(args...))
+}
+
+// Infof logs to INFO log. Arguments are handled in the manner of fmt.Printf.
+func (lv *LoggerV2) Infof(format string, args ...interface{}) {
+	lv.logger.Infof(format, args...)
+}
+
+// Warning logs to WARNING log. Arguments are handled in the manner of fmt.Print.
+func (lv *LoggerV2) Warning(args ...interface{}) {
+	lv.logger.Warn(fmt.Sprint(args...))
+}
+
+// Warningln logs to WARNING log. Arguments are handled in the manner of fmt.Println.
+func (lv *LoggerV2) Warningln(args ...interface{}) {
+	lv.logger.Warn(sprintln(args...))
+}
+
+// Warningf logs to WARNING log. Arguments are handled in the manner of fmt.Printf.
+func (lv *LoggerV2) Warningf(format string, args ...interface{}) {
+	lv.logger.Warnf(format, args...)
+}
+
+// Error logs to ERROR log. Arguments are handled in the manner of fmt.Print.
+func (lv *LoggerV2) Error(args ...interface{}) {
+	lv.logger.Error(fmt.Sprint(args...))
+}
+
+// Errorln logs to ERROR log. Arguments are handled in the manner of fmt.Println.
+func (lv *LoggerV2) Errorln(args ...interface{}) {
+	lv.logger.Error(sprintln(args...))
+}
+
+// Errorf logs to ERROR log. Arguments are handled in the manner of fmt.Printf.
+func (lv *LoggerV2) Errorf(format string, args ...interface{}) {
+	lv.logger.Errorf(format, args...)
+}
+
+// fatal closes the logger and exits the process with status code 1.
+func (lv *LoggerV2) fatal() {
+	lv.logger.Close()
+	exit(1)
+}
+
+// Fatal logs to ERROR log. Arguments are handled in the manner of fmt.Print.
+// The logger will be closed and the process will exit with status code 1 after logging.
+func (lv *LoggerV2) Fatal(args ...interface{}) {
+	lv.logger.Error(fmt.Sprint(args...))
+	lv.fatal()
+}
+
+// Fatalln logs to ERROR log. Arguments are handled in the manner of fmt.Println.
+// The logger will be closed and the process will exit with status code 1 after logging.
+func (lv *LoggerV2) Fatalln(args ...interface{}) {
+	lv.logger.Error(sprintln(args...))
+	lv.fatal()
+}
+
+// Fatalf logs to ERROR log. Arguments are handled in the manner of fmt.Printf.
+// The logger will be closed and the process will exit with status code 1 after logging.
+func (lv *LoggerV2) Fatalf(format string, args ...interface{}) {
+	lv.logger.Errorf(format, args...)
+	lv.fatal()
+}
+
+// levelOfVerbosity returns the logit level of gRPC verbosity.
+// Verbosity 0 is the normal info logs, and larger verbosity is more detailed like debug logs.
+func levelOfVerbosity(verbosity int) logit.Level {
+	if verbosity <= 0 {
+		return logit.InfoLevel
+	}
+	return logit.DebugLevel
+}
+
+// V reports whether verbosity level l is at least the requested verbose level.
+// Verbosity 0 maps to info level and others map to debug level, so V returns
+// true only if the level of logger is lower than or equal to the mapped level.
+func (lv *LoggerV2) V(l int) bool {
+	return lv.logger.IsLevelEnabled(levelOfVerbosity(l))
+}
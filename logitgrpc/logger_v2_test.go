@@ -0,0 +1,114 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/16 14:48:20
+
+package logitgrpc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/FishGoddess/logit"
+)
+
+// grpcLoggerV2 is a copy of grpclog.LoggerV2, so we can check the implementation without importing gRPC.
+type grpcLoggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+}
+
+// 测试 gRPC 日志适配器的每个方法
+func TestLoggerV2(t *testing.T) {
+	exitCodes := []int{}
+	exit = func(code int) {
+		exitCodes = append(exitCodes, code)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.TextEncoder(), ""))
+
+	var lv grpcLoggerV2 = NewLoggerV2(logger)
+	lv.Info("info", 1)
+	lv.Infoln("infoln", 2)
+	lv.Infof("infof %d", 3)
+	lv.Warning("warning", 4)
+	lv.Warningln("warningln", 5)
+	lv.Warningf("warningf %d", 6)
+	lv.Error("error", 7)
+	lv.Errorln("errorln", 8)
+	lv.Errorf("errorf %d", 9)
+	lv.Fatal("fatal", 10)
+	lv.Fatalln("fatalln", 11)
+	lv.Fatalf("fatalf %d", 12)
+
+	want := []string{
+		"[info] info1", "[info] infoln 2", "[info] infof 3",
+		"[warn] warning4", "[warn] warningln 5", "[warn] warningf 6",
+		"[error] error7", "[error] errorln 8", "[error] errorf 9",
+		"[error] fatal10", "[error] fatalln 11", "[error] fatalf 12",
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("日志条数不正确！%d\n%s", len(lines), buffer.String())
+	}
+
+	for i, line := range lines {
+		// 时间每次都不一样，去掉之后再比较
+		parts := strings.SplitN(line, "] ", 3)
+		if len(parts) != 3 || parts[0]+"] "+parts[2] != want[i] {
+			t.Fatalf("第 %d 条日志不正确！%s", i+1, line)
+		}
+	}
+
+	if len(exitCodes) != 3 || exitCodes[0] != 1 {
+		t.Fatalf("Fatal 方法没有退出程序！%v", exitCodes)
+	}
+}
+
+// 测试 gRPC 日志的详细程度和日志级别的映射
+func TestLoggerV2V(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.TextEncoder(), ""))
+	lv := NewLoggerV2(logger)
+
+	if !lv.V(0) || !lv.V(2) {
+		t.Fatal("debug 级别的日志记录器应该输出所有详细程度的日志！")
+	}
+
+	logger.ChangeLevelTo(logit.InfoLevel)
+	if !lv.V(0) || lv.V(1) {
+		t.Fatal("info 级别的日志记录器应该只输出详细程度为 0 的日志！")
+	}
+
+	logger.ChangeLevelTo(logit.WarnLevel)
+	if lv.V(0) {
+		t.Fatal("warn 级别的日志记录器不应该输出 info 日志！")
+	}
+}
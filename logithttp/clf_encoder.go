@@ -0,0 +1,126 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 22:03:47
+
+package logithttp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/FishGoddess/logit"
+)
+
+const (
+	// CLFTimeFormat is the time format of Common Log Format.
+	CLFTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// CLFEncoder returns an encoder encoding the log of a http request to a line of Common Log Format:
+//
+//     127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+//
+// The fields are from the log carrying fields of Request and Middleware, and the missing fields
+// will be rendered as "-". The time is always formatted in CLFTimeFormat, so timeFormat is ignored.
+// Use it with Middleware to produce standard access logs ingestible by existing tools.
+func CLFEncoder() logit.Encoder {
+	return clfEncoder(false)
+}
+
+// CombinedLogEncoder is the same as CLFEncoder except the referer and the user agent will be
+// appended, which is Combined Log Format:
+//
+//     127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"
+//
+func CombinedLogEncoder() logit.Encoder {
+	return clfEncoder(true)
+}
+
+// clfEncoder returns an encoder encoding logs to lines of Common Log Format.
+// The combined decides if the referer and the user agent should be appended.
+func clfEncoder(combined bool) logit.Encoder {
+	return func(log *logit.Log, timeFormat string) []byte {
+		fields := log.Map()
+
+		// 远程地址只保留 host 部分，ident 没有办法获取，固定为 -
+		buffer := bytes.NewBuffer(make([]byte, 0, 128))
+		buffer.WriteString(clfHost(fields[RemoteAddrKey]))
+		buffer.WriteString(" - ")
+		buffer.WriteString(clfValue(fields[UserKey]))
+		buffer.WriteString(" [")
+		buffer.WriteString(log.Now().Format(CLFTimeFormat))
+		buffer.WriteString("] ")
+
+		// 请求行由请求方法、路径和协议组成，全部缺失时使用 -
+		requestLine := strings.TrimSpace(clfString(fields[MethodKey]) + " " + clfString(fields[PathKey]) + " " + clfString(fields[ProtoKey]))
+		writeQuoted(buffer, requestLine)
+
+		buffer.WriteString(" ")
+		buffer.WriteString(clfValue(fields[StatusKey]))
+		buffer.WriteString(" ")
+		buffer.WriteString(clfValue(fields[BytesKey]))
+
+		if combined {
+			buffer.WriteString(" ")
+			writeQuoted(buffer, clfString(fields[RefererKey]))
+			buffer.WriteString(" ")
+			writeQuoted(buffer, clfString(fields[UserAgentKey]))
+		}
+
+		buffer.WriteString("\n")
+		return buffer.Bytes()
+	}
+}
+
+// clfString returns value in string form, and it's "" if value is nil.
+func clfString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// clfValue returns value in string form, and it's "-" if value is nil or empty.
+func clfValue(value interface{}) string {
+	if s := clfString(value); s != "" {
+		return s
+	}
+	return "-"
+}
+
+// clfHost returns the host of remote address, and it's "-" if remote address is nil or empty.
+func clfHost(remoteAddr interface{}) string {
+	addr := clfValue(remoteAddr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// writeQuoted writes s to buffer in double quotes, and s will be "-" if it's empty.
+// The double quotes and backslashes in s will be escaped.
+func writeQuoted(buffer *bytes.Buffer, s string) {
+	if s == "" {
+		s = "-"
+	}
+
+	buffer.WriteString(`"`)
+	buffer.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+	buffer.WriteString(`"`)
+}
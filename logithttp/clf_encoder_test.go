@@ -0,0 +1,85 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 22:31:05
+
+package logithttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/logit"
+)
+
+// 测试编码成 Common Log Format 的日志
+func TestCLFEncoder(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, CLFEncoder(), ""))
+	logger.InfoWith(logit.Fields{
+		{Key: RemoteAddrKey, Value: "127.0.0.1:52013"},
+		{Key: UserKey, Value: "frank"},
+		{Key: MethodKey, Value: "GET"},
+		{Key: PathKey, Value: "/apache_pb.gif"},
+		{Key: ProtoKey, Value: "HTTP/1.0"},
+		{Key: StatusKey, Value: 200},
+		{Key: BytesKey, Value: 2326},
+	}, requestMsg)
+
+	// 时间是当前时间，所以使用正则匹配
+	pattern := regexp.MustCompile(`^127\.0\.0\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /apache_pb.gif HTTP/1.0" 200 2326\n$`)
+	if !pattern.Match(buffer.Bytes()) {
+		t.Fatalf("编码的日志不正确！%s", buffer.String())
+	}
+
+	// 缺失的字段使用 - 代替
+	buffer.Reset()
+	logger.Info(requestMsg)
+	pattern = regexp.MustCompile(`^- - - \[[^\]]+\] "-" - -\n$`)
+	if !pattern.Match(buffer.Bytes()) {
+		t.Fatalf("缺失字段的日志不正确！%s", buffer.String())
+	}
+}
+
+// 测试中间件配合 Combined Log Format 输出访问日志
+func TestCombinedLogEncoder(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, CombinedLogEncoder(), ""))
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	r.RemoteAddr = "10.0.0.1:52013"
+	r.SetBasicAuth("frank", "secret")
+	r.Header.Set("Referer", "http://www.example.com/start.html")
+	r.Header.Set("User-Agent", `Mozilla/4.08 "test"`)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	pattern := regexp.MustCompile(`^10\.0\.0\.1 - frank \[[^\]]+\] "GET /index.html HTTP/1.1" 200 5 "http://www.example.com/start.html" "Mozilla/4.08 \\"test\\""\n$`)
+	if !pattern.Match(buffer.Bytes()) {
+		t.Fatalf("编码的日志不正确！%s", buffer.String())
+	}
+
+	// 时间使用 CLF 的固定格式
+	if _, err := time.Parse(CLFTimeFormat, regexp.MustCompile(`\[([^\]]+)\]`).FindStringSubmatch(buffer.String())[1]); err != nil {
+		t.Fatalf("时间的格式不正确！%v", err)
+	}
+}
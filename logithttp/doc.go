@@ -0,0 +1,46 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/08 15:10:26
+
+/*
+Package logithttp provides some helpers to log http requests by logit.
+
+1. Request:
+
+	// Request logs a http request with standard fields in one line.
+	// The fields are method, path, remote_addr, status, latency, user_agent, proto and referer.
+	begin := time.Now()
+	// Handle the request...
+	logithttp.Request(logger, r, http.StatusOK, time.Since(begin))
+
+2. Middleware:
+
+	// Middleware logs each request handled by the wrapped handler.
+	// If the wrapped handler panics, the panic will be logged with the stack and re-panicked.
+	// Try logithttp.RecoveringMiddleware if you want to recover the panic.
+	http.ListenAndServe(":8080", logithttp.Middleware(logger)(mux))
+
+3. Access logs:
+
+	// CLFEncoder encodes the logs of requests to Common Log Format, and CombinedLogEncoder
+	// encodes them to Combined Log Format. The missing fields will be rendered as "-".
+	handler := logit.NewStandardHandler(os.Stdout, logithttp.CombinedLogEncoder(), "")
+	logger := logit.NewLogger(logit.InfoLevel, handler)
+	http.ListenAndServe(":8080", logithttp.Middleware(logger)(mux))
+
+*/
+package logithttp // import "github.com/FishGoddess/logit/logithttp"
@@ -0,0 +1,161 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/09 11:26:40
+
+package logithttp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/FishGoddess/logit"
+)
+
+const (
+	// panicMsg is the message of the log of a panic in handling.
+	// The log carries the same fields as logit.Recover, see logit.PanicKey and logit.StackKey.
+	panicMsg = "http request panicked"
+)
+
+// responseWriter is a wrapper of http.ResponseWriter which records the status code.
+type responseWriter struct {
+	http.ResponseWriter
+
+	// status is the status code written to client.
+	status int
+
+	// wroteHeader is a flag to check if header has been written.
+	wroteHeader bool
+
+	// bytes is the count of bytes of body written to client.
+	bytes int
+}
+
+// WriteHeader records status code and writes it to client.
+func (rw *responseWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes p to client, and http.StatusOK will be recorded if header hasn't been written.
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += n
+	return n, err
+}
+
+// Flush flushes data to client if the wrapped http.ResponseWriter is a http.Flusher.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the wrapped http.ResponseWriter is a http.Hijacker.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("logithttp: %T doesn't implement http.Hijacker", rw.ResponseWriter)
+}
+
+// Push initiates an HTTP/2 server push if the wrapped http.ResponseWriter is a http.Pusher.
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := rw.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Middleware returns a middleware which logs each request by logger.
+// See Request to know what fields will be carried, and bytes of body written will be carried, too.
+// If the wrapped handler panics, the panic will be logged as an error message
+// with the stack and then re-panicked, so the http server can handle it as usual.
+// The http.ErrAbortHandler panic is used to abort a response on purpose, so it will be
+// re-panicked without logging.
+// The wrapped http.ResponseWriter is still a http.Flusher, http.Hijacker and http.Pusher if
+// the original one is.
+// If you want to recover the panic, see RecoveringMiddleware.
+func Middleware(logger *logit.Logger) func(http.Handler) http.Handler {
+	return newMiddleware(logger, false)
+}
+
+// RecoveringMiddleware returns a middleware which logs each request by logger.
+// It's the same as Middleware except the panic will be recovered, and
+// http.StatusInternalServerError will be responded if header hasn't been written.
+func RecoveringMiddleware(logger *logit.Logger) func(http.Handler) http.Handler {
+	return newMiddleware(logger, true)
+}
+
+// newMiddleware returns a middleware which logs each request by logger.
+// The recovering decides if the panic in wrapped handler should be recovered.
+func newMiddleware(logger *logit.Logger, recovering bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			begin := time.Now()
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			defer func() {
+				err := recover()
+				if err == nil {
+					fields := requestFields(r, rw.status, time.Since(begin))
+					fields.Set(BytesKey, rw.bytes)
+					logger.InfoWith(fields, requestMsg)
+					return
+				}
+
+				// 主动中断响应的 panic 不是错误，直接交给 http server 处理
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				// 发生 panic 的请求，响应码按照 500 记录
+				if !rw.wroteHeader {
+					rw.status = http.StatusInternalServerError
+				}
+
+				fields := requestFields(r, rw.status, time.Since(begin))
+				fields.Set(logit.PanicKey, fmt.Sprintf("%v", err))
+				fields.Set(logit.StackKey, string(debug.Stack()))
+				logger.ErrorWith(fields, panicMsg)
+
+				if !recovering {
+					panic(err)
+				}
+
+				if !rw.wroteHeader {
+					rw.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/09 11:58:13
+
+package logithttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FishGoddess/logit"
+)
+
+// 解析 buffer 中的所有 Json 日志
+func parseLogs(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var logs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		log := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &log); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+// 测试记录请求日志的中间件
+func TestMiddleware(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.JsonEncoder(), ""))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(Middleware(logger)(mux))
+	defer server.Close()
+
+	for _, path := range []string{"/ok", "/missing", "/ok"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	logs := parseLogs(t, buffer)
+	if len(logs) != 3 {
+		t.Fatalf("每个请求都应该有一条日志！%d", len(logs))
+	}
+
+	wantStatus := []float64{http.StatusOK, http.StatusNotFound, http.StatusOK}
+	for i, log := range logs {
+		if log[StatusKey] != wantStatus[i] {
+			t.Fatalf("第 %d 条日志的响应码 %v 不正确！", i+1, log[StatusKey])
+		}
+	}
+}
+
+// 测试中间件对 panic 的处理
+func TestMiddlewarePanic(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.JsonEncoder(), ""))
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	// 恢复 panic 的中间件需要响应 500
+	recorder := httptest.NewRecorder()
+	RecoveringMiddleware(logger)(panicking).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("发生 panic 之后应该响应 500！%d", recorder.Code)
+	}
+
+	// 不恢复 panic 的中间件需要重新抛出 panic
+	func() {
+		defer func() {
+			if err := recover(); err != "boom" {
+				t.Fatalf("中间件应该重新抛出 panic！%v", err)
+			}
+		}()
+		Middleware(logger)(panicking).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	logs := parseLogs(t, buffer)
+	if len(logs) != 2 {
+		t.Fatalf("每个 panic 都应该有一条日志！%d", len(logs))
+	}
+
+	for i, log := range logs {
+		if log["level"] != "error" || log[logit.PanicKey] != "boom" || log[StatusKey] != float64(http.StatusInternalServerError) {
+			t.Fatalf("第 %d 条 panic 日志不正确！%v", i+1, log)
+		}
+
+		if stack, ok := log[logit.StackKey].(string); !ok || !strings.Contains(stack, "goroutine") {
+			t.Fatalf("第 %d 条 panic 日志没有堆栈信息！%v", i+1, log[logit.StackKey])
+		}
+	}
+}
+
+// 测试主动中断响应的 panic 不记录日志并且总是重新抛出
+func TestMiddlewareAbortHandler(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.JsonEncoder(), ""))
+	aborting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	for _, middleware := range []func(http.Handler) http.Handler{Middleware(logger), RecoveringMiddleware(logger)} {
+		func() {
+			defer func() {
+				if err := recover(); err != http.ErrAbortHandler {
+					t.Fatalf("中间件应该重新抛出 http.ErrAbortHandler！%v", err)
+				}
+			}()
+			middleware(aborting).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+	}
+
+	if buffer.Len() != 0 {
+		t.Fatalf("http.ErrAbortHandler 不应该记录日志！%s", buffer.String())
+	}
+}
+
+// 测试包装之后的 http.ResponseWriter 仍然实现了 http.Flusher、http.Hijacker 和 http.Pusher
+func TestMiddlewareResponseWriterInterfaces(t *testing.T) {
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(bytes.NewBuffer(nil), logit.JsonEncoder(), ""))
+
+	recorder := httptest.NewRecorder()
+	Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("包装之后的 http.ResponseWriter 应该实现 http.Flusher！")
+		}
+		flusher.Flush()
+
+		// httptest.ResponseRecorder 没有实现 http.Hijacker 和 http.Pusher，需要返回错误
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Fatal("不支持 http.Hijacker 的时候应该返回错误！")
+		}
+
+		if err := w.(http.Pusher).Push("/style.css", nil); err != http.ErrNotSupported {
+			t.Fatalf("不支持 http.Pusher 的时候应该返回 http.ErrNotSupported！%v", err)
+		}
+	})).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !recorder.Flushed {
+		t.Fatal("Flush 应该传递给原来的 http.ResponseWriter！")
+	}
+
+	// 真实的连接支持 http.Hijacker
+	server := httptest.NewServer(Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack 应该传递给原来的 http.ResponseWriter！%v", err)
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n"))
+		conn.Close()
+	})))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("Hijack 之后的响应不正确！%d", response.StatusCode)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/08 15:21:07
+
+package logithttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/FishGoddess/logit"
+)
+
+const (
+	// These are the keys of fields carried by the log of a http request.
+	MethodKey     = "method"
+	PathKey       = "path"
+	RemoteAddrKey = "remote_addr"
+	StatusKey     = "status"
+	LatencyKey    = "latency"
+	UserAgentKey  = "user_agent"
+	ProtoKey      = "proto"
+	RefererKey    = "referer"
+	UserKey       = "user"
+	BytesKey      = "bytes"
+
+	// requestMsg is the message of the log of a http request.
+	requestMsg = "http request"
+)
+
+// requestFields returns the standard fields of a http request.
+// The user exists only if r has a user of basic auth.
+func requestFields(r *http.Request, status int, latency time.Duration) logit.Fields {
+	fields := logit.Fields{
+		{Key: MethodKey, Value: r.Method},
+		{Key: PathKey, Value: r.URL.Path},
+		{Key: RemoteAddrKey, Value: r.RemoteAddr},
+		{Key: StatusKey, Value: status},
+		{Key: LatencyKey, Value: latency},
+		{Key: UserAgentKey, Value: r.UserAgent()},
+		{Key: ProtoKey, Value: r.Proto},
+		{Key: RefererKey, Value: r.Referer()},
+	}
+
+	if user, _, ok := r.BasicAuth(); ok {
+		fields.Set(UserKey, user)
+	}
+	return fields
+}
+
+// Request logs r as an info message with standard fields.
+// The fields are method, path, remote_addr, status, latency, user_agent, proto and referer,
+// and user will be carried if r has a user of basic auth.
+// The status is the status code of the response, and latency is the time spent on handling r.
+func Request(logger *logit.Logger, r *http.Request, status int, latency time.Duration) {
+	logger.InfoWith(requestFields(r, status, latency), requestMsg)
+}
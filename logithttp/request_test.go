@@ -0,0 +1,63 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/08 15:40:52
+
+package logithttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/logit"
+)
+
+// 测试记录 http 请求的日志
+func TestRequest(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, logit.JsonEncoder(), ""))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders?id=1", nil)
+	r.RemoteAddr = "10.0.0.1:52013"
+	r.Header.Set("User-Agent", "logit-test")
+	Request(logger, r, http.StatusCreated, 350*time.Millisecond)
+
+	log := map[string]interface{}{}
+	if err := json.Unmarshal(buffer.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"level":       "info",
+		"msg":         requestMsg,
+		MethodKey:     "POST",
+		PathKey:       "/orders",
+		RemoteAddrKey: "10.0.0.1:52013",
+		StatusKey:     float64(http.StatusCreated),
+		LatencyKey:    float64(350 * time.Millisecond),
+		UserAgentKey:  "logit-test",
+	}
+
+	for key, value := range want {
+		if log[key] != value {
+			t.Fatalf("字段 %s 的值 %v 不正确，应该是 %v！", key, log[key], value)
+		}
+	}
+}
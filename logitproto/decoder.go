@@ -0,0 +1,75 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 13:17:30
+
+package logitproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// maxRecordSize is the max size of a record Decoder accepts, which protects it from bad lengths.
+	maxRecordSize = 64 * 1024 * 1024
+)
+
+// Decoder reads the length-delimited records encoded by Encoder from a stream.
+type Decoder struct {
+	reader *bufio.Reader
+	buffer []byte
+}
+
+// NewDecoder returns a decoder reading records from reader.
+func NewDecoder(reader io.Reader) *Decoder {
+	return &Decoder{
+		reader: bufio.NewReader(reader),
+	}
+}
+
+// Decode reads and returns the next record.
+// It returns io.EOF if there are no more records, and io.ErrUnexpectedEOF if the last record is incomplete.
+func (d *Decoder) Decode() (*LogRecord, error) {
+	length, err := binary.ReadUvarint(d.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("%w: record size %d exceeds %d", InvalidRecordError, length, maxRecordSize)
+	}
+
+	if uint64(cap(d.buffer)) < length {
+		d.buffer = make([]byte, length)
+	}
+
+	d.buffer = d.buffer[:length]
+	if _, err = io.ReadFull(d.reader, d.buffer); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	record := &LogRecord{}
+	if err = record.Unmarshal(d.buffer); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
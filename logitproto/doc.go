@@ -0,0 +1,46 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 13:05:19
+
+/*
+Package logitproto provides an encoder encoding logs to length-delimited protobuf records.
+The schema is log_record.proto in this package, so consumers in other languages can decode them.
+
+1. Encoder:
+
+	// Encoder encodes a log to a LogRecord prefixed with its length in varint.
+	// Notice that the time is always in unix form of nanoseconds, so the time format is ignored.
+	handler := logit.NewStandardHandler(conn, logitproto.Encoder(), "")
+	logger := logit.NewLogger(logit.InfoLevel, handler)
+
+2. Decoder:
+
+	// Decoder reads the records encoded by Encoder one by one, and io.EOF will be returned at the end.
+	decoder := logitproto.NewDecoder(conn)
+	for {
+		record, err := decoder.Decode()
+		if err != nil {
+			break
+		}
+		fmt.Println(record.Level, record.Msg, record.Fields)
+	}
+
+Notice that this package doesn't import any protobuf library, and the wire format is encoded by hand,
+so logit won't have any new dependencies. Just generate the code from log_record.proto if you prefer
+the generated one.
+*/
+package logitproto // import "github.com/FishGoddess/logit/logitproto"
@@ -0,0 +1,53 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 13:14:52
+
+package logitproto
+
+import (
+	"fmt"
+
+	"github.com/FishGoddess/logit"
+)
+
+// Encoder returns an encoder encoding a log to a LogRecord in log_record.proto.
+// Each record is prefixed with its length in varint, which is the length-delimited framing,
+// so records can be written to a stream like a tcp connection one by one and read by Decoder.
+// The values of fields are rendered in string form by fmt, and the time is always in unix form
+// of nanoseconds, so timeFormat is ignored.
+func Encoder() logit.Encoder {
+	return func(log *logit.Log, timeFormat string) []byte {
+		record := LogRecord{
+			TimeUnixNano: log.Now().UnixNano(),
+			Level:        log.Level().String(),
+			Msg:          log.Msg(),
+			File:         log.File(),
+			Line:         int64(log.Line()),
+		}
+
+		fields := log.Fields()
+		if len(fields) > 0 {
+			record.Fields = make(map[string]string, len(fields))
+			for _, field := range fields {
+				record.Fields[field.Key] = fmt.Sprint(field.Value)
+			}
+		}
+
+		body := record.Marshal(nil)
+		return append(appendUvarint(make([]byte, 0, uvarintSize(uint64(len(body)))+len(body)), uint64(len(body))), body...)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 13:26:18
+
+package logitproto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/FishGoddess/logit"
+)
+
+// 测试编码后的字节和 protobuf 的线格式一致
+func TestLogRecordMarshal(t *testing.T) {
+	record := &LogRecord{
+		TimeUnixNano: 300,
+		Level:        "info",
+		Fields:       map[string]string{"k": "v"},
+	}
+
+	expect := []byte{
+		0x08, 0xac, 0x02, // time_unix_nano = 300
+		0x12, 0x04, 'i', 'n', 'f', 'o', // level = "info"
+		0x22, 0x06, 0x0a, 0x01, 'k', 0x12, 0x01, 'v', // fields = {"k": "v"}
+	}
+
+	data := record.Marshal(nil)
+	if !bytes.Equal(data, expect) {
+		t.Fatalf("编码后的字节不正确！%v", data)
+	}
+
+	// 加上未知的字段，解码时应该被跳过
+	data = append(data, 0x3d, 0x01, 0x02, 0x03, 0x04)
+	decoded := &LogRecord{}
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.TimeUnixNano != 300 || decoded.Level != "info" || decoded.Fields["k"] != "v" || len(decoded.Fields) != 1 {
+		t.Fatalf("解码后的记录不正确！%+v", decoded)
+	}
+
+	if err := decoded.Unmarshal([]byte{0x12, 0x10, 'i'}); !errors.Is(err, InvalidRecordError) {
+		t.Fatalf("解码错误的记录应该返回 InvalidRecordError！%v", err)
+	}
+}
+
+// 测试分帧的日志记录可以被逐条解码
+func TestEncoderRoundTrip(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := logit.NewLogger(logit.DebugLevel, logit.NewStandardHandler(buffer, Encoder(), ""))
+	logger.EnableFileInfo()
+
+	logger.Debug("debug")
+	logger.WithFields(map[string]interface{}{"id": 123, "ok": true}).Info("info")
+	logger.Error("")
+
+	decoder := NewDecoder(buffer)
+	expects := []struct {
+		level  string
+		msg    string
+		fields map[string]string
+	}{
+		{"debug", "debug", nil},
+		{"info", "info", map[string]string{"id": "123", "ok": "true"}},
+		{"error", "", nil},
+	}
+
+	for i, expect := range expects {
+		record, err := decoder.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if record.Level != expect.level || record.Msg != expect.msg || len(record.Fields) != len(expect.fields) {
+			t.Fatalf("第 %d 条记录不正确！%+v", i+1, record)
+		}
+
+		for key, value := range expect.fields {
+			if record.Fields[key] != value {
+				t.Fatalf("第 %d 条记录的字段不正确！%+v", i+1, record)
+			}
+		}
+
+		if record.TimeUnixNano == 0 || record.File == "" || record.Line == 0 {
+			t.Fatalf("第 %d 条记录的时间或者文件信息不正确！%+v", i+1, record)
+		}
+	}
+
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Fatalf("没有更多记录时应该返回 io.EOF！%v", err)
+	}
+
+	// 不完整的记录
+	truncated := bytes.NewReader([]byte{0x05, 0x12, 0x03})
+	if _, err := NewDecoder(truncated).Decode(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("不完整的记录应该返回 io.ErrUnexpectedEOF！%v", err)
+	}
+}
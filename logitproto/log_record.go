@@ -0,0 +1,250 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 13:11:06
+
+package logitproto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// The numbers of fields in LogRecord, see log_record.proto.
+	timeUnixNanoNumber = 1
+	levelNumber        = 2
+	msgNumber          = 3
+	fieldsNumber       = 4
+	fileNumber         = 5
+	lineNumber         = 6
+
+	// The numbers of key and value in the entry of map.
+	entryKeyNumber   = 1
+	entryValueNumber = 2
+)
+
+const (
+	// The wire types of protobuf.
+	varintType  = 0
+	fixed64Type = 1
+	bytesType   = 2
+	fixed32Type = 5
+)
+
+var (
+	// InvalidRecordError is the error returned when decoding a record which isn't a valid LogRecord.
+	InvalidRecordError = errors.New("logitproto: invalid log record")
+)
+
+// LogRecord is the Go form of LogRecord in log_record.proto.
+type LogRecord struct {
+
+	// TimeUnixNano is the time of log in unix form of nanoseconds.
+	TimeUnixNano int64
+
+	// Level is the name of level, like "info".
+	Level string
+
+	// Msg is the message of log.
+	Msg string
+
+	// Fields is the fields of log, and values are in string form.
+	Fields map[string]string
+
+	// File and Line are the caller info.
+	File string
+	Line int64
+}
+
+// Marshal returns the protobuf wire form of r appended to dst.
+// The entries of fields are sorted by key, so the same record is always marshaled to the same bytes.
+func (r *LogRecord) Marshal(dst []byte) []byte {
+	if r.TimeUnixNano != 0 {
+		dst = appendTag(dst, timeUnixNanoNumber, varintType)
+		dst = appendUvarint(dst, uint64(r.TimeUnixNano))
+	}
+
+	dst = appendString(dst, levelNumber, r.Level)
+	dst = appendString(dst, msgNumber, r.Msg)
+
+	keys := make([]string, 0, len(r.Fields))
+	for key := range r.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		dst = appendTag(dst, fieldsNumber, bytesType)
+		dst = appendUvarint(dst, uint64(entrySize(key, r.Fields[key])))
+		dst = appendString(dst, entryKeyNumber, key)
+		dst = appendString(dst, entryValueNumber, r.Fields[key])
+	}
+
+	dst = appendString(dst, fileNumber, r.File)
+	if r.Line != 0 {
+		dst = appendTag(dst, lineNumber, varintType)
+		dst = appendUvarint(dst, uint64(r.Line))
+	}
+	return dst
+}
+
+// Unmarshal parses the protobuf wire form in data to r.
+// Unknown fields will be skipped, so records from newer schemas can be parsed.
+func (r *LogRecord) Unmarshal(data []byte) error {
+	*r = LogRecord{}
+	for len(data) > 0 {
+		number, wireType, value, n, err := consumeField(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		switch {
+		case number == timeUnixNanoNumber && wireType == varintType:
+			r.TimeUnixNano = int64(value.(uint64))
+		case number == levelNumber && wireType == bytesType:
+			r.Level = string(value.([]byte))
+		case number == msgNumber && wireType == bytesType:
+			r.Msg = string(value.([]byte))
+		case number == fieldsNumber && wireType == bytesType:
+			key, val, err := unmarshalEntry(value.([]byte))
+			if err != nil {
+				return err
+			}
+
+			if r.Fields == nil {
+				r.Fields = make(map[string]string)
+			}
+			r.Fields[key] = val
+		case number == fileNumber && wireType == bytesType:
+			r.File = string(value.([]byte))
+		case number == lineNumber && wireType == varintType:
+			r.Line = int64(value.(uint64))
+		}
+	}
+	return nil
+}
+
+// unmarshalEntry parses an entry of map<string, string>.
+func unmarshalEntry(data []byte) (key string, value string, err error) {
+	for len(data) > 0 {
+		number, wireType, v, n, err := consumeField(data)
+		if err != nil {
+			return "", "", err
+		}
+		data = data[n:]
+
+		if wireType != bytesType {
+			continue
+		}
+
+		if number == entryKeyNumber {
+			key = string(v.([]byte))
+		} else if number == entryValueNumber {
+			value = string(v.([]byte))
+		}
+	}
+	return key, value, nil
+}
+
+// consumeField parses a field at the beginning of data, and returns the length of bytes it takes.
+// The value is an uint64 for varint and fixed types, and a []byte for bytes type.
+func consumeField(data []byte) (number int, wireType int, value interface{}, n int, err error) {
+	tag, n := binary.Uvarint(data)
+	if n <= 0 || tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+		return 0, 0, nil, 0, fmt.Errorf("%w: bad tag", InvalidRecordError)
+	}
+
+	number = int(tag >> 3)
+	wireType = int(tag & 7)
+	switch wireType {
+	case varintType:
+		v, m := binary.Uvarint(data[n:])
+		if m <= 0 {
+			return 0, 0, nil, 0, fmt.Errorf("%w: bad varint of field %d", InvalidRecordError, number)
+		}
+		return number, wireType, v, n + m, nil
+	case fixed64Type:
+		if len(data[n:]) < 8 {
+			return 0, 0, nil, 0, fmt.Errorf("%w: bad fixed64 of field %d", InvalidRecordError, number)
+		}
+		return number, wireType, binary.LittleEndian.Uint64(data[n:]), n + 8, nil
+	case bytesType:
+		length, m := binary.Uvarint(data[n:])
+		if m <= 0 || length > uint64(len(data[n+m:])) {
+			return 0, 0, nil, 0, fmt.Errorf("%w: bad length of field %d", InvalidRecordError, number)
+		}
+		start := n + m
+		return number, wireType, data[start : start+int(length)], start + int(length), nil
+	case fixed32Type:
+		if len(data[n:]) < 4 {
+			return 0, 0, nil, 0, fmt.Errorf("%w: bad fixed32 of field %d", InvalidRecordError, number)
+		}
+		return number, wireType, uint64(binary.LittleEndian.Uint32(data[n:])), n + 4, nil
+	default:
+		return 0, 0, nil, 0, fmt.Errorf("%w: unsupported wire type %d", InvalidRecordError, wireType)
+	}
+}
+
+// appendTag appends the tag of a field to dst.
+func appendTag(dst []byte, number int, wireType int) []byte {
+	return appendUvarint(dst, uint64(number)<<3|uint64(wireType))
+}
+
+// appendString appends a string field to dst, and empty string will be skipped as proto3 does.
+func appendString(dst []byte, number int, s string) []byte {
+	if s == "" {
+		return dst
+	}
+
+	dst = appendTag(dst, number, bytesType)
+	dst = appendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// entrySize returns the size of an entry of map<string, string> in wire form.
+func entrySize(key string, value string) int {
+	size := 0
+	if key != "" {
+		size += 1 + uvarintSize(uint64(len(key))) + len(key)
+	}
+
+	if value != "" {
+		size += 1 + uvarintSize(uint64(len(value))) + len(value)
+	}
+	return size
+}
+
+// uvarintSize returns the size of v in varint form.
+func uvarintSize(v uint64) int {
+	size := 1
+	for v >= 0x80 {
+		v >>= 7
+		size++
+	}
+	return size
+}
+
+// appendUvarint appends v in varint form to dst.
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buffer [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buffer[:], v)
+	return append(dst, buffer[:n]...)
+}
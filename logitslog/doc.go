@@ -0,0 +1,32 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 23:51:06
+
+/*
+Package logitslog provides a slog.Handler backed by logit, so you can code against log/slog
+while logit does the actual output, such as rolling files.
+
+1. Handler:
+
+	// NewHandler returns a slog.Handler which logs records by a logit logger.
+	// The attrs will be the fields of logs, and the keys in groups will be prefixed like "group.key".
+	// Notice that this package needs Go 1.21 or later, and the core of logit doesn't.
+	logger := slog.New(logitslog.NewHandler(logitLogger))
+	logger.Info("hello", "user", "fish", slog.Group("req", "id", 1))
+
+*/
+package logitslog // import "github.com/FishGoddess/logit/logitslog"
@@ -0,0 +1,148 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 00:12:45
+
+// +build go1.21
+
+package logitslog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FishGoddess/logit"
+)
+
+// Handler is a slog.Handler which logs records by a logit logger.
+type Handler struct {
+	logger *logit.Logger
+
+	// fields is the fields carried by all records, which are from WithAttrs.
+	// Notice that it will never be modified after being set, because it may be shared.
+	fields logit.Fields
+
+	// prefix is the prefix of keys in groups, like "group.", which is from WithGroup.
+	prefix string
+}
+
+// NewHandler returns a slog.Handler which logs records by logger.
+// The levels of records will be mapped to the nearest logit levels, such as slog.LevelWarn+1 is
+// logit.WarnLevel. The attrs will be the fields of logs, and the keys in groups will be prefixed
+// like "group.key". Notice that the time of log is the time of logit handling it, not the time
+// of record, and the caller info is controlled by logger.
+func NewHandler(logger *logit.Logger) slog.Handler {
+	return &Handler{
+		logger: logger,
+	}
+}
+
+// levelOf returns the logit level of slog level.
+func levelOf(level slog.Level) logit.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return logit.DebugLevel
+	case level < slog.LevelWarn:
+		return logit.InfoLevel
+	case level < slog.LevelError:
+		return logit.WarnLevel
+	default:
+		return logit.ErrorLevel
+	}
+}
+
+// Enabled returns true if the record in level will be logged by logger.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(levelOf(level))
+}
+
+// Handle logs record by logger with the fields of h and the attrs of record.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logit.Fields, 0, len(h.fields)+record.NumAttrs())
+	fields = append(fields, h.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	switch levelOf(record.Level) {
+	case logit.DebugLevel:
+		h.logger.DebugWith(fields, record.Message)
+	case logit.InfoLevel:
+		h.logger.InfoWith(fields, record.Message)
+	case logit.WarnLevel:
+		h.logger.WarnWith(fields, record.Message)
+	default:
+		h.logger.ErrorWith(fields, record.Message)
+	}
+	return nil
+}
+
+// WithAttrs returns a new handler whose fields include attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) < 1 {
+		return h
+	}
+
+	// 写时复制，原来的字段可能正在被使用
+	fields := make(logit.Fields, 0, len(h.fields)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.prefix, attr)
+	}
+
+	return &Handler{
+		logger: h.logger,
+		fields: fields,
+		prefix: h.prefix,
+	}
+}
+
+// WithGroup returns a new handler whose keys of attrs after it will be prefixed with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &Handler{
+		logger: h.logger,
+		fields: h.fields,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// appendAttr appends attr to fields with prefix, and the attrs in a group will be flattened.
+// The empty attrs and the groups without any attrs will be ignored, which is the rule of slog.
+func appendAttr(fields logit.Fields, prefix string, attr slog.Attr) logit.Fields {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		return append(fields, logit.Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+	}
+
+	// 没有 key 的分组直接内联到当前的层级
+	if attr.Key != "" {
+		prefix = prefix + attr.Key + "."
+	}
+
+	for _, groupAttr := range attr.Value.Group() {
+		fields = appendAttr(fields, prefix, groupAttr)
+	}
+	return fields
+}
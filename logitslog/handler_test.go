@@ -0,0 +1,93 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 00:40:27
+
+// +build go1.21
+
+package logitslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/logit"
+)
+
+// 解析 buffer 中的每一行 Json 日志
+func decodeLogs(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var logs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		log := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &log); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+// 测试通过 slog 记录日志
+func TestHandler(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := slog.New(NewHandler(logit.NewLogger(logit.InfoLevel, logit.NewStandardHandler(buffer, logit.JsonEncoder(), ""))))
+
+	logger.Debug("debug")
+	logger.Info("hello", "user", "fish", "latency", 350*time.Millisecond)
+	logger.With("service", "order").WithGroup("req").Warn("slow", "id", 1, slog.Group("db", "table", "orders"))
+	logger.Log(context.Background(), slog.LevelError+4, "boom", slog.Group("empty"), slog.Attr{})
+
+	logs := decodeLogs(t, buffer)
+	if len(logs) != 3 {
+		t.Fatalf("日志条数不正确！%d %s", len(logs), buffer.String())
+	}
+
+	if logs[0]["level"] != "info" || logs[0]["msg"] != "hello" || logs[0]["user"] != "fish" || logs[0]["latency"] != float64(350*time.Millisecond) {
+		t.Fatalf("第 1 条日志不正确！%v", logs[0])
+	}
+
+	if logs[1]["level"] != "warn" || logs[1]["service"] != "order" || logs[1]["req.id"] != float64(1) || logs[1]["req.db.table"] != "orders" {
+		t.Fatalf("第 2 条日志不正确！%v", logs[1])
+	}
+
+	// 空的属性和空的分组会被忽略
+	if logs[2]["level"] != "error" || logs[2]["msg"] != "boom" || len(logs[2]) != 3 {
+		t.Fatalf("第 3 条日志不正确！%v", logs[2])
+	}
+}
+
+// 测试日志级别的判断
+func TestHandlerEnabled(t *testing.T) {
+	handler := NewHandler(logit.NewLogger(logit.WarnLevel, logit.NewStandardHandler(bytes.NewBuffer(nil), logit.TextEncoder(), "")))
+	expects := map[slog.Level]bool{
+		slog.LevelDebug:    false,
+		slog.LevelInfo:     false,
+		slog.LevelInfo + 2: false,
+		slog.LevelWarn:     true,
+		slog.LevelError:    true,
+	}
+
+	for level, expect := range expects {
+		if handler.Enabled(context.Background(), level) != expect {
+			t.Fatalf("级别 %s 是否记录的结果不正确！", level)
+		}
+	}
+}
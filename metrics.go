@@ -0,0 +1,77 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 16:42:08
+
+package logit
+
+const (
+	// LogsCounter is the name of counter increased by one for every log handled by logger.
+	// Its labels have a "level" key, whose value is the name of level like "info".
+	LogsCounter = "logit_logs_total"
+
+	// DroppedCounter is the name of counter increased by one for every log dropped by handler.
+	// Its labels have a "handler" key, whose value is the kind of handler like "smoothing".
+	DroppedCounter = "logit_dropped_total"
+)
+
+// MetricsSink is an interface representation of something receiving metrics of logging.
+// Logger and handlers report counts through it, so you can export them to your monitoring
+// system like Prometheus by implementing it, and logit doesn't need to import any of them.
+// See Logger.SetMetricsSink, LogsCounter and DroppedCounter.
+type MetricsSink interface {
+
+	// IncCounter increases the counter called name with labels by one.
+	// Notice that it may be called concurrently, and labels shouldn't be retained or modified
+	// because they may be shared by all calls.
+	IncCounter(name string, labels map[string]string)
+}
+
+// logsCounterLabels stores the labels of LogsCounter of each level, so logging won't allocate them every time.
+var logsCounterLabels = func() map[Level]map[string]string {
+	labels := make(map[Level]map[string]string, len(levels))
+	for level, name := range levels {
+		labels[level] = map[string]string{"level": name}
+	}
+	return labels
+}()
+
+// nopMetricsSink is a sink doing nothing, which is the default sink of logger.
+type nopMetricsSink struct{}
+
+// IncCounter does nothing.
+func (nopMetricsSink) IncCounter(name string, labels map[string]string) {}
+
+// reportLogs increases LogsCounter of level in sink.
+// It won't do anything if sink is the default nopMetricsSink.
+func reportLogs(sink MetricsSink, level Level) {
+	if _, ok := sink.(nopMetricsSink); ok {
+		return
+	}
+
+	labels, ok := logsCounterLabels[level]
+	if !ok {
+		labels = map[string]string{"level": level.String()}
+	}
+	sink.IncCounter(LogsCounter, labels)
+}
+
+// reportDropped increases DroppedCounter of the logger of log with handler kind.
+func reportDropped(log *Log, handler string) {
+	if log.logger != nil {
+		log.logger.metrics().IncCounter(DroppedCounter, map[string]string{"handler": handler})
+	}
+}
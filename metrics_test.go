@@ -0,0 +1,123 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 17:05:33
+
+package logit
+
+import (
+	"sync"
+	"testing"
+)
+
+// fakeMetricsSink records counts of counters in memory for testing.
+type fakeMetricsSink struct {
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func (fms *fakeMetricsSink) IncCounter(name string, labels map[string]string) {
+	fms.mu.Lock()
+	defer fms.mu.Unlock()
+
+	key := name
+	for _, label := range []string{"level", "handler"} {
+		if value, ok := labels[label]; ok {
+			key += "," + label + "=" + value
+		}
+	}
+	fms.counts[key]++
+}
+
+// 测试每个级别的日志计数
+func TestLoggerSetMetricsSink(t *testing.T) {
+	sink := &fakeMetricsSink{counts: map[string]int{}}
+	logger := NewLogger(InfoLevel, NewStandardHandler(&syncBuffer{}, TextEncoder(), DefaultTimeFormat))
+	logger.SetMetricsSink(sink)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	want := map[string]int{
+		LogsCounter + ",level=info":  2,
+		LogsCounter + ",level=warn":  1,
+		LogsCounter + ",level=error": 1,
+	}
+	if len(sink.counts) != len(want) {
+		t.Fatalf("计数器的数量不正确！%v", sink.counts)
+	}
+
+	for key, count := range want {
+		if sink.counts[key] != count {
+			t.Fatalf("计数器 %s 的值不正确！%d", key, sink.counts[key])
+		}
+	}
+
+	// 设置为 nil 之后恢复成什么都不做的 sink
+	logger.SetMetricsSink(nil)
+	logger.Info("info")
+	if sink.counts[LogsCounter+",level=info"] != 2 {
+		t.Fatalf("恢复默认的 sink 之后仍然有计数！%v", sink.counts)
+	}
+}
+
+// 测试日志处理器丢弃日志的计数
+func TestMetricsSinkDropped(t *testing.T) {
+	sink := &fakeMetricsSink{counts: map[string]int{}}
+	handler, _ := NewChannelHandler(1)
+	logger := NewLogger(DebugLevel, handler)
+	logger.SetMetricsSink(sink)
+
+	for i := 0; i < 3; i++ {
+		logger.Info("info")
+	}
+
+	if sink.counts[DroppedCounter+",handler=channel"] != 2 || handler.Dropped() != 2 {
+		t.Fatalf("丢弃日志的计数不正确！%v", sink.counts)
+	}
+}
+
+// labelsMetricsSink records the labels of the last call for testing.
+type labelsMetricsSink struct {
+	labels map[string]string
+}
+
+func (lms *labelsMetricsSink) IncCounter(name string, labels map[string]string) {
+	lms.labels = labels
+}
+
+// 测试记录日志计数的时候不会分配内存
+func TestReportLogsAllocs(t *testing.T) {
+	sinks := []MetricsSink{nopMetricsSink{}, &labelsMetricsSink{}}
+	for _, sink := range sinks {
+		allocs := testing.AllocsPerRun(100, func() {
+			reportLogs(sink, InfoLevel)
+		})
+
+		if allocs != 0 {
+			t.Fatalf("%T 记录日志计数的时候分配了内存！%v", sink, allocs)
+		}
+	}
+
+	sink := &labelsMetricsSink{}
+	reportLogs(sink, WarnLevel)
+	if sink.labels["level"] != "warn" {
+		t.Fatalf("日志计数的标签不正确！%v", sink.labels)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 15:10:27
+
+package logit
+
+// minLevelHandler is a level filter handler.
+// It only handles logs whose levels are not lower than its level, so different handlers can
+// have different visibilities in one logger. For example, you want all logs are written to
+// console but only error level logs are written to log file, then you can use this handler to do it.
+type minLevelHandler struct {
+
+	// level is the min level of log that can be handled by this handler.
+	// See logit.Level.
+	level Level
+
+	// handlers is all handlers used to handle logs not lower than level.
+	// See logit.Handler.
+	handlers []Handler
+}
+
+// NewMinLevelHandler returns a handler handled logs whose levels are not lower than level.
+// You can add more than one handler to this handler. This handler is just like a
+// wrapper wrapping some handlers.
+//
+// For config:
+//     You don't need to register it, and just add a "level" param to any handler in config:
+//
+//         "handlers": {
+//             "console": {
+//                 "level": "debug"
+//             },
+//             "file": {
+//                 "level": "error",
+//                 "path": "D:/logit.error.log"
+//             }
+//         }
+//
+func NewMinLevelHandler(level Level, handlers ...Handler) Handler {
+	return &minLevelHandler{
+		level:    level,
+		handlers: handlers,
+	}
+}
+
+// Handle handles a log with handlers in mlh.
+// Notice that the handling process will be interrupted if one of them
+// returned false. However, this method will always return true, so the handlers
+// after it will always be used.
+func (mlh *minLevelHandler) Handle(log *Log) bool {
+	if log.Level() >= mlh.level {
+		for _, handler := range mlh.handlers {
+			if !handler.Handle(log) {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// Flush flushes all handlers inside which are Flushers.
+func (mlh *minLevelHandler) Flush() error {
+	_, err := flushHandlers(mlh.handlers)
+	return err
+}
+
+// FlushWithResult flushes all handlers inside which are Flushers, and returns the sum of results.
+func (mlh *minLevelHandler) FlushWithResult() (FlushResult, error) {
+	return flushHandlers(mlh.handlers)
+}
+
+// Close closes all handlers inside which are io.Closers.
+func (mlh *minLevelHandler) Close() error {
+	return closeHandlers(mlh.handlers)
+}
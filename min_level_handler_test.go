@@ -0,0 +1,35 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 15:21:09
+
+package logit
+
+import "testing"
+
+// 测试只处理不低于某个级别的日志
+func TestMinLevelHandler(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, NewMinLevelHandler(WarnLevel, handler))
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	if len(handler.logs) != 2 || handler.logs[0]["msg"] != "warn" || handler.logs[1]["msg"] != "error" {
+		t.Fatalf("只应该处理 warn 和 error 级别的日志！%v", handler.logs)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:15:08
+
+package logit
+
+import "time"
+
+const (
+	// MonoKey is the key of the monotonic reading of logs in encoded logs. See Logger.SetMonotonic.
+	MonoKey = "mono_ns"
+)
+
+var (
+	// processStart is the time when this process starts, and it carries a monotonic clock reading.
+	// The monotonic reading of logs is the duration since it.
+	processStart = time.Now()
+)
+
+// monoSince returns the duration since process start measured by the monotonic clock.
+// It's always positive, so a zero duration means the log doesn't carry a monotonic reading.
+func monoSince() time.Duration {
+	mono := time.Since(processStart)
+	if mono <= 0 {
+		mono = 1
+	}
+	return mono
+}
+
+// SetMonotonic sets whether logs carry a monotonic clock reading, which is the duration since process start.
+// Wall clock may jump on NTP adjustments, but monotonic clock never goes backward, so it's reliable for
+// ordering logs and computing latencies within a process. The reading is independent of the clock set by
+// Logger.SetClock, and encoders write it in nanoseconds with MonoKey. Default is false. See Log.Mono.
+func (l *Logger) SetMonotonic(monotonic bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.monotonic = monotonic
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 20:52:17
+
+package logit
+
+import "strings"
+
+// newlineReplacer replaces "\r\n" and the lone "\r" with "\n".
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// normalizeMsg returns msg whose line endings are "\n" and trailing line endings are removed.
+// Encoders always end a log with a "\n", so a log will end with a single "\n" after normalized.
+func normalizeMsg(msg string) string {
+	if strings.IndexByte(msg, '\r') >= 0 {
+		msg = newlineReplacer.Replace(msg)
+	}
+	return strings.TrimRight(msg, "\n")
+}
+
+// normalizeFields returns fields whose string values use "\n" as line endings.
+// If no field is changed, fields will be returned directly without any allocation.
+func normalizeFields(fields []Field) []Field {
+	var normalized []Field
+	for i, field := range fields {
+		value, ok := field.Value.(string)
+		if !ok || strings.IndexByte(value, '\r') < 0 {
+			continue
+		}
+
+		// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+		if normalized == nil {
+			normalized = make([]Field, len(fields))
+			copy(normalized, fields)
+		}
+		normalized[i].Value = newlineReplacer.Replace(value)
+	}
+
+	if normalized == nil {
+		return fields
+	}
+	return normalized
+}
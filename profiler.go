@@ -0,0 +1,186 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 15:42:18
+
+package logit
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// LatencyStats is the statistics of latencies.
+type LatencyStats struct {
+
+	// Count is the count of latencies recorded.
+	Count int64
+
+	// Min, Max and Avg are the min, the max and the average of latencies.
+	Min time.Duration
+	Max time.Duration
+	Avg time.Duration
+
+	// P99 is the 99th percentile of latencies. It's estimated by a histogram whose buckets
+	// are powers of 2 in nanoseconds, so it's the upper bound of the bucket, but never over Max.
+	P99 time.Duration
+}
+
+// PerfStats is the performance statistics of a logger. See Logger.SetProfiling.
+type PerfStats struct {
+
+	// Encode is the latencies of encoding logs in standard handlers.
+	Encode LatencyStats
+
+	// Write is the latencies of writing logs in standard handlers, including waiting for other writings.
+	Write LatencyStats
+
+	// Handle is the latencies of handling logs by all handlers of logger, which includes Encode and Write.
+	Handle LatencyStats
+}
+
+// histogram records latencies in buckets, and the bucket i holds latencies whose bit length is i.
+type histogram struct {
+	count   int64
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+	buckets [65]int64
+}
+
+// record records a latency to h.
+func (h *histogram) record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+
+	if h.count == 0 || latency < h.min {
+		h.min = latency
+	}
+
+	if latency > h.max {
+		h.max = latency
+	}
+
+	h.count++
+	h.sum += latency
+	h.buckets[bits.Len64(uint64(latency))]++
+}
+
+// stats returns the statistics of latencies recorded in h.
+func (h *histogram) stats() LatencyStats {
+	if h.count == 0 {
+		return LatencyStats{}
+	}
+
+	stats := LatencyStats{
+		Count: h.count,
+		Min:   h.min,
+		Max:   h.max,
+		Avg:   h.sum / time.Duration(h.count),
+		P99:   h.max,
+	}
+
+	// 找到累计数量达到 99% 的桶，这个桶的上界就是 p99 的估计值
+	target := (h.count*99 + 99) / 100
+	accumulated := int64(0)
+	for i, count := range h.buckets {
+		accumulated += count
+		if accumulated >= target {
+			if upper := time.Duration(uint64(1)<<uint(i) - 1); upper < stats.P99 {
+				stats.P99 = upper
+			}
+			break
+		}
+	}
+	return stats
+}
+
+// profiler records the latencies of handling logs. See Logger.SetProfiling.
+type profiler struct {
+	encode histogram
+	write  histogram
+	handle histogram
+	mu     sync.Mutex
+}
+
+// recordEncode records the latency of encoding a log since begin.
+func (p *profiler) recordEncode(begin time.Time) {
+	latency := time.Since(begin)
+	p.mu.Lock()
+	p.encode.record(latency)
+	p.mu.Unlock()
+}
+
+// recordWrite records the latency of writing a log since begin.
+func (p *profiler) recordWrite(begin time.Time) {
+	latency := time.Since(begin)
+	p.mu.Lock()
+	p.write.record(latency)
+	p.mu.Unlock()
+}
+
+// recordHandle records the latency of handling a log since begin.
+func (p *profiler) recordHandle(begin time.Time) {
+	latency := time.Since(begin)
+	p.mu.Lock()
+	p.handle.record(latency)
+	p.mu.Unlock()
+}
+
+// stats returns the performance statistics recorded by p.
+func (p *profiler) stats() PerfStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PerfStats{
+		Encode: p.encode.stats(),
+		Write:  p.write.stats(),
+		Handle: p.handle.stats(),
+	}
+}
+
+// SetProfiling sets if l should record the latencies of handling logs, which helps to find out if
+// logging becomes a bottleneck. Call Logger.PerfStats to get the statistics. Encoding and writing
+// are recorded by standard handlers only, and handling is recorded for all handlers of logger.
+// Child loggers created after enabling share the statistics. Disabling it drops the statistics,
+// and it costs nothing when disabled, which is the default.
+func (l *Logger) SetProfiling(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !enabled {
+		l.profiler = nil
+		return
+	}
+
+	if l.profiler == nil {
+		l.profiler = &profiler{}
+	}
+}
+
+// PerfStats returns the performance statistics of l.
+// Return a zero PerfStats if profiling is disabled. See Logger.SetProfiling.
+func (l *Logger) PerfStats() PerfStats {
+	l.mu.RLock()
+	profiler := l.profiler
+	l.mu.RUnlock()
+
+	if profiler == nil {
+		return PerfStats{}
+	}
+	return profiler.stats()
+}
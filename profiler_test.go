@@ -0,0 +1,75 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 15:58:36
+
+package logit
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// 测试开启性能分析之后记录编码和写入的耗时
+func TestLoggerSetProfiling(t *testing.T) {
+	logger := NewLogger(InfoLevel, NewStandardHandler(ioutil.Discard, JsonEncoder(), DefaultTimeFormat))
+	logger.Info("not profiled")
+	if stats := logger.PerfStats(); stats != (PerfStats{}) {
+		t.Fatalf("没有开启性能分析时不应该有统计数据！%+v", stats)
+	}
+
+	logger.SetProfiling(true)
+	for i := 0; i < 100; i++ {
+		logger.Info("profiled")
+	}
+	logger.Debug("ignored")
+
+	stats := logger.PerfStats()
+	for name, s := range map[string]LatencyStats{"encode": stats.Encode, "write": stats.Write, "handle": stats.Handle} {
+		if s.Count != 100 {
+			t.Fatalf("%s 的统计数量不正确！%+v", name, s)
+		}
+
+		if s.Max <= 0 || s.Min > s.Avg || s.Avg > s.Max || s.P99 < s.Min || s.P99 > s.Max {
+			t.Fatalf("%s 的统计数据不正确！%+v", name, s)
+		}
+	}
+
+	logger.SetProfiling(false)
+	if stats := logger.PerfStats(); stats != (PerfStats{}) {
+		t.Fatalf("关闭性能分析之后不应该有统计数据！%+v", stats)
+	}
+}
+
+// 测试直方图估计的 p99
+func TestHistogramStats(t *testing.T) {
+	h := &histogram{}
+	for i := 0; i < 99; i++ {
+		h.record(100 * time.Nanosecond)
+	}
+	h.record(time.Second)
+
+	stats := h.stats()
+	if stats.Count != 100 || stats.Min != 100*time.Nanosecond || stats.Max != time.Second {
+		t.Fatalf("统计数据不正确！%+v", stats)
+	}
+
+	// 100ns 在 [64ns, 128ns) 的桶里，所以 p99 的估计值是 127ns
+	if stats.P99 != 127*time.Nanosecond {
+		t.Fatalf("p99 的估计值不正确！%v", stats.P99)
+	}
+}
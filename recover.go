@@ -0,0 +1,92 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/11 22:45:03
+
+package logit
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+const (
+	// These are the keys of fields carried by the log of a recovered panic.
+	PanicKey = "panic"
+	StackKey = "stack"
+
+	// recoveredMsg is the message of the log of a recovered panic.
+	recoveredMsg = "recovered from panic"
+)
+
+// Recover recovers the panic and logs it as an error message with the stack.
+// It must be called by defer directly, or it won't recover anything:
+//
+//     go func() {
+//         defer logit.Recover(logger)
+//         // Do something may panic...
+//     }()
+//
+// So the panicking goroutine won't take down the whole process.
+// If you want to re-panic after logging, see RecoverAndPanic.
+func Recover(logger *Logger) {
+	if err := recover(); err != nil {
+		logPanic(logger, err)
+	}
+}
+
+// RecoverAndPanic is the same as Recover except it will re-panic after logging.
+// It must be called by defer directly, or it won't recover anything.
+func RecoverAndPanic(logger *Logger) {
+	if err := recover(); err != nil {
+		logPanic(logger, err)
+		panic(err)
+	}
+}
+
+// RecoverWith is the same as Recover except the log will carry fields, such as the id of request,
+// so you know the context of the panic in request-scoped goroutines:
+//
+//     go func() {
+//         defer logit.RecoverWith(logger, map[string]interface{}{"request_id": id})
+//         // Do something may panic...
+//     }()
+//
+// It must be called by defer directly, or it won't recover anything.
+// If you want to re-panic after logging, see RecoverAndPanicWith.
+func RecoverWith(logger *Logger, fields map[string]interface{}) {
+	if err := recover(); err != nil {
+		logPanic(logger, err, fieldsOf(fields)...)
+	}
+}
+
+// RecoverAndPanicWith is the same as RecoverWith except it will re-panic after logging.
+// It must be called by defer directly, or it won't recover anything.
+func RecoverAndPanicWith(logger *Logger, fields map[string]interface{}) {
+	if err := recover(); err != nil {
+		logPanic(logger, err, fieldsOf(fields)...)
+		panic(err)
+	}
+}
+
+// logPanic logs err as an error message with the stack.
+// The contextual fields will be carried before the panic and the stack.
+func logPanic(logger *Logger, err interface{}, fields ...Field) {
+	logger.ErrorWith(append(fields,
+		Field{Key: PanicKey, Value: fmt.Sprintf("%v", err)},
+		Field{Key: StackKey, Value: string(debug.Stack())},
+	), recoveredMsg)
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/11 23:10:37
+
+package logit
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 测试恢复 goroutine 中的 panic
+func TestRecover(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+
+	group := sync.WaitGroup{}
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		defer Recover(logger)
+		panic("goroutine boom")
+	}()
+	group.Wait()
+
+	output := buffer.String()
+	if !strings.HasPrefix(output, "[error]") || !strings.Contains(output, recoveredMsg+` panic="goroutine boom" stack="`) {
+		t.Fatalf("panic 没有被记录为 error 日志！%s", output)
+	}
+
+	if !strings.Contains(output, "TestRecover") {
+		t.Fatalf("panic 日志没有包含堆栈信息！%s", output)
+	}
+}
+
+// 测试恢复 panic 并重新抛出
+func TestRecoverAndPanic(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, TextEncoder(), DefaultTimeFormat))
+
+	defer func() {
+		if err := recover(); err != "boom again" {
+			t.Fatalf("RecoverAndPanic 应该重新抛出 panic！%v", err)
+		}
+
+		if !strings.Contains(buffer.String(), `panic="boom again"`) {
+			t.Fatalf("panic 没有被记录！%s", buffer.String())
+		}
+	}()
+
+	func() {
+		defer RecoverAndPanic(logger)
+		panic("boom again")
+	}()
+}
+
+// 测试恢复 panic 时携带上下文字段
+func TestRecoverWith(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+
+	group := sync.WaitGroup{}
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		defer RecoverWith(logger, map[string]interface{}{"request_id": "req-1", "user": "fish"})
+		panic("request boom")
+	}()
+	group.Wait()
+
+	if len(handler.logs) != 1 {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	m := handler.logs[0]
+	if m["level"] != ErrorLevel || m["msg"] != recoveredMsg || m["request_id"] != "req-1" || m["user"] != "fish" || m[PanicKey] != "request boom" {
+		t.Fatalf("panic 日志没有携带上下文字段！%v", m)
+	}
+
+	if stack, ok := m[StackKey].(string); !ok || !strings.Contains(stack, "TestRecoverWith") {
+		t.Fatalf("panic 日志没有包含堆栈信息！%v", m[StackKey])
+	}
+
+	defer func() {
+		if err := recover(); err != "boom again" {
+			t.Fatalf("RecoverAndPanicWith 应该重新抛出 panic！%v", err)
+		}
+
+		if len(handler.logs) != 2 || handler.logs[1]["request_id"] != "req-2" {
+			t.Fatalf("panic 没有被记录！%v", handler.logs)
+		}
+	}()
+
+	func() {
+		defer RecoverAndPanicWith(logger, map[string]interface{}{"request_id": "req-2"})
+		panic("boom again")
+	}()
+}
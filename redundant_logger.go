@@ -0,0 +1,93 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 19:12:53
+
+package logit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/FishGoddess/logit/files"
+)
+
+const (
+	// defaultRollingFileSize is the default limited size of log file when rolling by size.
+	defaultRollingFileSize = 64 * files.MB
+)
+
+// RollStrategy is the strategy of rolling files. See NewRedundantRollingLogger.
+type RollStrategy struct {
+
+	// Duration decides log files roll by duration if it's larger than 0, or they roll by size.
+	Duration time.Duration
+
+	// Size is the limited size of log file when rolling by size, and 64 MB will be used if it's <= 0.
+	Size int64
+
+	// Encoder is the encoder of logs, and TextEncoder will be used if it's nil.
+	Encoder Encoder
+
+	// TimeFormat is the time format of logs, and DefaultTimeFormat will be used if it's empty.
+	TimeFormat string
+}
+
+// newRollingHandler returns a handler writing logs to files in directory rolled by strategy.
+func newRollingHandler(directory string, strategy RollStrategy) (Handler, error) {
+	encoder := strategy.Encoder
+	if encoder == nil {
+		encoder = TextEncoder()
+	}
+
+	timeFormat := strategy.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+
+	// 按时间间隔滚动优先，否则按照文件大小滚动
+	if strategy.Duration > 0 {
+		return NewDurationRollingHandlerE(directory, strategy.Duration, encoder, timeFormat)
+	}
+
+	size := strategy.Size
+	if size <= 0 {
+		size = defaultRollingFileSize
+	}
+	return NewSizeRollingHandlerE(directory, size, encoder, timeFormat)
+}
+
+// NewRedundantRollingLogger returns a logger writing the same logs to rolling files in every directory
+// of dirs simultaneously, so losing one disk doesn't lose logs if dirs are on different mount points.
+// A failure of writing to one directory doesn't prevent the others, and it will be reported to the
+// error callback of logger. See Logger.SetErrorCallback.
+// Return an error if dirs is empty or one of them isn't an existing directory.
+func NewRedundantRollingLogger(dirs []string, strategy RollStrategy, level Level) (*Logger, error) {
+	if len(dirs) < 1 {
+		return nil, errors.New("at least one directory should be given")
+	}
+
+	handlers := make([]Handler, 0, len(dirs))
+	for _, dir := range dirs {
+		handler, err := newRollingHandler(dir, strategy)
+		if err != nil {
+			closeHandlers(handlers)
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+	}
+	return NewLogger(level, handlers...), nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 19:30:07
+
+package logit
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试同时输出到多个目录的 logger
+func TestNewRedundantRollingLogger(t *testing.T) {
+	if _, err := NewRedundantRollingLogger(nil, RollStrategy{}, InfoLevel); err == nil {
+		t.Fatal("没有目录时应该返回错误！")
+	}
+
+	dirs := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		dir, err := ioutil.TempDir("", "TestNewRedundantRollingLogger_*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		dirs = append(dirs, dir)
+	}
+
+	if _, err := NewRedundantRollingLogger([]string{dirs[0], "/not/existed/dir"}, RollStrategy{}, InfoLevel); err == nil {
+		t.Fatal("目录不存在时应该返回错误！")
+	}
+
+	logger, err := NewRedundantRollingLogger(dirs, RollStrategy{Encoder: JsonEncoder()}, InfoLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := 0
+	logger.SetErrorCallback(func(err error) {
+		errs++
+	})
+
+	// 其中一个目录不可用了，也不影响其他的目录
+	os.RemoveAll(dirs[2])
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+	logger.Close()
+
+	first := readAllFilesIn(t, dirs[0])
+	if strings.Count(first, "\n") != 2 || !strings.Contains(first, `"msg":"info"`) || !strings.Contains(first, `"msg":"error"`) {
+		t.Fatalf("目录中的日志不正确！%s", first)
+	}
+
+	if second := readAllFilesIn(t, dirs[1]); second != first {
+		t.Fatalf("两个目录中的日志不一样！\n%s\n%s", first, second)
+	}
+
+	if errs != 2 {
+		t.Fatalf("写入失败的目录应该回调错误！%d", errs)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 11:08:24
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ReentrantLogError is an error happening on dropping a log emitted while handling another log
+	// in the same goroutine, such as a handler logs through the same logger. See Logger.EnableReentrancyGuard.
+	ReentrantLogError = errors.New("the log is dropped because it's emitted while handling another log")
+)
+
+// reentrancyGuard records goroutines which are handling logs, so logs emitted by them again can be detected.
+// Go doesn't have goroutine-local storage, so we use the id of goroutine as the key.
+type reentrancyGuard struct {
+	goroutines sync.Map
+}
+
+// enter marks current goroutine is handling a log, and returns false if it's already handling one.
+// Call leave with the id returned after handling if it returns true.
+func (rg *reentrancyGuard) enter() (uint64, bool) {
+	id := goroutineID()
+	if _, loaded := rg.goroutines.LoadOrStore(id, struct{}{}); loaded {
+		return id, false
+	}
+	return id, true
+}
+
+// leave marks the goroutine of id isn't handling logs anymore.
+func (rg *reentrancyGuard) leave(id uint64) {
+	rg.goroutines.Delete(id)
+}
+
+// goroutineID returns the id of current goroutine, which is parsed from the stack like "goroutine 18 [running]:".
+// It's expensive, so it's only used when the reentrancy guard is enabled.
+func goroutineID() uint64 {
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+	buffer = bytes.TrimPrefix(buffer, []byte("goroutine "))
+	if index := bytes.IndexByte(buffer, ' '); index >= 0 {
+		buffer = buffer[:index]
+	}
+
+	id, _ := strconv.ParseUint(string(buffer), 10, 64)
+	return id
+}
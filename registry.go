@@ -0,0 +1,45 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 22:02:56
+
+package logit
+
+import "reflect"
+
+// ResetRegistries resets all registries to the built-in defaults, so the handlers, encoders and
+// field renderers registered by you will be removed, and the built-in ones like "console" and "json"
+// will be retained. It's intended for testing, such as calling it in TestMain or teardown to prevent
+// registrations from leaking across tests. Notice that it affects the whole process, so don't call it
+// in your application, and loggers created before won't be affected.
+func ResetRegistries() {
+	mutexOfHandlers.Lock()
+	handlers = map[string]func(params map[string]interface{}) (Handler, error){}
+	mutexOfHandlers.Unlock()
+
+	// 内置的日志处理器是通过 RegisterHandler 注册的，所以需要在释放锁之后重新注册
+	registerExtensionHandlers()
+	registerLevelBasedHandlers()
+	registerLevelShieldedHandlers()
+
+	mutexOfEncoders.Lock()
+	encoders = builtinEncoders()
+	mutexOfEncoders.Unlock()
+
+	mutexOfFieldRenderers.Lock()
+	fieldRenderers = map[reflect.Type]func(value interface{}) interface{}{}
+	mutexOfFieldRenderers.Unlock()
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 22:02:56
+
+package logit
+
+import (
+	"reflect"
+	"testing"
+)
+
+// registryTestType is a type for testing field renderers.
+type registryTestType struct{}
+
+// 测试重置注册表之后，自定义的注册被移除，内置的依然存在
+func TestResetRegistries(t *testing.T) {
+	builtinHandlers := RegisteredHandlers()
+
+	err := RegisterHandler("registryTestHandler", func(params map[string]interface{}) Handler {
+		return NewMinLevelHandler(DebugLevel)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RegisterEncoder("registryTestEncoder", func(params map[string]interface{}) Encoder {
+		return TextEncoder()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterFieldRenderer(reflect.TypeOf(registryTestType{}), func(value interface{}) interface{} {
+		return "rendered"
+	})
+
+	if len(RegisteredHandlers()) != len(builtinHandlers)+1 || !isEncoderRegistered("registryTestEncoder") {
+		t.Fatal("注册失败！")
+	}
+
+	ResetRegistries()
+
+	// 自定义的注册都被移除了
+	if !reflect.DeepEqual(RegisteredHandlers(), builtinHandlers) {
+		t.Fatalf("重置之后的日志处理器不正确！%v", RegisteredHandlers())
+	}
+
+	if isEncoderRegistered("registryTestEncoder") {
+		t.Fatal("重置之后自定义的编码器还存在！")
+	}
+
+	if value := renderFieldValue(registryTestType{}); value != (registryTestType{}) {
+		t.Fatalf("重置之后自定义的字段渲染器还存在！%v", value)
+	}
+
+	// 内置的编码器依然存在，并且可以重新注册
+	if !isEncoderRegistered("text") || !isEncoderRegistered("json") {
+		t.Fatal("重置之后内置的编码器不存在了！")
+	}
+
+	err = RegisterHandler("registryTestHandler", func(params map[string]interface{}) Handler {
+		return NewMinLevelHandler(DebugLevel)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DeregisterHandler("registryTestHandler")
+
+	// 内置的日志处理器依然可以使用
+	if err := ValidateConfig([]byte(`"handlers": {"info": {"console": {}}, "!error": {"file": {}}}`)); err != nil {
+		t.Fatal(err)
+	}
+}
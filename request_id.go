@@ -0,0 +1,80 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/22 20:12:08
+
+package logit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+const (
+	// RequestIDKey is the key of the request id field. See WithRequestID.
+	RequestIDKey = "request_id"
+
+	// crockfordBase32 is the alphabet of request id, which excludes I, L, O and U to avoid confusion.
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	// requestIDLength is the length of a request id.
+	// It's 128 bits encoded in base32, including 48 bits timestamp and 80 bits randomness.
+	requestIDLength = 26
+)
+
+// NewRequestID returns a new request id like "01EG2MQ8W0X7J4V9T3ZP6KD5RB".
+// It's like a ULID, which starts with a millisecond timestamp and ends with 80 random bits
+// from crypto/rand, so it's collision-resistant and sortable by generating time.
+func NewRequestID() string {
+	var id [16]byte
+
+	// 前 48 位是毫秒时间戳，后 80 位是随机数
+	// 如果随机数生成失败，就使用纳秒时间填充，至少保证 id 可用
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(id[:6], ts[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		binary.BigEndian.PutUint64(id[8:], uint64(time.Now().UnixNano()))
+	}
+
+	// 128 位按照每 5 位一个字符编码，第一个字符只有 3 位
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var encoded [requestIDLength]byte
+	for i := requestIDLength - 1; i >= 0; i-- {
+		encoded[i] = crockfordBase32[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(encoded[:])
+}
+
+// WithRequestID generates a new request id and returns a copy of ctx carrying a child logger
+// of the logger in ctx, and all logs of the child logger will carry the request id field.
+// The request id is returned, too, so you can pass it to your response or downstream services:
+//
+//     ctx, requestID := logit.WithRequestID(r.Context())
+//     w.Header().Set("X-Request-Id", requestID)
+//     logit.FromContext(ctx).Info("handling request")
+//
+func WithRequestID(ctx context.Context) (context.Context, string) {
+	requestID := NewRequestID()
+	logger := FromContext(ctx).WithFields(map[string]interface{}{RequestIDKey: requestID})
+	return NewContext(ctx, logger), requestID
+}
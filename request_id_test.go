@@ -0,0 +1,62 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/22 20:58:14
+
+package logit
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+// 测试生成的请求 id 的格式和唯一性
+func TestNewRequestID(t *testing.T) {
+	format := regexp.MustCompile("^[0-9A-HJKMNP-TV-Z]{26}$")
+	ids := make(map[string]struct{}, 100000)
+	for i := 0; i < 100000; i++ {
+		id := NewRequestID()
+		if !format.MatchString(id) {
+			t.Fatalf("请求 id 的格式不正确！%s", id)
+		}
+
+		if _, ok := ids[id]; ok {
+			t.Fatalf("生成了重复的请求 id！%s", id)
+		}
+		ids[id] = struct{}{}
+	}
+
+	// 请求 id 的前 10 个字符正好是 48 位的毫秒时间戳，所以可以按照生成顺序排序
+	first := NewRequestID()
+	second := NewRequestID()
+	if first[:10] > second[:10] {
+		t.Fatalf("请求 id 没有以时间戳开头！%s %s", first, second)
+	}
+}
+
+// 测试把请求 id 放到 context 的 logger 中
+func TestWithRequestID(t *testing.T) {
+	handler := &mapHandler{}
+	ctx := NewContext(context.Background(), NewLogger(DebugLevel, handler))
+
+	ctx, requestID := WithRequestID(ctx)
+	FromContext(ctx).Info("with request id")
+
+	if len(handler.logs) != 1 || handler.logs[0][RequestIDKey] != requestID {
+		t.Fatalf("日志没有携带请求 id！%v", handler.logs)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/03 21:07:12
+
+package logit
+
+import "regexp"
+
+// scrubber rewrites the parts of a string matching pattern to replacement.
+type scrubber struct {
+
+	// pattern is the compiled regexp matching sensitive data.
+	pattern *regexp.Regexp
+
+	// replacement is the string replacing the matched parts.
+	// Notice that it supports expanding like $1, see regexp.Regexp.ReplaceAllString.
+	replacement string
+}
+
+// scrubString returns s scrubbed by all scrubbers in order.
+func scrubString(s string, scrubbers []scrubber) string {
+	for _, scrubber := range scrubbers {
+		s = scrubber.pattern.ReplaceAllString(s, scrubber.replacement)
+	}
+	return s
+}
+
+// scrubValue returns value scrubbed by all scrubbers and true if value is changed.
+// Only strings and the strings in merged values will be scrubbed. See AppendMode.
+func scrubValue(value interface{}, scrubbers []scrubber) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		newValue := scrubString(v, scrubbers)
+		return newValue, newValue != v
+	case mergedValues:
+		// 合并的值里面也可能有敏感数据，同样需要写时复制
+		var scrubbed mergedValues
+		for i, value := range v {
+			newValue, changed := scrubValue(value, scrubbers)
+			if !changed {
+				continue
+			}
+
+			if scrubbed == nil {
+				scrubbed = make(mergedValues, len(v))
+				copy(scrubbed, v)
+			}
+			scrubbed[i] = newValue
+		}
+		return scrubbed, scrubbed != nil
+	default:
+		return value, false
+	}
+}
+
+// scrubFields returns fields whose string values are scrubbed by all scrubbers.
+// If no field is changed, fields will be returned directly without any allocation.
+func scrubFields(fields []Field, scrubbers []scrubber) []Field {
+	if len(scrubbers) < 1 {
+		return fields
+	}
+
+	var scrubbed []Field
+	for i, field := range fields {
+		newValue, changed := scrubValue(field.Value, scrubbers)
+		if !changed {
+			continue
+		}
+
+		// 写时复制，fields 可能被多个 logger 共享，不能直接修改
+		if scrubbed == nil {
+			scrubbed = make([]Field, len(fields))
+			copy(scrubbed, fields)
+		}
+		scrubbed[i].Value = newValue
+	}
+
+	if scrubbed == nil {
+		return fields
+	}
+	return scrubbed
+}
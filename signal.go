@@ -0,0 +1,110 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/13 21:18:55
+
+package logit
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// closeTimeoutOnSignals is the max time spent on closing logger after receiving a signal.
+	closeTimeoutOnSignals = 5 * time.Second
+)
+
+var (
+	// CloseTimeoutError is an error happening on closing logger timeout.
+	CloseTimeoutError = errors.New("closing logger timeout! May be some handlers are blocked")
+)
+
+// closeWithTimeout closes logger and waits at most timeout.
+// Return CloseTimeoutError if closing didn't finish in time.
+// Notice that the closing goroutine will keep running after timeout, because
+// there is no way to stop a goroutine in Go.
+func closeWithTimeout(logger *Logger, timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- logger.Close()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return CloseTimeoutError
+	}
+}
+
+// FlushOnSignals installs a signal handler which flushes and closes logger when receiving
+// one of sigs, so buffered logs won't be lost on graceful shutdown. If no sig is given,
+// os.Interrupt and syscall.SIGTERM will be used. Closing will wait at most 5 seconds.
+// It returns a function to uninstall the signal handler, and it's safe to call it more than once.
+//
+// After closing, the signal handler uninstalls itself and re-raises the signal, so the process
+// will be terminated as usual if your application doesn't handle this signal. If re-raising
+// failed (such as os.Interrupt on windows), the process will exit with status code 1.
+//
+// Notice that if your application handles these signals by itself, it will receive the signal
+// twice (the original one and the re-raised one), so keep your handling idempotent. Also, if your
+// application exits immediately after receiving the signal, logs may be lost before closing
+// finishes. In this case, you'd better call logger.Close in your own handling instead.
+func FlushOnSignals(logger *Logger, sigs ...os.Signal) (uninstall func()) {
+	if len(sigs) < 1 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sigs...)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			closeWithTimeout(logger, closeTimeoutOnSignals)
+			reraiseSignal(sig)
+		case <-done:
+		}
+	}()
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// reraiseSignal sends sig to current process again.
+// If failed, the process will exit with status code 1.
+func reraiseSignal(sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil && process.Signal(sig) == nil {
+		return
+	}
+	os.Exit(1)
+}
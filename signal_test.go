@@ -0,0 +1,70 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/13 21:50:12
+
+package logit
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// 记录是否被关闭的日志处理器
+type closingHandler struct {
+	bufferedHandler
+	closed bool
+	block  chan struct{}
+}
+
+func (ch *closingHandler) Close() error {
+	if ch.block != nil {
+		<-ch.block
+	}
+	ch.closed = true
+	return nil
+}
+
+// 测试收到信号之后关闭日志记录器
+func TestFlushOnSignals(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	handler := &closingHandler{bufferedHandler: bufferedHandler{writer: buffer}}
+	logger := NewLogger(DebugLevel, handler)
+	logger.Info("buffered")
+
+	// 直接调用信号处理的逻辑，避免真的给测试进程发送信号
+	if err := closeWithTimeout(logger, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if !handler.closed || buffer.Len() == 0 {
+		t.Fatalf("收到信号之后没有刷新和关闭日志处理器！closed=%v, flushed=%d", handler.closed, buffer.Len())
+	}
+
+	// 关闭超时需要返回错误
+	blocked := &closingHandler{bufferedHandler: bufferedHandler{writer: buffer}, block: make(chan struct{})}
+	defer close(blocked.block)
+	if err := closeWithTimeout(NewLogger(DebugLevel, blocked), 10*time.Millisecond); err != CloseTimeoutError {
+		t.Fatalf("关闭超时应该返回 CloseTimeoutError！%v", err)
+	}
+
+	// 安装之后卸载，多次卸载也不应该有问题
+	uninstall := FlushOnSignals(logger, os.Interrupt)
+	uninstall()
+	uninstall()
+}
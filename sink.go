@@ -0,0 +1,159 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:48:31
+
+package logit
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink is the transport of encoded logs, such as console, file and network connection.
+// It's only responsible for delivering bytes, and the encoding is done by the handler using it,
+// so the same encoder can target different sinks. See NewEncodingHandler.
+// Notice that the handler serializes all calls, so a sink doesn't need to be safe for concurrency.
+type Sink interface {
+
+	// Write delivers p, which is an encoded log, as a whole.
+	Write(p []byte) error
+
+	// Flush delivers all buffered data to the underlying destination.
+	Flush() error
+
+	// Close flushes and releases the sink, and it shouldn't be used after closing.
+	Close() error
+}
+
+// writerSink is a sink writing logs to a writer.
+type writerSink struct {
+	writer io.Writer
+}
+
+// WriterSink returns a sink writing logs to writer, such as os.Stdout, a file and a net.Conn.
+// A log is written in one Write call, and the rest will be written again if writer accepts a
+// part of it only. The writer will be flushed if it's a Flusher, and closed if it's an io.Closer.
+// Notice that os.Stdout and os.Stderr will never be closed.
+func WriterSink(writer io.Writer) Sink {
+	return &writerSink{writer: writer}
+}
+
+// Write writes all of p to the writer.
+func (ws *writerSink) Write(p []byte) error {
+	return writeFull(ws.writer, p)
+}
+
+// Flush flushes the writer if it is a Flusher.
+func (ws *writerSink) Flush() error {
+	if flusher, ok := ws.writer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the writer if it is an io.Closer.
+func (ws *writerSink) Close() error {
+	err := ws.Flush()
+	if ws.writer == os.Stdout || ws.writer == os.Stderr {
+		return err
+	}
+
+	if closer, ok := ws.writer.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil {
+			return closeErr
+		}
+	}
+	return err
+}
+
+// encodingHandler is a handler pairing an encoder with a sink.
+type encodingHandler struct {
+	encoder    Encoder
+	sink       Sink
+	timeFormat string
+
+	// mu serializes the calls of sink, so lines written concurrently never interleave.
+	mu *sync.Mutex
+}
+
+// NewEncodingHandler returns a handler encoding logs by encoder with timeFormat and delivering
+// them by sink. It separates encoding from transport, so features of transport like batching
+// can be implemented as sinks and work with any encoder:
+//
+//     fileHandler := logit.NewEncodingHandler(logit.JsonEncoder(), logit.WriterSink(file), logit.DefaultTimeFormat)
+//     connHandler := logit.NewEncodingHandler(logit.JsonEncoder(), logit.WriterSink(conn), logit.DefaultTimeFormat)
+//
+// All calls of sink are serialized by the handler. See Sink.
+func NewEncodingHandler(encoder Encoder, sink Sink, timeFormat string) Handler {
+	return &encodingHandler{
+		encoder:    encoder,
+		sink:       sink,
+		timeFormat: timeFormat,
+		mu:         &sync.Mutex{},
+	}
+}
+
+// Handle will encode log and deliver it by sink.
+// If delivering failed, the error will be reported to the error callback of logger.
+// Return true so that handlers after it will be used.
+func (eh *encodingHandler) Handle(log *Log) bool {
+	err := eh.HandleWithError(log)
+	if err != nil && log.logger != nil {
+		log.logger.reportError(err)
+	}
+	return true
+}
+
+// HandleWithError will encode log and deliver it by sink.
+// Return the error of delivering, which won't be reported to the error callback of logger.
+func (eh *encodingHandler) HandleWithError(log *Log) error {
+	if log.profiler == nil {
+		encoded := eh.encoder.Encode(log, eh.timeFormat)
+
+		eh.mu.Lock()
+		defer eh.mu.Unlock()
+		return eh.sink.Write(encoded)
+	}
+
+	// 开启了性能分析，需要记录编码和写入的耗时
+	begin := time.Now()
+	encoded := eh.encoder.Encode(log, eh.timeFormat)
+	log.profiler.recordEncode(begin)
+
+	begin = time.Now()
+	eh.mu.Lock()
+	err := eh.sink.Write(encoded)
+	eh.mu.Unlock()
+	log.profiler.recordWrite(begin)
+	return err
+}
+
+// Flush flushes the sink.
+func (eh *encodingHandler) Flush() error {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+	return eh.sink.Flush()
+}
+
+// Close closes the sink.
+func (eh *encodingHandler) Close() error {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+	return eh.sink.Close()
+}
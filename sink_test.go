@@ -0,0 +1,102 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 21:48:31
+
+package logit
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试同一个编码器输出到文件和网络两种 sink
+func TestNewEncodingHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewEncodingHandler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := os.Create(filepath.Join(dir, "logit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+
+		// 一直读取到客户端关闭连接
+		var lines []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- lines
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder := JsonEncoder()
+	logger := NewLogger(DebugLevel,
+		NewEncodingHandler(encoder, WriterSink(file), DefaultTimeFormat),
+		NewEncodingHandler(encoder, WriterSink(conn), DefaultTimeFormat),
+	)
+
+	logger.Info("first")
+	logger.Error("second")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileLines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	connLines := <-received
+	if len(fileLines) != 2 || len(connLines) != 2 {
+		t.Fatalf("输出的日志条数不正确！%q %q", fileLines, connLines)
+	}
+
+	// 两种 sink 收到的是同一个编码器编码出来的日志
+	for i, msg := range []string{"first", "second"} {
+		if fileLines[i] != connLines[i] || !strings.Contains(fileLines[i], `"msg":"`+msg+`"`) {
+			t.Fatalf("第 %d 条日志不正确！%s %s", i+1, fileLines[i], connLines[i])
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/21 20:17:52
+
+package logit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SmoothingHandler is a handler releasing logs to the inner handler at a steady rate like a leaky bucket.
+// Bursts of logs will be queued and released one by one, so the I/O of inner handler won't be spiky.
+// Logs overflowing the queue will be dropped and counted, see SmoothingHandler.Dropped.
+//
+// Notice that logs in queue haven't been handled yet, so they may be lost if your program crashes.
+// Call Logger.Close or Logger.Flush before exiting to handle all logs in queue.
+type SmoothingHandler struct {
+
+	// inner is the handler handling logs released from queue.
+	inner Handler
+
+	// queue stores the logs waiting for releasing.
+	queue chan *Log
+
+	// dropped is the count of logs dropped because queue is full.
+	dropped uint64
+
+	// done is closed when the handler is closed, and exited is closed after releasing goroutine exits.
+	done   chan struct{}
+	exited chan struct{}
+
+	// closeOnce makes Close can be called more than once.
+	closeOnce *sync.Once
+
+	// closed is a flag to check if the handler has been closed, and stateMu is for its concurrency.
+	// Handle holds the read lock while putting logs to queue, so no log is put after closing.
+	closed  bool
+	stateMu *sync.RWMutex
+
+	// mu makes only one log is handled by inner at the same time.
+	mu *sync.Mutex
+}
+
+// NewSmoothingHandler returns a handler releasing logs to inner at most ratePerSec logs per second.
+// The queueSize is the max count of logs waiting for releasing, and logs beyond it will be dropped.
+// Notice that a goroutine will be started for releasing logs, so remember to close it.
+// It panics if ratePerSec isn't in (0, 1e9] or queueSize <= 0.
+func NewSmoothingHandler(inner Handler, ratePerSec int, queueSize int) *SmoothingHandler {
+	if ratePerSec <= 0 {
+		panic("ratePerSec must be larger than 0!")
+	}
+
+	// 释放的间隔最小是 1 纳秒，否则间隔是 0 会导致创建定时器的时候 panic
+	if int64(ratePerSec) > int64(time.Second) {
+		panic("ratePerSec must be not larger than 1e9!")
+	}
+
+	// 队列没有缓冲的话，所有的日志都会被丢弃
+	if queueSize <= 0 {
+		panic("queueSize must be larger than 0!")
+	}
+
+	sh := &SmoothingHandler{
+		inner:     inner,
+		queue:     make(chan *Log, queueSize),
+		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+		stateMu:   &sync.RWMutex{},
+		mu:        &sync.Mutex{},
+	}
+
+	go sh.release(time.Second / time.Duration(ratePerSec))
+	return sh
+}
+
+// release releases one log to inner handler every interval until sh is closed.
+func (sh *SmoothingHandler) release(interval time.Duration) {
+	defer close(sh.exited)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sh.done:
+			return
+		case <-ticker.C:
+			select {
+			case log := <-sh.queue:
+				sh.handle(log)
+			default:
+			}
+		}
+	}
+}
+
+// handle handles log with inner handler.
+func (sh *SmoothingHandler) handle(log *Log) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.inner.Handle(log)
+}
+
+// Handle puts a copy of log to queue, and it will be released to inner handler later.
+// If the queue is full or the handler has been closed, this log will be dropped and counted.
+// Return true so that handlers after it will be used.
+func (sh *SmoothingHandler) Handle(log *Log) bool {
+	sh.stateMu.RLock()
+	defer sh.stateMu.RUnlock()
+
+	// 关闭之后队列不会再被处理，放进去的日志永远不会被释放，所以直接丢弃
+	if sh.closed {
+		atomic.AddUint64(&sh.dropped, 1)
+		reportDropped(log, "smoothing")
+		return true
+	}
+
+	// log 会被放回对象池中复用，所以需要拷贝一份放到队列中
+	// 日志是之后才编码的，调用者可能已经修改了传进来的 Fields，所以字段也需要克隆
+	copied := *log
+	copied.fields = Fields(log.fields).Clone()
+	select {
+	case sh.queue <- &copied:
+	default:
+		atomic.AddUint64(&sh.dropped, 1)
+		reportDropped(log, "smoothing")
+	}
+	return true
+}
+
+// Dropped returns the count of logs dropped because the queue is full or the handler has been closed.
+func (sh *SmoothingHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&sh.dropped)
+}
+
+// drain handles all logs in queue immediately without pacing, and returns the count of them.
+func (sh *SmoothingHandler) drain() int {
+	drained := 0
+	for {
+		select {
+		case log := <-sh.queue:
+			sh.handle(log)
+			drained++
+		default:
+			return drained
+		}
+	}
+}
+
+// Flush handles all logs in queue immediately, then flushes inner handler if it is a Flusher.
+func (sh *SmoothingHandler) Flush() error {
+	_, err := sh.FlushWithResult()
+	return err
+}
+
+// FlushWithResult is the same as Flush but returns the result of flushing.
+// The logs drained from queue are counted in Entries, unless inner handler reports more entries flushed.
+// That's because inner handler may buffer the drained logs and count them again when flushing.
+func (sh *SmoothingHandler) FlushWithResult() (FlushResult, error) {
+	drained := sh.drain()
+	result, err := flushHandlers([]Handler{sh.inner})
+	if result.Entries < drained {
+		result.Entries = drained
+	}
+	return result, err
+}
+
+// Close stops releasing, handles all logs in queue, then closes inner handler if it is an io.Closer.
+// It's safe to call it more than once.
+// Logs handled after closing will be dropped and counted.
+func (sh *SmoothingHandler) Close() error {
+	sh.closeOnce.Do(func() {
+		sh.stateMu.Lock()
+		sh.closed = true
+		sh.stateMu.Unlock()
+		close(sh.done)
+	})
+	<-sh.exited
+
+	// 刷新失败也要关闭内层的日志处理器，否则文件之类的资源会泄露
+	sh.drain()
+	_, flushErr := flushHandlers([]Handler{sh.inner})
+	return joinErrors(flushErr, closeHandlers([]Handler{sh.inner}))
+}
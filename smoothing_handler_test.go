@@ -0,0 +1,243 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/21 20:56:18
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 记录每条日志被处理的时间
+type timingHandler struct {
+	msgs  []string
+	times []time.Time
+	mu    sync.Mutex
+}
+
+func (th *timingHandler) Handle(log *Log) bool {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.msgs = append(th.msgs, log.Msg())
+	th.times = append(th.times, time.Now())
+	return true
+}
+
+// 测试平滑日志处理器按照固定速率输出日志
+func TestSmoothingHandler(t *testing.T) {
+	inner := &timingHandler{}
+	handler := NewSmoothingHandler(inner, 100, 10)
+	defer handler.Close()
+
+	logger := NewLogger(DebugLevel, handler)
+	begin := time.Now()
+	for i := 0; i < 5; i++ {
+		logger.Info(string(rune('a' + i)))
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if len(inner.msgs) != 5 {
+		t.Fatalf("日志条数不正确！%d", len(inner.msgs))
+	}
+
+	for i, msg := range inner.msgs {
+		if msg != string(rune('a'+i)) {
+			t.Fatalf("日志的顺序不正确！%v", inner.msgs)
+		}
+	}
+
+	// 每秒 100 条，也就是每 10ms 一条，5 条日志至少需要 50ms
+	if elapsed := inner.times[4].Sub(begin); elapsed < 40*time.Millisecond {
+		t.Fatalf("日志没有被平滑输出！%v", elapsed)
+	}
+
+	for i := 1; i < len(inner.times); i++ {
+		if inner.times[i].Sub(inner.times[i-1]) < 5*time.Millisecond {
+			t.Fatalf("日志的输出间隔太短！%v", inner.times[i].Sub(inner.times[i-1]))
+		}
+	}
+}
+
+// 测试队列满了之后丢弃日志，并且关闭时处理完队列中的日志
+func TestSmoothingHandlerDropAndClose(t *testing.T) {
+	inner := &timingHandler{}
+	handler := NewSmoothingHandler(inner, 1, 2)
+
+	logger := NewLogger(DebugLevel, handler)
+	for i := 0; i < 5; i++ {
+		logger.Info("burst")
+	}
+
+	if handler.Dropped() != 3 {
+		t.Fatalf("丢弃的日志条数不正确！%d", handler.Dropped())
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inner.msgs) != 2 {
+		t.Fatalf("关闭时没有处理完队列中的日志！%d", len(inner.msgs))
+	}
+
+	// 多次关闭也不能出问题
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭之后的日志直接丢弃
+	logger.Info("closed")
+	if handler.Dropped() != 4 || len(inner.msgs) != 2 || len(handler.queue) != 0 {
+		t.Fatalf("关闭之后的日志没有被丢弃！%d %d", handler.Dropped(), len(inner.msgs))
+	}
+}
+
+// 测试调用者复用 Fields 不会影响队列中还没有输出的日志
+func TestSmoothingHandlerReusedFields(t *testing.T) {
+	inner := &mapHandler{}
+	handler := NewSmoothingHandler(inner, 1, 2)
+	logger := NewLogger(DebugLevel, handler)
+
+	fields := Fields{}
+	fields.Set("id", 1)
+	logger.InfoWith(fields, "first")
+	fields.Set("id", 2)
+	logger.InfoWith(fields, "second")
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inner.logs) != 2 {
+		t.Fatalf("日志条数不正确！%d", len(inner.logs))
+	}
+
+	for i, log := range inner.logs {
+		if log["id"] != i+1 {
+			t.Fatalf("第 %d 条日志的字段被调用者修改了！%v", i+1, log)
+		}
+	}
+}
+
+// 测试队列大小不合法的时候直接 panic
+func TestNewSmoothingHandlerInvalidQueueSize(t *testing.T) {
+	for _, queueSize := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("队列大小为 %d 的时候应该 panic！", queueSize)
+				}
+			}()
+			NewSmoothingHandler(&mapHandler{}, 1, queueSize)
+		}()
+	}
+}
+
+// 测试刷新的结果包含了从队列中处理的日志
+func TestSmoothingHandlerFlushWithResult(t *testing.T) {
+	inner := &timingHandler{}
+	handler := NewSmoothingHandler(inner, 1, 10)
+	defer handler.Close()
+
+	logger := NewLogger(DebugLevel, handler)
+	logger.Info("a")
+	logger.Info("b")
+	logger.Info("c")
+
+	result, err := handler.FlushWithResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Entries != 3 {
+		t.Fatalf("刷新的日志条数不正确！%+v", result)
+	}
+
+	// 内层日志处理器会缓冲日志并且统计刷新结果的时候，不会重复计数
+	buffer := bytes.NewBuffer(nil)
+	buffered := NewSmoothingHandler(&bufferedHandler{writer: buffer}, 1, 10)
+	defer buffered.Close()
+
+	logger = NewLogger(DebugLevel, buffered)
+	logger.Info("a")
+	logger.Info("b")
+
+	result, err = buffered.FlushWithResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Entries != 2 || result.Bytes == 0 || result.Bytes != buffer.Len() {
+		t.Fatalf("刷新的结果不正确！%+v, 实际写入了 %d 字节", result, buffer.Len())
+	}
+}
+
+// 测试每秒释放的日志条数超过 1e9 的时候 panic
+func TestNewSmoothingHandlerInvalidRate(t *testing.T) {
+	for _, ratePerSec := range []int{0, -1, int(time.Second) + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("速率为 %d 的时候应该 panic！", ratePerSec)
+				}
+			}()
+			NewSmoothingHandler(&mapHandler{}, ratePerSec, 1)
+		}()
+	}
+
+	NewSmoothingHandler(&mapHandler{}, int(time.Second), 1).Close()
+}
+
+// 刷新总是失败的日志处理器，记录是否被关闭
+type failingFlushHandler struct {
+	err    error
+	closed bool
+}
+
+func (ffh *failingFlushHandler) Handle(log *Log) bool {
+	return true
+}
+
+func (ffh *failingFlushHandler) Flush() error {
+	return ffh.err
+}
+
+func (ffh *failingFlushHandler) Close() error {
+	ffh.closed = true
+	return nil
+}
+
+// 测试刷新失败的时候，关闭依然会关闭内层的日志处理器
+func TestSmoothingHandlerCloseAfterFlushFailed(t *testing.T) {
+	inner := &failingFlushHandler{err: errors.New("flush failed")}
+	handler := NewSmoothingHandler(inner, 1, 1)
+
+	if err := handler.Close(); !errors.Is(err, inner.err) {
+		t.Fatalf("刷新失败的错误没有返回！%v", err)
+	}
+
+	if !inner.closed {
+		t.Fatal("刷新失败之后没有关闭内层的日志处理器！")
+	}
+}
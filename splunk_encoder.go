@@ -0,0 +1,102 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 14:02:37
+
+package logit
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// SplunkHECEncoder returns an encoder encoding a log to an event of Splunk HTTP Event Collector like:
+//
+//     {"time":1598767357.123,"host":"web-1","source":"order","sourcetype":"_json","event":"msg","fields":{"level":"info","id":"123"}}
+//
+// The message goes to event, and the level, the file info and the structured fields go to fields,
+// which are indexed by Splunk. Splunk only indexes string values, so values of fields are written
+// in text form like TextEncoder does. The time is always in epoch seconds with milliseconds, so
+// timeFormat is ignored. Empty host, source and sourceType will be omitted, and HEC will use the
+// defaults of the token. Each event ends with "\n", so events written together can be posted to
+// HEC as a batch.
+func SplunkHECEncoder(host string, source string, sourceType string) Encoder {
+	return func(log *Log, timeFormat string) []byte {
+		nanos := log.Now().UnixNano()
+		buffer := bytes.NewBuffer(make([]byte, 0, 128))
+		buffer.WriteString(`{"time":`)
+		buffer.WriteString(strconv.FormatInt(nanos/1e9, 10))
+		buffer.WriteString(".")
+
+		// 保留毫秒，不足三位的在前面补 0
+		millis := strconv.FormatInt(nanos%1e9/1e6, 10)
+		for i := len(millis); i < 3; i++ {
+			buffer.WriteString("0")
+		}
+		buffer.WriteString(millis)
+
+		writeSplunkHECString(buffer, "host", host)
+		writeSplunkHECString(buffer, "source", source)
+		writeSplunkHECString(buffer, "sourcetype", sourceType)
+
+		buffer.WriteString(`,"event":"`)
+		buffer.WriteString(escapeString(log.Msg()))
+		buffer.WriteString(`","fields":{"level":"`)
+		buffer.WriteString(log.Level().String())
+		buffer.WriteString(`"`)
+
+		if log.File() != "" && log.Line() != 0 {
+			buffer.WriteString(`,"file":"` + escapeString(log.File()))
+			buffer.WriteString(`","line":"` + strconv.Itoa(log.Line()) + `"`)
+			if log.CallerFunc() != "" {
+				buffer.WriteString(`,"func":"` + escapeString(log.CallerFunc()) + `"`)
+			}
+		}
+
+		if log.Mono() > 0 {
+			buffer.WriteString(`,"` + MonoKey + `":"` + strconv.FormatInt(int64(log.Mono()), 10) + `"`)
+		}
+
+		// 字段的值都以文本形式写出，因为 Splunk 只会索引字符串
+		value := bytes.NewBuffer(make([]byte, 0, 32))
+		for _, field := range log.Fields() {
+			value.Reset()
+			writeTextValue(value, renderFieldValue(field.Value))
+
+			buffer.WriteString(`,"`)
+			buffer.WriteString(escapeString(field.Key))
+			buffer.WriteString(`":"`)
+			buffer.WriteString(escapeString(value.String()))
+			buffer.WriteString(`"`)
+		}
+
+		buffer.WriteString("}}\n")
+		return buffer.Bytes()
+	}
+}
+
+// writeSplunkHECString writes a string property of the HEC envelope to buffer if value isn't empty.
+func writeSplunkHECString(buffer *bytes.Buffer, key string, value string) {
+	if value == "" {
+		return
+	}
+
+	buffer.WriteString(`,"`)
+	buffer.WriteString(key)
+	buffer.WriteString(`":"`)
+	buffer.WriteString(escapeString(value))
+	buffer.WriteString(`"`)
+}
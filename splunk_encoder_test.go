@@ -0,0 +1,105 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 14:15:52
+
+package logit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// 测试 Splunk HEC 编码器输出的事件格式
+func TestSplunkHECEncoder(t *testing.T) {
+	log := &Log{
+		level:  InfoLevel,
+		now:    time.Unix(1598767357, 5*int64(time.Millisecond)),
+		file:   "file.go",
+		line:   12,
+		msg:    "order \"created\"",
+		fields: Fields{{Key: "id", Value: 123}, {Key: "cost", Value: 1500 * time.Millisecond}},
+	}
+
+	event := map[string]interface{}{}
+	if err := json.Unmarshal(SplunkHECEncoder("web-1", "order", "_json").Encode(log, DefaultTimeFormat), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	if event["time"] != 1598767357.005 || event["host"] != "web-1" || event["source"] != "order" ||
+		event["sourcetype"] != "_json" || event["event"] != "order \"created\"" {
+		t.Fatalf("HEC 事件的格式不正确！%v", event)
+	}
+
+	fields, ok := event["fields"].(map[string]interface{})
+	if !ok || len(fields) != 5 || fields["level"] != "info" || fields["file"] != "file.go" ||
+		fields["line"] != "12" || fields["id"] != "123" || fields["cost"] != "1.5s" {
+		t.Fatalf("HEC 事件的字段不正确！%v", event["fields"])
+	}
+
+	// 空的 host、source 和 sourcetype 不输出
+	event = map[string]interface{}{}
+	if err := json.Unmarshal(SplunkHECEncoder("", "", "").Encode(log, ""), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := event["host"]; ok || len(event) != 3 {
+		t.Fatalf("空的属性不应该输出！%v", event)
+	}
+}
+
+// 测试多个 HEC 事件可以作为一批提交给 HEC
+func TestSplunkHECEncoderBatch(t *testing.T) {
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decoder := json.NewDecoder(r.Body)
+		for {
+			event := map[string]interface{}{}
+			err := decoder.Decode(&event)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil || event["event"] == nil || event["fields"] == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received++
+		}
+		w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	buffer := bytes.NewBuffer(nil)
+	logger := NewLogger(DebugLevel, NewStandardHandler(buffer, SplunkHECEncoder("web-1", "order", ""), ""))
+	logger.Info("first")
+	logger.WithFields(map[string]interface{}{"id": 1}).Error("second")
+
+	response, err := http.Post(server.URL+"/services/collector/event", "application/json", buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK || received != 2 {
+		t.Fatalf("HEC 批量提交的结果不正确！%d %d", response.StatusCode, received)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/16 16:25:40
+
+package logit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// These are the keys of fields carried by the log of a sql query.
+	SQLQueryKey    = "query"
+	SQLArgsKey     = "args"
+	SQLDurationKey = "duration"
+
+	// sqlQueryMsg is the message of the log of a sql query.
+	sqlQueryMsg = "sql query"
+
+	// slowSQLQueryMsg is the message of the log of a slow sql query.
+	slowSQLQueryMsg = "slow sql query"
+)
+
+// SQLLogFunc returns a callback which logs a sql query by logger in level.
+// The query, args and duration will be carried as fields, see SQLQueryKey.
+// If slowThreshold > 0 and the duration of query is longer than it, the query will be
+// logged in warn level as a slow query. The signature of callback is generic, so you
+// can adapt it to any sql driver without importing it:
+//
+//     logQuery := logit.SQLLogFunc(logger, logit.DebugLevel, 100*time.Millisecond)
+//     driver.SetLogger(func(ctx context.Context, query string, args []interface{}, d time.Duration) {
+//         logQuery(ctx, query, args, d)
+//     })
+//
+func SQLLogFunc(logger *Logger, level Level, slowThreshold time.Duration) func(ctx context.Context, query string, args []interface{}, duration time.Duration) {
+	return func(ctx context.Context, query string, args []interface{}, duration time.Duration) {
+		fields := Fields{
+			{Key: SQLQueryKey, Value: query},
+			{Key: SQLArgsKey, Value: args},
+			{Key: SQLDurationKey, Value: duration},
+		}
+
+		// 慢查询至少使用 warn 级别输出，避免被调试级别的日志淹没
+		logLevel, msg := level, sqlQueryMsg
+		if slowThreshold > 0 && duration > slowThreshold {
+			msg = slowSQLQueryMsg
+			if logLevel < WarnLevel {
+				logLevel = WarnLevel
+			}
+		}
+		logger.log(callDepth, logLevel, msg, fields)
+	}
+}
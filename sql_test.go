@@ -0,0 +1,57 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/16 16:52:13
+
+package logit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 测试 sql 查询日志的回调函数
+func TestSQLLogFunc(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+
+	logQuery := SQLLogFunc(logger, DebugLevel, 100*time.Millisecond)
+	logQuery(context.Background(), "SELECT * FROM users WHERE id = ?", []interface{}{1}, 10*time.Millisecond)
+	logQuery(context.Background(), "SELECT * FROM orders", nil, time.Second)
+
+	if len(handler.logs) != 2 {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	m := handler.logs[0]
+	if m["level"] != DebugLevel || m["msg"] != sqlQueryMsg {
+		t.Fatalf("sql 查询日志的级别或信息不正确！%v", m)
+	}
+
+	if m[SQLQueryKey] != "SELECT * FROM users WHERE id = ?" || m[SQLDurationKey] != 10*time.Millisecond {
+		t.Fatalf("sql 查询日志的字段不正确！%v", m)
+	}
+
+	if args, ok := m[SQLArgsKey].([]interface{}); !ok || len(args) != 1 || args[0] != 1 {
+		t.Fatalf("sql 查询日志的参数不正确！%v", m[SQLArgsKey])
+	}
+
+	m = handler.logs[1]
+	if m["level"] != WarnLevel || m["msg"] != slowSQLQueryMsg || m[SQLDurationKey] != time.Second {
+		t.Fatalf("慢查询日志不正确！%v", m)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 20:05:31
+
+package logit
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// StackRefKey is the key of the field referencing a stack by its fingerprint.
+	// See Logger.SetStackDedupWindow.
+	StackRefKey = "stack_ref"
+)
+
+// stackDedup remembers the fingerprints of stacks seen in a window.
+type stackDedup struct {
+
+	// window is how long a stack seen is remembered.
+	window time.Duration
+
+	// seen is the first time of every fingerprint seen in its window.
+	seen map[string]time.Time
+
+	// mu is for safe concurrency.
+	mu sync.Mutex
+}
+
+// newStackDedup returns a stack dedup remembering stacks in window.
+func newStackDedup(window time.Duration) *stackDedup {
+	return &stackDedup{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// dedup returns fields with a stack reference, and the stack will be removed if it's seen in the window.
+// Notice that fields will never be modified in place, because they may be shared.
+func (sd *stackDedup) dedup(fields []Field, now time.Time) []Field {
+	index := -1
+	for i, field := range fields {
+		if field.Key == StackKey {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return fields
+	}
+
+	stack, ok := fields[index].Value.(string)
+	if !ok {
+		return fields
+	}
+
+	ref := stackFingerprint(stack)
+	deduped := make([]Field, 0, len(fields)+1)
+	deduped = append(deduped, fields[:index]...)
+	if !sd.seenBefore(ref, now) {
+		deduped = append(deduped, fields[index])
+	}
+
+	deduped = append(deduped, Field{Key: StackRefKey, Value: ref})
+	return append(deduped, fields[index+1:]...)
+}
+
+// seenBefore returns true if ref was seen in the window, or it will be remembered from now.
+func (sd *stackDedup) seenBefore(ref string, now time.Time) bool {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if first, ok := sd.seen[ref]; ok && now.Sub(first) < sd.window {
+		return true
+	}
+
+	// 顺便清理过期的指纹，防止 map 无限增长
+	for r, first := range sd.seen {
+		if now.Sub(first) >= sd.window {
+			delete(sd.seen, r)
+		}
+	}
+
+	sd.seen[ref] = now
+	return false
+}
+
+// stackFingerprint returns the fingerprint of stack in hex. The goroutine header and the arguments of
+// functions are ignored, so the same call path in different goroutines has the same fingerprint.
+func stackFingerprint(stack string) string {
+	hash := fnv.New64a()
+	for i, line := range strings.Split(stack, "\n") {
+		if i == 0 && strings.HasPrefix(line, "goroutine ") {
+			continue
+		}
+
+		// 文件位置去掉 +0x 偏移量，函数去掉参数，参数的值每次都可能不一样
+		if strings.HasPrefix(line, "\t") {
+			if index := strings.LastIndex(line, " +0x"); index >= 0 {
+				line = line[:index]
+			}
+		} else if index := strings.LastIndex(line, "("); index >= 0 {
+			line = line[:index]
+		}
+
+		hash.Write([]byte(line))
+		hash.Write([]byte("\n"))
+	}
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// SetStackDedupWindow sets the window in which the same stack is attached only once, which slashes the
+// volume of logs during an error storm. The stack is the field whose key is StackKey, like the one logged
+// by Recover. The first log of a stack in the window carries the full stack, and every log carrying the
+// stack will have a field whose key is StackRefKey and value is the fingerprint of stack, so the repeats
+// without stacks can reference the first one. Child loggers created after setting share the window.
+// Set d to <= 0 to disable it, which is the default.
+func (l *Logger) SetStackDedupWindow(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d <= 0 {
+		l.stackDedup = nil
+		return
+	}
+	l.stackDedup = newStackDedup(d)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/30 20:24:48
+
+package logit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试同一个堆栈在窗口内只输出一次，重复的只输出引用
+func TestLoggerSetStackDedupWindow(t *testing.T) {
+	handler := &mapHandler{}
+	logger := NewLogger(DebugLevel, handler)
+	window := 100 * time.Millisecond
+	logger.SetStackDedupWindow(window)
+
+	// 在不同的 goroutine 中从同一个地方 panic，堆栈的头部和参数不一样，但是指纹应该一样
+	panicInGoroutine := func(id int) {
+		group := sync.WaitGroup{}
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			defer RecoverWith(logger, map[string]interface{}{"id": id})
+			panic("storm")
+		}()
+		group.Wait()
+	}
+
+	panicInGoroutine(1)
+	panicInGoroutine(2)
+	time.Sleep(window)
+	panicInGoroutine(3)
+
+	if len(handler.logs) != 3 {
+		t.Fatalf("日志条数不正确！%d", len(handler.logs))
+	}
+
+	first, second, third := handler.logs[0], handler.logs[1], handler.logs[2]
+	if _, ok := first[StackKey]; !ok || first[StackRefKey] == nil {
+		t.Fatalf("第一次出现的堆栈应该完整输出！%v", first)
+	}
+
+	if _, ok := second[StackKey]; ok || second[StackRefKey] != first[StackRefKey] || second["id"] != 2 {
+		t.Fatalf("重复的堆栈应该只输出引用！%v", second)
+	}
+
+	if _, ok := third[StackKey]; !ok || third[StackRefKey] != first[StackRefKey] {
+		t.Fatalf("窗口过期之后堆栈应该重新完整输出！%v", third)
+	}
+
+	// 关闭之后每次都完整输出
+	logger.SetStackDedupWindow(0)
+	panicInGoroutine(4)
+	if _, ok := handler.logs[3][StackKey]; !ok || handler.logs[3][StackRefKey] != nil {
+		t.Fatalf("关闭之后堆栈应该完整输出！%v", handler.logs[3])
+	}
+}
+
+// 测试不同的调用路径有不同的指纹
+func TestStackFingerprint(t *testing.T) {
+	a := "goroutine 1 [running]:\nmain.f(0xc000012345)\n\t/app/main.go:12 +0x25\n"
+	b := "goroutine 7 [running]:\nmain.f(0xc000067890)\n\t/app/main.go:12 +0x25\n"
+	c := "goroutine 1 [running]:\nmain.f(0xc000012345)\n\t/app/main.go:13 +0x25\n"
+
+	if stackFingerprint(a) != stackFingerprint(b) {
+		t.Fatal("同一个调用路径的指纹应该一样！")
+	}
+
+	if stackFingerprint(a) == stackFingerprint(c) {
+		t.Fatal("不同调用路径的指纹应该不一样！")
+	}
+}
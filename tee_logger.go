@@ -0,0 +1,83 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 19:26:14
+
+package logit
+
+import (
+	"os"
+	"time"
+)
+
+// TeeOptions is the options of the file handler of tee logger. See NewTeeLoggerWith.
+type TeeOptions struct {
+
+	// Directory is the directory storing all log files, and "./" will be used if it's empty.
+	Directory string
+
+	// Duration decides log files roll by duration if it's larger than 0, or they roll by size.
+	Duration time.Duration
+
+	// Size is the limited size of log file when rolling by size, and 64 MB will be used if it's <= 0.
+	Size int64
+
+	// Encoder is the encoder of the file handler, and TextEncoder will be used if it's nil.
+	// Notice that the console handler always uses TextEncoder.
+	Encoder Encoder
+
+	// TimeFormat is the time format of both handlers, and DefaultTimeFormat will be used if it's empty.
+	TimeFormat string
+}
+
+// NewTeeLogger returns a logger writing logs to console and to size rolling files in directory,
+// which is the most common setup. Each file is 64 MB at most. See NewTeeLoggerWith if you want
+// to customize the file strategy.
+func NewTeeLogger(directory string, level Level) *Logger {
+	return NewTeeLoggerWith(TeeOptions{Directory: directory}, level)
+}
+
+// NewTeeLoggerWith is the same as NewTeeLogger except the file handler is customized by options.
+// For example, a logger writing Json logs to files rolled every day:
+//
+//     logger := logit.NewTeeLoggerWith(logit.TeeOptions{
+//         Directory: "./logs",
+//         Duration:  24 * time.Hour,
+//         Encoder:   logit.JsonEncoder(),
+//     }, logit.InfoLevel)
+//
+func NewTeeLoggerWith(options TeeOptions, level Level) *Logger {
+	directory := options.Directory
+	if directory == "" {
+		directory = "./"
+	}
+
+	fileHandler, err := newRollingHandler(directory, RollStrategy{
+		Duration:   options.Duration,
+		Size:       options.Size,
+		Encoder:    options.Encoder,
+		TimeFormat: options.TimeFormat,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	timeFormat := options.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+	return NewLogger(level, NewStandardHandler(os.Stdout, TextEncoder(), timeFormat), fileHandler)
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/29 19:48:37
+
+package logit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 捕获 os.Stdout 的输出
+func captureStdout(t *testing.T, fn func()) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	fn()
+	writer.Close()
+
+	output, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+// 读取目录下所有文件的内容
+func readAllFilesIn(t *testing.T, dir string) string {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := strings.Builder{}
+	for _, fileInfo := range fileInfos {
+		content, err := ioutil.ReadFile(filepath.Join(dir, fileInfo.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder.Write(content)
+	}
+	return builder.String()
+}
+
+// 测试同时输出到控制台和文件的 logger
+func TestNewTeeLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewTeeLogger_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := captureStdout(t, func() {
+		logger := NewTeeLogger(dir, InfoLevel)
+		logger.Debug("debug")
+		logger.Info("tee info")
+		logger.Close()
+	})
+
+	if !strings.Contains(output, "tee info") || strings.Contains(output, "debug") {
+		t.Fatalf("控制台输出的日志不正确！%s", output)
+	}
+
+	content := readAllFilesIn(t, dir)
+	if !strings.Contains(content, "tee info") || strings.Contains(content, "debug") {
+		t.Fatalf("文件中的日志不正确！%s", content)
+	}
+}
+
+// 测试定制文件处理器的 tee logger
+func TestNewTeeLoggerWith(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestNewTeeLoggerWith_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := captureStdout(t, func() {
+		logger := NewTeeLoggerWith(TeeOptions{Directory: dir, Duration: time.Hour, Encoder: JsonEncoder()}, DebugLevel)
+		logger.Info("tee json")
+		logger.Close()
+	})
+
+	if !strings.HasPrefix(output, "[info]") || !strings.Contains(output, "tee json") {
+		t.Fatalf("控制台输出的日志不正确！%s", output)
+	}
+
+	content := readAllFilesIn(t, dir)
+	if !strings.Contains(content, `"msg":"tee json"`) {
+		t.Fatalf("文件中的日志不正确！%s", content)
+	}
+}
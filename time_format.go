@@ -0,0 +1,76 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/23 15:22:40
+
+package logit
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+const (
+	// UnixTimeFormat is the time format keeping time in unix form of seconds.
+	// It's not a real layout, and encoders will check it before formatting time.
+	UnixTimeFormat = ""
+
+	// UnixMilliTimeFormat is the time format keeping time in unix form of milliseconds.
+	// It's not a real layout, and encoders will check it before formatting time.
+	UnixMilliTimeFormat = "unixmilli"
+)
+
+var (
+	// timeFormats stores all time formats selectable by name. See TimeFormat.
+	timeFormats = map[string]string{
+		"rfc3339":     time.RFC3339,
+		"rfc3339nano": time.RFC3339Nano,
+		"datetime":    DefaultTimeFormat,
+		"kitchen":     time.Kitchen,
+		"unix":        UnixTimeFormat,
+		"unixmilli":   UnixMilliTimeFormat,
+	}
+)
+
+// TimeFormat returns the time format of name, which can be one of "rfc3339", "rfc3339nano",
+// "datetime", "kitchen", "unix" and "unixmilli". If name isn't one of them, it will be returned
+// as a literal layout, so TimeFormat("2006/01/02") is "2006/01/02". The time format returned can
+// be passed to handlers directly, and the config file uses it to resolve "timeFormat" params.
+func TimeFormat(name string) string {
+	if timeFormat, ok := timeFormats[name]; ok {
+		return timeFormat
+	}
+	return name
+}
+
+// writeTime writes the time of log to buffer in timeFormat.
+// The numeric forms won't be quoted, and the formatted one will be quoted if quote is true.
+// The formatted one is cached in log, so handlers using the same timeFormat won't format it again.
+func writeTime(buffer *bytes.Buffer, log *Log, timeFormat string, quote bool) {
+	switch timeFormat {
+	case UnixTimeFormat:
+		buffer.WriteString(strconv.FormatInt(log.now.Unix(), 10))
+	case UnixMilliTimeFormat:
+		buffer.WriteString(strconv.FormatInt(log.now.UnixNano()/int64(time.Millisecond), 10))
+	default:
+		if quote {
+			buffer.WriteString(strconv.Quote(log.formatTime(timeFormat)))
+		} else {
+			buffer.WriteString(log.formatTime(timeFormat))
+		}
+	}
+}
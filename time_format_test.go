@@ -0,0 +1,93 @@
+// Copyright 2020 Ye Zi Jie. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: FishGoddess
+// Email: fishgoddess@qq.com
+// Created at 2020/08/23 16:05:12
+
+package logit
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// 测试预设的时间格式
+func TestTimeFormat(t *testing.T) {
+	now := time.Date(2020, 8, 23, 16, 5, 12, 345678900, time.UTC)
+	log := &Log{level: InfoLevel, now: now, msg: "msg"}
+
+	cases := map[string]string{
+		"rfc3339":     "[info] [2020-08-23T16:05:12Z] msg\n",
+		"rfc3339nano": "[info] [2020-08-23T16:05:12.3456789Z] msg\n",
+		"datetime":    "[info] [2020-08-23 16:05:12] msg\n",
+		"kitchen":     "[info] [4:05PM] msg\n",
+		"unix":        "[info] [1598198712] msg\n",
+		"unixmilli":   "[info] [1598198712345] msg\n",
+		"2006/01/02":  "[info] [2020/08/23] msg\n",
+	}
+
+	for name, expect := range cases {
+		if got := string(TextEncoder().Encode(log, TimeFormat(name))); got != expect {
+			t.Fatalf("时间格式 %s 的输出不正确！%q", name, got)
+		}
+	}
+
+	jsonCases := map[string]string{
+		"rfc3339":   `{"level":"info","time":"2020-08-23T16:05:12Z","msg":"msg"}` + "\n",
+		"unixmilli": `{"level":"info","time":1598198712345,"msg":"msg"}` + "\n",
+	}
+
+	for name, expect := range jsonCases {
+		if got := string(JsonEncoder().Encode(log, TimeFormat(name))); got != expect {
+			t.Fatalf("Json 编码器中时间格式 %s 的输出不正确！%q", name, got)
+		}
+	}
+}
+
+// 测试格式化时间的缓存
+func TestLogFormatTime(t *testing.T) {
+	now := time.Date(2020, 8, 29, 20, 15, 30, 0, time.UTC)
+	log := &Log{level: InfoLevel, now: now, msg: "msg"}
+
+	if log.formatTime(DefaultTimeFormat) != "2020-08-29 20:15:30" {
+		t.Fatalf("格式化的时间不正确！%s", log.formatTime(DefaultTimeFormat))
+	}
+
+	// 修改时间之后，同样的格式应该使用缓存的结果，说明没有重新格式化
+	log.now = now.Add(time.Hour)
+	if log.formatTime(DefaultTimeFormat) != "2020-08-29 20:15:30" {
+		t.Fatalf("同样的格式没有使用缓存！%s", log.formatTime(DefaultTimeFormat))
+	}
+
+	// 不同的格式需要重新格式化
+	if log.formatTime(time.Kitchen) != "9:15PM" {
+		t.Fatalf("不同的格式没有重新格式化！%s", log.formatTime(time.Kitchen))
+	}
+}
+
+// 测试两个日志处理器使用同样时间格式的性能，时间只会被格式化一次
+func BenchmarkTwoHandlersSameTimeFormat(b *testing.B) {
+	logger := NewLogger(DebugLevel,
+		NewStandardHandler(ioutil.Discard, TextEncoder(), DefaultTimeFormat),
+		NewStandardHandler(ioutil.Discard, JsonEncoder(), DefaultTimeFormat),
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("benchmark")
+	}
+}